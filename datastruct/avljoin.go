@@ -0,0 +1,140 @@
+package datastruct
+
+import "fmt"
+
+// join 以 node 为根合并 left 与 right，要求 left 中的 key < node.key < right 中的 key，返回新的根节点。
+// 沿较高子树的侧边下降到与较矮子树高度相差不超过 1 的位置挂上 node，再逐层向上调整，
+// 时间复杂度 O(|h(left) - h(right)| + 1)
+func (tree *AvlTree[K, V]) join(left, node, right *TreeNode[K, V]) *TreeNode[K, V] {
+	switch lh, rh := tree.nodeHeight(left), tree.nodeHeight(right); {
+	case lh > rh+1:
+		left.right = tree.join(left.right, node, right)
+		return tree.balance(left)
+	case rh > lh+1:
+		right.left = tree.join(left, node, right.left)
+		return tree.balance(right)
+	}
+	node.left, node.right = left, right
+	tree.update(node)
+	return node
+}
+
+// join2 合并 left 与 right，要求 left 中的 key < right 中的 key，返回新的根节点
+func (tree *AvlTree[K, V]) join2(left, right *TreeNode[K, V]) *TreeNode[K, V] {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	right, least := tree.deleteMin(right)
+	return tree.join(left, least, right)
+}
+
+// split 按 key 拆分子树，返回小于 key 的子树、key 所在的节点（不存在时为 nil）与大于 key 的子树
+func (tree *AvlTree[K, V]) split(node *TreeNode[K, V], key K) (left, found, right *TreeNode[K, V]) {
+	if node == nil {
+		return nil, nil, nil
+	}
+	switch c := tree.cmp(key, node.key); {
+	case c < 0:
+		left, found, right = tree.split(node.left, key)
+		return left, found, tree.join(right, node, node.right)
+	case c > 0:
+		left, found, right = tree.split(node.right, key)
+		return tree.join(node.left, node, left), found, right
+	}
+	return node.left, node, node.right
+}
+
+// union 合并两棵子树，key 相同时使用 other 中的 value，返回新的根节点
+func (tree *AvlTree[K, V]) union(node, other *TreeNode[K, V]) *TreeNode[K, V] {
+	if node == nil {
+		return other
+	}
+	if other == nil {
+		return node
+	}
+	left, found, right := tree.split(other, node.key)
+	if found != nil {
+		node.value = found.value
+	}
+	return tree.join(tree.union(node.left, left), node, tree.union(node.right, right))
+}
+
+// intersection 保留 node 中同时存在于 other 的节点，返回新的根节点。other 不会被修改
+func (tree *AvlTree[K, V]) intersection(node, other *TreeNode[K, V]) *TreeNode[K, V] {
+	if node == nil || other == nil {
+		return nil
+	}
+	left, found, right := tree.split(node, other.key)
+	l, r := tree.intersection(left, other.left), tree.intersection(right, other.right)
+	if found != nil {
+		return tree.join(l, found, r)
+	}
+	return tree.join2(l, r)
+}
+
+// difference 删除 node 中存在于 other 的节点，返回新的根节点。other 不会被修改
+func (tree *AvlTree[K, V]) difference(node, other *TreeNode[K, V]) *TreeNode[K, V] {
+	if node == nil || other == nil {
+		return node
+	}
+	left, _, right := tree.split(node, other.key)
+	return tree.join2(tree.difference(left, other.left), tree.difference(right, other.right))
+}
+
+// Join 将 key、value 以及 right 中的所有节点合并到 tree 中，
+// 要求 tree 中所有 key 小于 key，right 中所有 key 大于 key，否则 panic。
+// right 的节点被移入 tree，调用后 right 为空。时间复杂度 O(log n)
+func (tree *AvlTree[K, V]) Join(key K, value V, right *AvlTree[K, V]) {
+	if k, _, ok := tree.Max(); ok && tree.cmp(k, key) >= 0 {
+		panic(fmt.Sprintf("AvlTree.Join: key %v is not greater than %v", key, k))
+	}
+	if k, _, ok := right.Min(); ok && tree.cmp(key, k) >= 0 {
+		panic(fmt.Sprintf("AvlTree.Join: key %v is not less than %v", key, k))
+	}
+	tree.treeRoot = tree.join(tree.treeRoot, &TreeNode[K, V]{size: 1, key: key, value: value}, right.treeRoot)
+	right.treeRoot = nil
+}
+
+// Split 按 key 将 tree 拆分为 key 小于 key 与大于 key 的两棵树，key 存在时同时返回它的 value。
+// tree 的节点被移入返回的两棵树，调用后 tree 为空。时间复杂度 O(log n)
+func (tree *AvlTree[K, V]) Split(key K) (left, right *AvlTree[K, V], value V, found bool) {
+	l, node, r := tree.split(tree.treeRoot, key)
+	tree.treeRoot = nil
+	left = &AvlTree[K, V]{treeRoot: l, cmp: tree.cmp}
+	right = &AvlTree[K, V]{treeRoot: r, cmp: tree.cmp}
+	if node != nil {
+		value, found = node.value, true
+	}
+	return
+}
+
+// Union 将 other 合并到 tree 中，key 相同时使用 other 中的 value。
+// other 的节点被移入 tree，调用后 other 为空。两棵树必须使用相同的 key 顺序，
+// 大小分别为 m <= n 时时间复杂度 O(m log(n/m + 1))
+func (tree *AvlTree[K, V]) Union(other *AvlTree[K, V]) {
+	if other == tree {
+		return
+	}
+	tree.treeRoot = tree.union(tree.treeRoot, other.treeRoot)
+	other.treeRoot = nil
+}
+
+// Intersection 只保留 tree 中同时存在于 other 的 key，value 不变，other 不会被修改。时间复杂度同 Union
+func (tree *AvlTree[K, V]) Intersection(other *AvlTree[K, V]) {
+	if other == tree {
+		return
+	}
+	tree.treeRoot = tree.intersection(tree.treeRoot, other.treeRoot)
+}
+
+// Difference 删除 tree 中存在于 other 的 key，other 不会被修改。时间复杂度同 Union
+func (tree *AvlTree[K, V]) Difference(other *AvlTree[K, V]) {
+	if other == tree {
+		tree.treeRoot = nil
+		return
+	}
+	tree.treeRoot = tree.difference(tree.treeRoot, other.treeRoot)
+}
@@ -0,0 +1,144 @@
+package datastruct
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// randomAvlTree 随机生成 n 个 [0, limit) 中的 key，value 为 key*10+tag
+func randomAvlTree(r *rand.Rand, n, limit, tag int) (*AvlTree[int, int], map[int]int) {
+	tree := NewAvlTree[int, int](OrderedComparator[int]())
+	m := make(map[int]int)
+	for i := 0; i < n; i++ {
+		k := r.Intn(limit)
+		tree.Put(k, k*10+tag)
+		m[k] = k*10 + tag
+	}
+	return tree, m
+}
+
+// checkAvlContent 校验树的不变式以及内容与 m 一致
+func checkAvlContent(t *testing.T, tree *AvlTree[int, int], m map[int]int) {
+	t.Helper()
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != len(m) {
+		t.Fatalf("Len=%d, 期望 %d", tree.Len(), len(m))
+	}
+	tree.Ascend(func(k, v int) bool {
+		if mv, ok := m[k]; !ok || mv != v {
+			t.Fatalf("key %d 的 value 为 %d, 期望 %d,%v", k, v, mv, ok)
+		}
+		return true
+	})
+}
+
+func TestAvlTreeJoinSplit(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 10, 100, 1000} {
+		for i := 0; i < 20; i++ {
+			tree, m := randomAvlTree(r, n, 2*n+1, 0)
+			key := r.Intn(2*n + 1)
+			left, right, value, found := tree.Split(key)
+			if mv, ok := m[key]; ok != found || mv != value {
+				t.Fatalf("Split(%d) 返回 %d,%v, 期望 %d,%v", key, value, found, mv, ok)
+			}
+			if tree.Len() != 0 {
+				t.Fatalf("Split 后原树不为空")
+			}
+			lm, rm := make(map[int]int), make(map[int]int)
+			for k, v := range m {
+				if k < key {
+					lm[k] = v
+				} else if k > key {
+					rm[k] = v
+				}
+			}
+			checkAvlContent(t, left, lm)
+			checkAvlContent(t, right, rm)
+
+			left.Join(key, -1, right)
+			m[key] = -1
+			checkAvlContent(t, left, m)
+			if right.Len() != 0 {
+				t.Fatalf("Join 后 right 不为空")
+			}
+		}
+	}
+
+	// 高度相差很大的两棵树
+	small, sm := randomAvlTree(r, 3, 10, 0)
+	large := NewAvlTree[int, int](OrderedComparator[int]())
+	for k := 100; k < 5000; k++ {
+		large.Put(k, k)
+		sm[k] = k
+	}
+	small.Join(50, 50, large)
+	sm[50] = 50
+	checkAvlContent(t, small, sm)
+}
+
+func TestAvlTreeJoinPanic(t *testing.T) {
+	for _, key := range []int{5, 10} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Join(%d) 未 panic", key)
+				}
+			}()
+			left, right := NewAvlTree[int, int](OrderedComparator[int]()), NewAvlTree[int, int](OrderedComparator[int]())
+			left.Put(5, 5)
+			right.Put(10, 10)
+			left.Join(key, 0, right)
+		}()
+	}
+}
+
+func TestAvlTreeSetOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	sizes := [][2]int{{0, 0}, {0, 50}, {50, 0}, {1, 1000}, {1000, 1}, {100, 100}, {20, 2000}, {2000, 20}}
+	for _, sz := range sizes {
+		limit := sz[0] + sz[1] + 1
+		a, am := randomAvlTree(r, sz[0], limit, 1)
+		b, bm := randomAvlTree(r, sz[1], limit, 2)
+		union, inter, diff := make(map[int]int), make(map[int]int), make(map[int]int)
+		for k, v := range am {
+			union[k] = v
+			if _, ok := bm[k]; ok {
+				inter[k] = v
+			} else {
+				diff[k] = v
+			}
+		}
+		for k, v := range bm {
+			union[k] = v
+		}
+
+		u := NewAvlTree[int, int](OrderedComparator[int]())
+		u.Union(a.clone())
+		u.Union(b.clone())
+		checkAvlContent(t, u, union)
+
+		// Intersection 与 Difference 不修改参数树
+		i := a.clone()
+		i.Intersection(b)
+		checkAvlContent(t, i, inter)
+		checkAvlContent(t, b, bm)
+
+		d := a.clone()
+		d.Difference(b)
+		checkAvlContent(t, d, diff)
+		checkAvlContent(t, b, bm)
+	}
+}
+
+// clone 复制一棵树，用于测试会清空参数的集合操作
+func (tree *AvlTree[K, V]) clone() *AvlTree[K, V] {
+	c := NewAvlTree[K, V](tree.cmp)
+	tree.Ascend(func(key K, value V) bool {
+		c.Put(key, value)
+		return true
+	})
+	return c
+}
@@ -6,195 +6,320 @@ import (
 
 /***************AVL Tree****************/
 
-type ElemType int
-type TreeNode struct {
+type TreeNode[K, V any] struct {
 	height int
-	data   ElemType
-	left   *TreeNode
-	right  *TreeNode
+	size   int // 以该节点为根的子树的节点数量
+	key    K
+	value  V
+	left   *TreeNode[K, V]
+	right  *TreeNode[K, V]
 }
-type AvlTree struct {
-	treeRoot *TreeNode
+
+type AvlTree[K, V any] struct {
+	treeRoot *TreeNode[K, V]
+	cmp      Comparator[K] // key 比较函数
+}
+
+// NewAvlTree 新建 AVL 树
+//
+//	@cmp key 的比较函数
+func NewAvlTree[K, V any](cmp Comparator[K]) *AvlTree[K, V] {
+	if cmp == nil {
+		panic("AvlTree comparator is nil")
+	}
+	return &AvlTree[K, V]{cmp: cmp}
 }
 
-func (tree *AvlTree) nodeHeight(node *TreeNode) int {
+func (tree *AvlTree[K, V]) nodeHeight(node *TreeNode[K, V]) int {
 	if node == nil {
 		return -1
 	}
 	return node.height
 }
 
-func (tree *AvlTree) nodeBf(node *TreeNode) int {
+func (tree *AvlTree[K, V]) nodeBf(node *TreeNode[K, V]) int {
 	return (tree.nodeHeight(node.left) - tree.nodeHeight(node.right))
 }
 
-func (tree *AvlTree) rightRotate(node *TreeNode) (l *TreeNode) {
-	l = node.left
-	node.left = l.right
-	l.right = node
+func (tree *AvlTree[K, V]) nodeSize(node *TreeNode[K, V]) int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
 
-	/*if tree.nodeHeight(node.left) < tree.nodeHeight(node.right) {
-		node.height = tree.nodeHeight(node.right) + 1
-	} else {
-		node.height = tree.nodeHeight(node.left) + 1
-	}*/
+// update 根据左右子树重新计算节点高度与子树大小
+func (tree *AvlTree[K, V]) update(node *TreeNode[K, V]) {
 	node.height = max(tree.nodeHeight(node.left), tree.nodeHeight(node.right)) + 1
+	node.size = tree.nodeSize(node.left) + tree.nodeSize(node.right) + 1
+}
 
-	/*if tree.nodeHeight(l.left) < tree.nodeHeight(l.right) {
-		l.height = tree.nodeHeight(l.right) + 1
-	} else {
-		l.height = tree.nodeHeight(l.left) + 1
-	}*/
-	l.height = max(tree.nodeHeight(node.left), tree.nodeHeight(node.right)) + 1
+func (tree *AvlTree[K, V]) rightRotate(node *TreeNode[K, V]) (l *TreeNode[K, V]) {
+	l = node.left
+	node.left = l.right
+	l.right = node
 
+	tree.update(node)
+	tree.update(l)
 	return l
 }
 
-func (tree *AvlTree) leftRotate(node *TreeNode) (r *TreeNode) {
+func (tree *AvlTree[K, V]) leftRotate(node *TreeNode[K, V]) (r *TreeNode[K, V]) {
 	r = node.right
 	node.right = r.left
 	r.left = node
 
-	if tree.nodeHeight(node.left) < tree.nodeHeight(node.right) {
-		node.height = tree.nodeHeight(node.right) + 1
-	} else {
-		node.height = tree.nodeHeight(node.left) + 1
-	}
+	tree.update(node)
+	tree.update(r)
+	return r
+}
 
-	if tree.nodeHeight(r.left) < tree.nodeHeight(r.right) {
-		r.height = tree.nodeHeight(r.right) + 1
-	} else {
-		r.height = tree.nodeHeight(r.left) + 1
+// balance 插入或删除后更新节点高度，失衡时旋转，返回子树新的根节点
+func (tree *AvlTree[K, V]) balance(node *TreeNode[K, V]) *TreeNode[K, V] {
+	tree.update(node)
+	switch bf := tree.nodeBf(node); {
+	case bf == 2:
+		if tree.nodeBf(node.left) < 0 { // 左右
+			node.left = tree.leftRotate(node.left)
+		}
+		return tree.rightRotate(node)
+	case bf == -2:
+		if tree.nodeBf(node.right) > 0 { // 右左
+			node.right = tree.rightRotate(node.right)
+		}
+		return tree.leftRotate(node)
 	}
+	return node
+}
 
-	return r
+// Len 节点数量
+func (tree *AvlTree[K, V]) Len() int {
+	return tree.nodeSize(tree.treeRoot)
 }
 
-// InsertTreeNode 插入节点
-func (tree *AvlTree) InsertTreeNode(node *TreeNode, e ElemType) *TreeNode {
-	if node == nil {
-		newTreeNode := new(TreeNode)
-		newTreeNode.right = nil
-		newTreeNode.left = nil
-		newTreeNode.height = 0
-		newTreeNode.data = e
-		return newTreeNode
-	} else {
-		if e < node.data {
-			node.left = tree.InsertTreeNode(node.left, e)
-			if tree.nodeBf(node) == 2 { // 插入完后，可能需要调整树结构
-				if tree.nodeBf(node.left) < 0 {
-					node.left = tree.leftRotate(node.left)
-					node = tree.rightRotate(node.right)
-				} else {
-					node = tree.rightRotate(node)
-				}
-			}
-		} else if e > node.data {
-			node.right = tree.InsertTreeNode(node.right, e)
-			if tree.nodeBf(node) == -2 {
-				if tree.nodeBf(node.right) > 0 {
-					node.right = tree.rightRotate(node.right)
-					node = tree.leftRotate(node)
-				} else {
-					node = tree.leftRotate(node)
-				}
-			}
+// Get 查找 key 对应的 value
+func (tree *AvlTree[K, V]) Get(key K) (V, bool) {
+	for node := tree.treeRoot; node != nil; {
+		c := tree.cmp(key, node.key)
+		if c == 0 {
+			return node.value, true
+		}
+		if c < 0 {
+			node = node.left
 		} else {
-			fmt.Println("InsertTreeNode failed")
-			return node
+			node = node.right
 		}
 	}
-	if node != nil { // 调整完树结构后，节点的高度可能发生改变
-		if (node.right != nil && node.left == nil) ||
-			(node.right.height > node.left.height) {
-			node.height = node.right.height + 1
-		} else if (node.right == nil && node.left != nil) ||
-			(node.left.height > node.right.height) {
-			node.height = node.left.height + 1
+	var zero V
+	return zero, false
+}
+
+// Put 插入或更新 key，key 已存在时返回旧的 value 与 replaced=true
+func (tree *AvlTree[K, V]) Put(key K, value V) (old V, replaced bool) {
+	tree.treeRoot, old, replaced = tree.insertTreeNode(tree.treeRoot, key, value)
+	return
+}
+
+// insertTreeNode 在子树中插入节点，返回子树新的根节点
+func (tree *AvlTree[K, V]) insertTreeNode(node *TreeNode[K, V], key K, value V) (*TreeNode[K, V], V, bool) {
+	if node == nil {
+		var zero V
+		return &TreeNode[K, V]{size: 1, key: key, value: value}, zero, false
+	}
+	var (
+		old      V
+		replaced bool
+	)
+	switch c := tree.cmp(key, node.key); {
+	case c < 0:
+		node.left, old, replaced = tree.insertTreeNode(node.left, key, value)
+	case c > 0:
+		node.right, old, replaced = tree.insertTreeNode(node.right, key, value)
+	default:
+		old, node.value = node.value, value
+		return node, old, true
+	}
+	// 插入完后，可能需要调整树结构
+	return tree.balance(node), old, replaced
+}
+
+// Delete 删除 key，返回被删除的 value
+func (tree *AvlTree[K, V]) Delete(key K) (V, bool) {
+	var deleted *TreeNode[K, V]
+	tree.treeRoot, deleted = tree.deleteTreeNode(tree.treeRoot, key)
+	if deleted == nil {
+		var zero V
+		return zero, false
+	}
+	return deleted.value, true
+}
+
+// deleteTreeNode 在子树中删除节点，返回子树新的根节点与被删除的节点
+func (tree *AvlTree[K, V]) deleteTreeNode(node *TreeNode[K, V], key K) (*TreeNode[K, V], *TreeNode[K, V]) {
+	if node == nil {
+		return nil, nil
+	}
+	var deleted *TreeNode[K, V]
+	switch c := tree.cmp(key, node.key); {
+	case c < 0:
+		node.left, deleted = tree.deleteTreeNode(node.left, key)
+	case c > 0:
+		node.right, deleted = tree.deleteTreeNode(node.right, key)
+	default:
+		deleted = node
+		if node.left == nil {
+			return node.right, deleted
+		} else if node.right == nil {
+			return node.left, deleted
 		}
+		// 用后继节点(右子树最小的节点)替换
+		right, succ := tree.deleteMin(node.right)
+		succ.left, succ.right = node.left, right
+		node = succ
 	}
-	return node
+	if deleted == nil {
+		return node, nil
+	}
+	// 删除完后，可能需要调整树结构
+	return tree.balance(node), deleted
+}
+
+// deleteMin 删除子树中最小的节点，返回子树新的根节点与被删除的节点
+func (tree *AvlTree[K, V]) deleteMin(node *TreeNode[K, V]) (*TreeNode[K, V], *TreeNode[K, V]) {
+	if node.left == nil {
+		return node.right, node
+	}
+	var least *TreeNode[K, V]
+	node.left, least = tree.deleteMin(node.left)
+	return tree.balance(node), least
+}
+
+// Min 最小的 key 及其 value，空树返回 ok=false
+func (tree *AvlTree[K, V]) Min() (key K, value V, ok bool) {
+	node := tree.treeRoot
+	if node == nil {
+		return
+	}
+	for node.left != nil {
+		node = node.left
+	}
+	return node.key, node.value, true
 }
 
-// DeleteTreeNode 删除节点
-func (tree *AvlTree) DeleteTreeNode(node *TreeNode, e ElemType) *TreeNode {
+// Max 最大的 key 及其 value，空树返回 ok=false
+func (tree *AvlTree[K, V]) Max() (key K, value V, ok bool) {
+	node := tree.treeRoot
 	if node == nil {
-		return nil
-	}
-	if e < node.data {
-		node.left = tree.DeleteTreeNode(node.left, e)
-		if tree.nodeBf(node) == -2 { // 删除完后，可能需要调整树结构
-			if tree.nodeBf(node.right) > 0 {
-				node.right = tree.rightRotate(node.right)
-				node = tree.leftRotate(node)
-			} else {
-				node = tree.leftRotate(node)
+		return
+	}
+	for node.right != nil {
+		node = node.right
+	}
+	return node.key, node.value, true
+}
+
+// Floor 小于等于 key 的最大 key 及其 value，不存在时返回 ok=false
+func (tree *AvlTree[K, V]) Floor(key K) (k K, v V, ok bool) {
+	for node := tree.treeRoot; node != nil; {
+		c := tree.cmp(key, node.key)
+		if c >= 0 {
+			k, v, ok = node.key, node.value, true
+			if c == 0 {
+				break
 			}
+			node = node.right
+		} else {
+			node = node.left
 		}
-	} else if e > node.data {
-		node.right = tree.DeleteTreeNode(node.right, e)
-		if tree.nodeBf(node) == 2 {
-			if tree.nodeBf(node.left) < 0 {
-				node.left = tree.leftRotate(node.left)
-				node = tree.rightRotate(node.right)
-			} else {
-				node = tree.rightRotate(node)
+	}
+	return
+}
+
+// Ceiling 大于等于 key 的最小 key 及其 value，不存在时返回 ok=false
+func (tree *AvlTree[K, V]) Ceiling(key K) (k K, v V, ok bool) {
+	for node := tree.treeRoot; node != nil; {
+		c := tree.cmp(key, node.key)
+		if c <= 0 {
+			k, v, ok = node.key, node.value, true
+			if c == 0 {
+				break
 			}
-		}
-	} else {
-		if node.left == nil && node.right == nil {
-			return nil
-		} else if node.left == nil {
-			return node.right
+			node = node.left
 		} else {
-			l := node.left
-			lr := l.right
-			for lr != nil && lr.right != nil {
-				l = lr
-				lr = lr.right
-			}
-			if lr == nil {
-				node.data = l.data
-				node.left = l.left
-			} else {
-				node.data = lr.data
-				l.right = nil
-			}
+			node = node.right
 		}
 	}
-	if node != nil { // 调整完树结构后，节点的高度可能发生改变
-		if (node.right != nil && node.left == nil) ||
-			(node.right.height > node.left.height) {
-			node.height = node.right.height + 1
-		} else if (node.right == nil && node.left != nil) ||
-			(node.left.height > node.right.height) {
-			node.height = node.left.height + 1
-		}
+	return
+}
+
+// Ascend 按 key 升序遍历，fn 返回 false 时停止
+func (tree *AvlTree[K, V]) Ascend(fn func(key K, value V) bool) {
+	tree.ascend(tree.treeRoot, nil, nil, fn)
+}
+
+// Range 按 key 升序遍历 [lo, hi) 区间，fn 返回 false 时停止
+func (tree *AvlTree[K, V]) Range(lo, hi K, fn func(key K, value V) bool) {
+	tree.ascend(tree.treeRoot, &lo, &hi, fn)
+}
+
+// ascend 中序遍历子树中 [lo, hi) 区间的节点（nil 表示无边界），返回是否继续
+func (tree *AvlTree[K, V]) ascend(node *TreeNode[K, V], lo, hi *K, fn func(key K, value V) bool) bool {
+	if node == nil {
+		return true
 	}
-	return node
+	aboveLo := lo == nil || tree.cmp(node.key, *lo) >= 0
+	belowHi := hi == nil || tree.cmp(node.key, *hi) < 0
+	if aboveLo && !tree.ascend(node.left, lo, hi, fn) {
+		return false
+	}
+	if aboveLo && belowHi && !fn(node.key, node.value) {
+		return false
+	}
+	return !belowHi || tree.ascend(node.right, lo, hi, fn)
+}
+
+// Descend 按 key 降序遍历，fn 返回 false 时停止
+func (tree *AvlTree[K, V]) Descend(fn func(key K, value V) bool) {
+	tree.descend(tree.treeRoot, fn)
+}
+
+// descend 逆中序遍历子树，返回是否继续
+func (tree *AvlTree[K, V]) descend(node *TreeNode[K, V], fn func(key K, value V) bool) bool {
+	if node == nil {
+		return true
+	}
+	return tree.descend(node.right, fn) && fn(node.key, node.value) && tree.descend(node.left, fn)
 }
 
 // PreTraversal 先序遍历
-func (tree *AvlTree) PreTraversal(root *TreeNode) {
+func (tree *AvlTree[K, V]) PreTraversal() {
+	tree.preTraversal(tree.treeRoot)
+}
+
+func (tree *AvlTree[K, V]) preTraversal(root *TreeNode[K, V]) {
 	if root != nil {
-		fmt.Print(root.data, " ")
-		tree.PreTraversal(root.left)
-		tree.PreTraversal(root.right)
+		fmt.Print(root.key, " ")
+		tree.preTraversal(root.left)
+		tree.preTraversal(root.right)
 	}
 }
 
 // InTraversal 中序遍历
-func (tree *AvlTree) InTraversal(root *TreeNode) {
+func (tree *AvlTree[K, V]) InTraversal() {
+	tree.inTraversal(tree.treeRoot)
+}
+
+func (tree *AvlTree[K, V]) inTraversal(root *TreeNode[K, V]) {
 	if root != nil {
-		tree.InTraversal(root.left)
-		fmt.Print(root.data, " ")
-		tree.InTraversal(root.right)
+		tree.inTraversal(root.left)
+		fmt.Print(root.key, " ")
+		tree.inTraversal(root.right)
 	}
 }
 
 // SqcTraversal 层序遍历
-func (tree *AvlTree) SqcTraversal(root *TreeNode) {
+func (tree *AvlTree[K, V]) SqcTraversal() {
+	root := tree.treeRoot
 	if root == nil {
 		return
 	}
@@ -203,8 +328,8 @@ func (tree *AvlTree) SqcTraversal(root *TreeNode) {
 	for queue.Size() != 0 {
 		size := queue.Size()
 		for i := 0; i < size; i++ {
-			val := queue.Pop().(*TreeNode)
-			fmt.Print(val.data, " ")
+			val := queue.Pop().(*TreeNode[K, V])
+			fmt.Print(val.key, " ")
 			if val.left != nil {
 				queue.Push(val.left)
 			}
@@ -215,11 +340,11 @@ func (tree *AvlTree) SqcTraversal(root *TreeNode) {
 	}
 }
 
-func (tree *AvlTree) IsBalanced() bool {
+func (tree *AvlTree[K, V]) IsBalanced() bool {
 	return tree.isBalancedTree(tree.treeRoot)
 }
 
-func (tree *AvlTree) isBalancedTree(root *TreeNode) bool {
+func (tree *AvlTree[K, V]) isBalancedTree(root *TreeNode[K, V]) bool {
 	if root == nil {
 		return true
 	}
@@ -228,3 +353,41 @@ func (tree *AvlTree) isBalancedTree(root *TreeNode) bool {
 	}
 	return tree.isBalancedTree(root.left) && tree.isBalancedTree(root.right)
 }
+
+// Verify 校验 AVL 树的不变式：二叉搜索树有序、结点高度正确、平衡因子在 [-1, 1] 之间、子树的 size 正确，
+// 返回发现的第一处错误（*InvariantError），错误中包含出错结点的路径
+func (tree *AvlTree[K, V]) Verify() error {
+	_, _, err := tree.verify(tree.treeRoot, "root", nil, nil)
+	return err
+}
+
+// verify 校验子树，lo、hi 为子树中 key 的开区间边界（nil 表示无边界），返回子树的节点数量与高度
+func (tree *AvlTree[K, V]) verify(node *TreeNode[K, V], path string, lo, hi *K) (int, int, error) {
+	if node == nil {
+		return 0, -1, nil
+	}
+	if lo != nil && tree.cmp(node.key, *lo) <= 0 {
+		return 0, 0, NewInvariantError(path, "key %v <= lower bound %v", node.key, *lo)
+	}
+	if hi != nil && tree.cmp(node.key, *hi) >= 0 {
+		return 0, 0, NewInvariantError(path, "key %v >= upper bound %v", node.key, *hi)
+	}
+	ln, lh, err := tree.verify(node.left, path+".L", lo, &node.key)
+	if err != nil {
+		return 0, 0, err
+	}
+	rn, rh, err := tree.verify(node.right, path+".R", &node.key, hi)
+	if err != nil {
+		return 0, 0, err
+	}
+	if h := max(lh, rh) + 1; node.height != h {
+		return 0, 0, NewInvariantError(path, "height %d, expected %d", node.height, h)
+	}
+	if bf := lh - rh; bf > 1 || bf < -1 {
+		return 0, 0, NewInvariantError(path, "balance factor %d", bf)
+	}
+	if node.size != ln+rn+1 {
+		return 0, 0, NewInvariantError(path, "size %d, expected %d", node.size, ln+rn+1)
+	}
+	return node.size, node.height, nil
+}
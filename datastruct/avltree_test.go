@@ -1,28 +1,176 @@
 package datastruct
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
+	"slices"
 	"testing"
 )
 
 func TestAvlTree(t *testing.T) {
-	var tree AvlTree
+	tree := NewAvlTree[int, string](OrderedComparator[int]())
 	for i := 1; i <= 10; i++ {
-		tree.treeRoot = tree.InsertTreeNode(tree.treeRoot, ElemType(i))
+		tree.Put(i, fmt.Sprint(i))
 	}
-	tree.PreTraversal(tree.treeRoot)
+	tree.PreTraversal()
 	fmt.Println()
-	tree.InTraversal(tree.treeRoot)
+	tree.InTraversal()
 	fmt.Println()
-	tree.SqcTraversal(tree.treeRoot)
+	tree.SqcTraversal()
 	fmt.Println()
 	fmt.Println("is balanced", tree.IsBalanced())
-	tree.DeleteTreeNode(tree.treeRoot, ElemType(5))
+	tree.Delete(5)
 	fmt.Println("is balanced", tree.IsBalanced())
-	tree.DeleteTreeNode(tree.treeRoot, ElemType(6))
-	tree.DeleteTreeNode(tree.treeRoot, ElemType(7))
-	tree.DeleteTreeNode(tree.treeRoot, ElemType(4))
+	tree.Delete(6)
+	tree.Delete(7)
+	tree.Delete(4)
 	fmt.Println("is balanced", tree.IsBalanced())
 	fmt.Println()
-	tree.SqcTraversal(tree.treeRoot)
+	tree.SqcTraversal()
+	fmt.Println()
+
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != 6 {
+		t.Fatalf("Len=%d, 期望 6", tree.Len())
+	}
+	if v, ok := tree.Get(8); !ok || v != "8" {
+		t.Fatalf("Get(8)=%q,%v", v, ok)
+	}
+	if _, ok := tree.Get(5); ok {
+		t.Fatalf("5 已被删除")
+	}
+	if old, replaced := tree.Put(8, "eight"); !replaced || old != "8" {
+		t.Fatalf("Put(8) 返回 %q,%v", old, replaced)
+	}
+	if _, ok := tree.Delete(5); ok {
+		t.Fatalf("重复删除 5 成功")
+	}
+
+	if k, _, _ := tree.Min(); k != 1 {
+		t.Fatalf("Min=%d", k)
+	}
+	if k, _, _ := tree.Max(); k != 10 {
+		t.Fatalf("Max=%d", k)
+	}
+	if k, _, ok := tree.Floor(6); !ok || k != 3 {
+		t.Fatalf("Floor(6)=%d,%v", k, ok)
+	}
+	if k, _, ok := tree.Ceiling(4); !ok || k != 8 {
+		t.Fatalf("Ceiling(4)=%d,%v", k, ok)
+	}
+	if _, _, ok := tree.Ceiling(11); ok {
+		t.Fatalf("Ceiling(11) 不应存在")
+	}
+	var got []int
+	tree.Range(2, 9, func(k int, _ string) bool {
+		got = append(got, k)
+		return true
+	})
+	if !slices.Equal(got, []int{2, 3, 8}) {
+		t.Fatalf("Range(2,9)=%v", got)
+	}
+	got = got[:0]
+	tree.Descend(func(k int, _ string) bool {
+		got = append(got, k)
+		return len(got) < 3
+	})
+	if !slices.Equal(got, []int{10, 9, 8}) {
+		t.Fatalf("Descend=%v", got)
+	}
+}
+
+func TestAvlTreeRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := NewAvlTree[int, int](OrderedComparator[int]())
+	m := make(map[int]int)
+	for i := 0; i < 5000; i++ {
+		k := r.Intn(500)
+		if r.Intn(3) == 0 {
+			v, ok := tree.Delete(k)
+			mv, mok := m[k]
+			if ok != mok || v != mv {
+				t.Fatalf("Delete(%d)=%d,%v, 期望 %d,%v", k, v, ok, mv, mok)
+			}
+			delete(m, k)
+		} else {
+			old, replaced := tree.Put(k, i)
+			mv, mok := m[k]
+			if replaced != mok || old != mv {
+				t.Fatalf("Put(%d)=%d,%v, 期望 %d,%v", k, old, replaced, mv, mok)
+			}
+			m[k] = i
+		}
+		if i%100 == 0 {
+			if err := tree.Verify(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != len(m) {
+		t.Fatalf("Len=%d, 期望 %d", tree.Len(), len(m))
+	}
+	var keys []int
+	tree.Ascend(func(k, v int) bool {
+		if m[k] != v {
+			t.Fatalf("key %d 的 value 为 %d, 期望 %d", k, v, m[k])
+		}
+		keys = append(keys, k)
+		return true
+	})
+	if !slices.IsSorted(keys) || len(keys) != len(m) {
+		t.Fatalf("Ascend 结果错误: %v", keys)
+	}
+}
+
+func TestAvlTreeVerify(t *testing.T) {
+	//     2
+	//    / \
+	//   1   4
+	//      / \
+	//     3   5
+	leaf := func(k int) *TreeNode[int, int] { return &TreeNode[int, int]{size: 1, key: k} }
+	build := func() *AvlTree[int, int] {
+		right := &TreeNode[int, int]{height: 1, size: 3, key: 4, left: leaf(3), right: leaf(5)}
+		tree := NewAvlTree[int, int](OrderedComparator[int]())
+		tree.treeRoot = &TreeNode[int, int]{height: 2, size: 5, key: 2, left: leaf(1), right: right}
+		return tree
+	}
+	if err := build().Verify(); err != nil {
+		t.Fatalf("合法的 AVL 树校验失败: %v", err)
+	}
+	if err := NewAvlTree[int, int](OrderedComparator[int]()).Verify(); err != nil {
+		t.Fatalf("空树校验失败: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		corrupt func(tree *AvlTree[int, int])
+		path    string
+	}{
+		{"高度错误", func(tree *AvlTree[int, int]) { tree.treeRoot.right.height = 2 }, "root.R"},
+		{"无序", func(tree *AvlTree[int, int]) { tree.treeRoot.right.left.key = 1 }, "root.R.L"},
+		{"不平衡", func(tree *AvlTree[int, int]) {
+			tree.treeRoot.left = nil
+			tree.treeRoot.right.right.right = leaf(6)
+			tree.treeRoot.right.right.height, tree.treeRoot.right.right.size = 1, 2
+			tree.treeRoot.right.height, tree.treeRoot.right.size = 2, 4
+			tree.treeRoot.height = 3
+		}, "root"},
+		{"size 错误", func(tree *AvlTree[int, int]) { tree.treeRoot.right.size = 2 }, "root.R"},
+	}
+	for _, c := range cases {
+		tree := build()
+		c.corrupt(tree)
+		err := tree.Verify()
+		var ie *InvariantError
+		if !errors.Is(err, ErrInvariant) || !errors.As(err, &ie) || ie.Path() != c.path {
+			t.Errorf("%s: 期望 %s 处的错误, 实际 %v", c.name, c.path, err)
+		}
+	}
 }
@@ -1,102 +1,137 @@
 package bptree
 
+import (
+	"DataStruct/datastruct"
+	"container/list"
+	"sort"
+)
+
 // BNode B+树的结点
-type BPNode struct {
-	num        int       // 当前结点 key 的个数（leaf为kv的数量，index为child数量）
-	maxKey     int64     // 当前结点中最大的 key
-	kvNodes    []*KvNode // KvNode 数组
-	childNodes []*BPNode // 孩子结点
-	isLeaf     bool      // 是否为叶子结点
-	next       *BPNode
+type BPNode[K, V any] struct {
+	num        int             // 当前结点 key 的个数（leaf为kv的数量，index为child数量）
+	maxKey     K               // 当前结点中最大的 key
+	kvNodes    []*KvNode[K, V] // KvNode 数组
+	childNodes []*BPNode[K, V] // 孩子结点
+	isLeaf     bool            // 是否为叶子结点
+	prev       *BPNode[K, V]   // 叶子结点链表的前驱
+	next       *BPNode[K, V]   // 叶子结点链表的后继
+
+	// 以下字段仅由磁盘存储后端使用
+	id     pageID        // 结点所在的页号
+	loaded bool          // 结点内容是否已从磁盘加载
+	dirty  bool          // 结点内容是否被修改且未写回
+	elem   *list.Element // 在页缓存 LRU 链表中的位置
 }
 
 // newIndexNode 新建索引结点
-func newIndexNode(order int) *BPNode {
-	return &BPNode{
+func newIndexNode[K, V any](order int) *BPNode[K, V] {
+	return &BPNode[K, V]{
 		num:        0,
-		childNodes: make([]*BPNode, order+1),
+		childNodes: make([]*BPNode[K, V], order+1),
 		isLeaf:     false,
 	}
 }
 
 // newLeafNode 新建叶子结点
-func newLeafNode(order int) *BPNode {
-	return &BPNode{
+func newLeafNode[K, V any](order int) *BPNode[K, V] {
+	return &BPNode[K, V]{
 		num:     0,
-		kvNodes: make([]*KvNode, order+1),
+		kvNodes: make([]*KvNode[K, V], order+1),
 		isLeaf:  true,
 	}
 }
 
-func (node *BPNode) freeIndexNode() {
+func (node *BPNode[K, V]) freeIndexNode() {
 	node.childNodes = nil
 }
 
-func (node *BPNode) freeLeafNode() {
+func (node *BPNode[K, V]) freeLeafNode() {
 	node.kvNodes = nil
+	node.prev = nil
+	node.next = nil
+}
+
+// search 叶子结点二分查找第一个 >= key 的位置，并返回是否相等
+func (node *BPNode[K, V]) search(key K, cmp datastruct.Comparator[K]) (int, bool) {
+	i := sort.Search(node.num, func(i int) bool {
+		return cmp(node.kvNodes[i].key, key) >= 0
+	})
+	return i, i < node.num && cmp(node.kvNodes[i].key, key) == 0
+}
+
+// childIndex 索引结点中 key 所在子树的下标（大于所有 key 时返回最后一个子树）
+func (node *BPNode[K, V]) childIndex(key K, cmp datastruct.Comparator[K]) int {
+	i := sort.Search(node.num, func(i int) bool {
+		return cmp(key, node.childNodes[i].maxKey) <= 0
+	})
+	if i == node.num {
+		i = node.num - 1
+	}
+	return i
 }
 
-// insertKvn 叶子结点插入 kvn
-func (node *BPNode) insertKvn(kvn *KvNode) {
+// insertKvn 叶子结点插入 kvn，覆盖已有 key 时返回被替换的 kvn，新增时返回 nil
+func (node *BPNode[K, V]) insertKvn(kvn *KvNode[K, V], cmp datastruct.Comparator[K]) *KvNode[K, V] {
 	if node.num == 0 {
 		node.kvNodes[0] = kvn
 		node.maxKey = kvn.key
 		node.num++
-		return
+		return nil
 	}
-	if kvn.key > node.kvNodes[node.num-1].key {
+	if cmp(kvn.key, node.kvNodes[node.num-1].key) > 0 {
 		node.kvNodes[node.num] = kvn
 		node.maxKey = kvn.key
 		node.num++
-		return
+		return nil
 	}
-	for i, v := range node.kvNodes {
-		if v.key == kvn.key {
-			v.value = kvn.value
-			return
-		} else if v.key > kvn.key {
-			copy(node.kvNodes[i+1:], node.kvNodes[i:]) // 后移
-			node.kvNodes[i] = kvn                      // 插入
-			node.num++
-			return
-		}
+	i, ok := node.search(kvn.key, cmp)
+	if ok {
+		old := node.kvNodes[i]
+		node.kvNodes[i] = kvn
+		return old
 	}
+	copy(node.kvNodes[i+1:], node.kvNodes[i:node.num]) // 后移
+	node.kvNodes[i] = kvn                               // 插入
+	node.num++
+	return nil
 }
 
-// deleteKvn 叶子结点删除 kvn
-func (node *BPNode) deleteKvn(key int64) bool {
-	for i := 0; i < node.num; i++ {
-		if key == node.kvNodes[i].key {
-			copy(node.kvNodes[i:], node.kvNodes[i+1:])
-			node.num--
-			if node.num == 0 {
-				node.maxKey = 0 // 暂定为 0
-			} else if i == node.num { // 删除了最后一个点
-				node.maxKey = node.kvNodes[node.num-1].key
-			}
-			return true
-		}
+// deleteKvn 叶子结点删除 key，返回被删除的 kvn（不存在时返回 nil）
+func (node *BPNode[K, V]) deleteKvn(key K, cmp datastruct.Comparator[K]) *KvNode[K, V] {
+	i, ok := node.search(key, cmp)
+	if !ok {
+		return nil
+	}
+	kvn := node.kvNodes[i]
+	copy(node.kvNodes[i:], node.kvNodes[i+1:node.num])
+	node.num--
+	node.kvNodes[node.num] = nil
+	if node.num == 0 {
+		var zero K
+		node.maxKey = zero
+	} else if i == node.num { // 删除了最后一个点
+		node.maxKey = node.kvNodes[node.num-1].key
 	}
-	return false
+	return kvn
 }
 
 // addChild 索引结点添加子结点
-func (node *BPNode) addChild(child *BPNode) {
+func (node *BPNode[K, V]) addChild(child *BPNode[K, V], cmp datastruct.Comparator[K]) {
 	if node.num == 0 {
 		node.childNodes[0] = child
 		node.maxKey = child.maxKey
 		node.num++
 		return
-	} else if child.maxKey > node.childNodes[node.num-1].maxKey {
+	} else if cmp(child.maxKey, node.childNodes[node.num-1].maxKey) > 0 {
 		node.childNodes[node.num] = child
 		node.maxKey = child.maxKey
 		node.num++
 		return
 	}
 
-	for i, n := range node.childNodes {
-		if child.maxKey < n.maxKey { // 后移并插入
-			copy(node.childNodes[i+1:], node.childNodes[i:])
+	for i := 0; i < node.num; i++ {
+		if cmp(child.maxKey, node.childNodes[i].maxKey) < 0 { // 后移并插入
+			copy(node.childNodes[i+1:], node.childNodes[i:node.num])
 			node.childNodes[i] = child
 			node.num++
 			return
@@ -105,17 +140,19 @@ func (node *BPNode) addChild(child *BPNode) {
 }
 
 // deleteChild 索引结点删除子结点
-func (node *BPNode) deleteChild(child *BPNode) {
+func (node *BPNode[K, V]) deleteChild(child *BPNode[K, V]) {
 	if child == node.childNodes[node.num-1] {
 		node.num--
+		node.childNodes[node.num] = nil
 		node.maxKey = node.childNodes[node.num-1].maxKey
 		return
 	}
 
-	for i, n := range node.childNodes {
-		if child == n {
-			copy(node.childNodes[i:], node.childNodes[i+1:])
+	for i := 0; i < node.num; i++ {
+		if child == node.childNodes[i] {
+			copy(node.childNodes[i:], node.childNodes[i+1:node.num])
 			node.num--
+			node.childNodes[node.num] = nil
 			return
 		}
 	}
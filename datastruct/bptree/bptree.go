@@ -5,67 +5,180 @@ import (
 	"fmt"
 )
 
-type BPTree struct {
-	root   *BPNode
-	order  int // 阶数
-	minNum int // 结点最少存在 key 的个数(除root外)
+type BPTree[K, V any] struct {
+	root   *BPNode[K, V]
+	order  int                      // 阶数
+	minNum int                      // 结点最少存在 key 的个数(除root外)
+	length int                      // kv 的数量
+	cmp    datastruct.Comparator[K] // key 比较函数
+	store  pager[K, V]              // 结点存储后端
+	err    error                    // 存储后端发生的第一个错误，之后的操作均不再执行
 }
 
+var _ datastruct.OrderedMap[int, int] = (*BPTree[int, int])(nil)
+
 // NewBPTree 创建 b+ 树
-func NewBPTree(order int) *BPTree {
-	return &BPTree{
-		root:   newLeafNode(order),
+//
+//	@order 阶数（最小为 3）
+//	@cmp key 的比较函数
+func NewBPTree[K, V any](order int, cmp datastruct.Comparator[K]) *BPTree[K, V] {
+	if cmp == nil {
+		panic("BPTree comparator is nil")
+	}
+	if order < 3 {
+		order = 3
+	}
+	return newBPTree[K, V](order, cmp, &memPager[K, V]{order: order}, nil, 0)
+}
+
+// newBPTree 使用指定的存储后端创建 b+ 树，root 为 nil 时新建空的根结点
+func newBPTree[K, V any](order int, cmp datastruct.Comparator[K], store pager[K, V], root *BPNode[K, V], length int) *BPTree[K, V] {
+	if root == nil {
+		root = store.newNode(true)
+	}
+	return &BPTree[K, V]{
+		root:   root,
 		order:  order,
 		minNum: (order + 1) / 2,
+		length: length,
+		cmp:    cmp,
+		store:  store,
+	}
+}
+
+// Err 返回存储后端发生的错误
+//
+// 磁盘存储后端读写失败后，树停止执行后续的修改与查询，需要重新 Open
+func (t *BPTree[K, V]) Err() error {
+	return t.err
+}
+
+// catch 捕获 load 抛出的 storageError，在公开方法中 defer 调用
+func (t *BPTree[K, V]) catch() {
+	if r := recover(); r != nil {
+		se, ok := r.(storageError)
+		if !ok {
+			panic(r)
+		}
+		if t.err == nil {
+			t.err = se.err
+		}
+	}
+}
+
+// result 在 catch 之后 defer 调用，操作本身没有出错时返回存储后端的错误
+func (t *BPTree[K, V]) result(err *error) {
+	if *err == nil {
+		*err = t.err
 	}
 }
 
-// Insert 添加指定的 key
-func (t *BPTree) Insert(key int64, value interface{}) {
+// release 一次操作结束，通知存储后端回收缓存
+func (t *BPTree[K, V]) release() {
+	if err := t.store.release(t.root, t.length); err != nil && t.err == nil {
+		t.err = err
+	}
+}
+
+// tryLoad 加载结点内容，失败时记录错误并返回 false
+func (t *BPTree[K, V]) tryLoad(node *BPNode[K, V]) (ok bool) {
+	defer t.catch()
+	t.store.load(node)
+	return true
+}
+
+// Len 返回 kv 的数量
+func (t *BPTree[K, V]) Len() int {
+	return t.length
+}
+
+// Insert 添加指定的 key，key 已存在时覆盖 value
+func (t *BPTree[K, V]) Insert(key K, value V) {
+	t.Put(key, value)
+}
+
+// Put 添加指定的 key，key 已存在时覆盖 value 并返回旧的 value 与 replaced=true
+//
+// 磁盘存储的 kv 无法编码或超过页大小时拒绝写入，树不做修改，需要知道原因时使用 TryPut
+func (t *BPTree[K, V]) Put(key K, value V) (old V, replaced bool) {
+	old, replaced, _ = t.TryPut(key, value)
+	return
+}
+
+// TryPut 与 Put 相同，同时返回写入失败的原因
+//
+// kv 无法编码或超过页大小（ErrEntryTooLarge）时只拒绝本次写入，树仍可继续使用；
+// 存储后端读写失败时返回的 error 与 Err 相同，之后的操作均不再执行
+func (t *BPTree[K, V]) TryPut(key K, value V) (old V, replaced bool, err error) {
 	if t == nil {
 		panic("BPTree is null")
 	}
+	if t.err != nil {
+		return old, false, t.err
+	}
+	defer t.result(&err)
+	defer t.catch()
+	if err = t.store.log(walInsert, key, value); err != nil {
+		return
+	}
 	kvnode := newKvNode(key, value)
-	t.insert(nil, t.root, kvnode)
+	if oldKvn := t.insert(nil, t.root, kvnode); oldKvn != nil {
+		old, replaced = oldKvn.value, true
+	} else {
+		t.length++
+	}
+	t.release()
+	return
 }
 
-// insert 递归插入调整
-func (t *BPTree) insert(parent, node *BPNode, kvnode *KvNode) {
+// insert 递归插入调整，返回被覆盖的 kvn（新增时为 nil）
+func (t *BPTree[K, V]) insert(parent, node *BPNode[K, V], kvnode *KvNode[K, V]) (old *KvNode[K, V]) {
+	t.store.load(node)
 	// 找到插入结点
-	for i := 0; !node.isLeaf && i < node.num; i++ {
-		if kvnode.key <= node.childNodes[i].maxKey || i == node.num-1 {
-			// 递归查找
-			t.insert(node, node.childNodes[i], kvnode)
-			break
-		}
+	if !node.isLeaf {
+		i := node.childIndex(kvnode.key, t.cmp)
+		// 递归查找
+		old = t.insert(node, node.childNodes[i], kvnode)
+		// 子结点的最大 key 可能变大了
+		node.maxKey = node.childNodes[node.num-1].maxKey
 	}
 
 	// 叶子结点插入数据
 	if node.isLeaf {
-		node.insertKvn(kvnode)
+		old = node.insertKvn(kvnode, t.cmp)
 	}
+	t.store.dirty(node)
 	// 判断是否分裂了
 	newNode := t.spliteNode(node)
 	if newNode != nil {
 		if parent == nil {
-			parent = newIndexNode(t.order)
-			parent.addChild(node)
+			parent = t.store.newNode(false)
+			parent.addChild(node, t.cmp)
 			t.root = parent
 		}
-		parent.addChild(newNode)
+		parent.addChild(newNode, t.cmp)
+		t.store.dirty(parent)
 	}
+	return
 }
 
 // spliteNode 判断是否分裂，分裂了返回分裂结点
-func (t *BPTree) spliteNode(node *BPNode) *BPNode {
+func (t *BPTree[K, V]) spliteNode(node *BPNode[K, V]) *BPNode[K, V] {
 	if node.isLeaf && node.num > t.order { // 叶子结点
 		// 创建新结点
-		newNode := newLeafNode(t.order)
+		newNode := t.store.newNode(true)
 		mid := node.num / 2
 		copy(newNode.kvNodes[:], node.kvNodes[mid:node.num])
+		clear(node.kvNodes[mid:node.num])
 		newNode.num = node.num - mid
 		newNode.maxKey = newNode.kvNodes[newNode.num-1].key
 		newNode.next = node.next
+		newNode.prev = node
+		if node.next != nil {
+			t.store.load(node.next)
+			node.next.prev = newNode
+			t.store.dirty(node.next)
+		}
 
 		// 修改原结点
 		node.num = mid
@@ -74,9 +187,10 @@ func (t *BPTree) spliteNode(node *BPNode) *BPNode {
 		return newNode
 	} else if !node.isLeaf && node.num > t.order { // 索引结点
 		// 创建新结点
-		newNode := newIndexNode(t.order)
+		newNode := t.store.newNode(false)
 		mid := node.num / 2
 		copy(newNode.childNodes[:], node.childNodes[mid:node.num])
+		clear(node.childNodes[mid:node.num])
 		newNode.num = node.num - mid
 		newNode.maxKey = newNode.childNodes[newNode.num-1].maxKey
 
@@ -88,36 +202,56 @@ func (t *BPTree) spliteNode(node *BPNode) *BPNode {
 	return nil
 }
 
-// Delete 删除指定的key
-func (t *BPTree) Delete(key int64) bool {
-	return t.delete(nil, t.root, key)
+// Delete 删除指定的 key，返回被删除的 value
+//
+// 磁盘存储的 key 无法编码时不做修改，需要知道原因时使用 TryDelete
+func (t *BPTree[K, V]) Delete(key K) (value V, ok bool) {
+	value, ok, _ = t.TryDelete(key)
+	return
 }
 
-// delete 递归删除调整
-func (t *BPTree) delete(parent, node *BPNode, key int64) (ok bool) {
-	for i := 0; !node.isLeaf && i < node.num; i++ {
-		if key <= node.childNodes[i].maxKey {
-			ok = t.delete(node, node.childNodes[i], key)
-			break
-		}
+// TryDelete 与 Delete 相同，同时返回删除失败的原因，error 的含义与 TryPut 相同
+func (t *BPTree[K, V]) TryDelete(key K) (value V, ok bool, err error) {
+	if t.err != nil {
+		return value, false, t.err
+	}
+	defer t.result(&err)
+	defer t.catch()
+	var zero V
+	if err = t.store.log(walDelete, key, zero); err != nil {
+		return
+	}
+	if kvn := t.delete(nil, t.root, key); kvn != nil {
+		t.length--
+		value, ok = kvn.value, true
+	}
+	t.release()
+	return
+}
+
+// delete 递归删除调整，返回被删除的 kvn
+func (t *BPTree[K, V]) delete(parent, node *BPNode[K, V], key K) (kvn *KvNode[K, V]) {
+	t.store.load(node)
+	if !node.isLeaf {
+		i := node.childIndex(key, t.cmp)
+		kvn = t.delete(node, node.childNodes[i], key)
 	}
 
 	// 删除并判断是否需要移动或合并
 	if node.isLeaf {
-		ok = node.deleteKvn(key)
+		if kvn = node.deleteKvn(key, t.cmp); kvn == nil {
+			return
+		}
+		t.store.dirty(node)
 		if node.num < t.minNum {
 			t.kvnMoveOrMerge(parent, node)
 		}
-	} else {
+	} else if kvn != nil {
 		node.maxKey = node.childNodes[node.num-1].maxKey
-		if node.num == 1 { // 保证 b+ 树的形态
-			if parent != nil {
-				parent.childNodes[0] = node.childNodes[0]
-				parent.maxKey = node.childNodes[0].maxKey
-			} else {
-				t.root = node.childNodes[0]
-			}
-			node.freeIndexNode()
+		t.store.dirty(node)
+		if node.num == 1 && parent == nil { // 保证 b+ 树的形态，根结点只剩一个孩子时降低树高
+			t.root = node.childNodes[0]
+			t.store.free(node)
 		} else if node.num < t.minNum {
 			t.childMoveOrMerge(parent, node)
 		}
@@ -126,15 +260,8 @@ func (t *BPTree) delete(parent, node *BPNode, key int64) (ok bool) {
 	return
 }
 
-// kvnMoveOrMerge 移动或合并叶子结点
-func (t *BPTree) kvnMoveOrMerge(parent, node *BPNode) {
-	if parent == nil {
-		return
-	}
-	var (
-		leftSib  *BPNode
-		rightSib *BPNode
-	)
+// siblings 获取 node 在 parent 中的左右兄弟
+func (t *BPTree[K, V]) siblings(parent, node *BPNode[K, V]) (leftSib, rightSib *BPNode[K, V]) {
 	for i := 0; i < parent.num; i++ {
 		if parent.childNodes[i] == node {
 			if i > 0 {
@@ -146,35 +273,57 @@ func (t *BPTree) kvnMoveOrMerge(parent, node *BPNode) {
 			break
 		}
 	}
+	if leftSib != nil {
+		t.store.load(leftSib)
+		t.store.dirty(leftSib)
+	}
+	if rightSib != nil {
+		t.store.load(rightSib)
+		t.store.dirty(rightSib)
+	}
+	return
+}
+
+// kvnMoveOrMerge 移动或合并叶子结点
+func (t *BPTree[K, V]) kvnMoveOrMerge(parent, node *BPNode[K, V]) {
+	if parent == nil {
+		return
+	}
+	leftSib, rightSib := t.siblings(parent, node)
 
 	// move
 	if leftSib != nil && leftSib.num > t.minNum {
-		copy(node.kvNodes[1:], node.kvNodes[0:])
+		copy(node.kvNodes[1:], node.kvNodes[0:node.num])
 		node.kvNodes[0] = leftSib.kvNodes[leftSib.num-1]
 		leftSib.num--
+		leftSib.kvNodes[leftSib.num] = nil
 		leftSib.maxKey = leftSib.kvNodes[leftSib.num-1].key
 		node.num++
-		// parent.maxKey = parent.childNodes[parent.num-1].maxKey
+		node.maxKey = node.kvNodes[node.num-1].key
 		return
 	}
 	if rightSib != nil && rightSib.num > t.minNum {
 		node.kvNodes[node.num] = rightSib.kvNodes[0]
 		node.maxKey = rightSib.kvNodes[0].key
-		copy(rightSib.kvNodes[0:], rightSib.kvNodes[1:])
+		copy(rightSib.kvNodes[0:], rightSib.kvNodes[1:rightSib.num])
 		rightSib.num--
+		rightSib.kvNodes[rightSib.num] = nil
 		node.num++
-		// parent.maxKey = parent.childNodes[parent.num-1].maxKey
 		return
 	}
 	// merge
 	if leftSib != nil {
 		copy(leftSib.kvNodes[leftSib.num:], node.kvNodes[:node.num])
-		leftSib.maxKey = node.maxKey
 		leftSib.num += node.num
+		leftSib.maxKey = leftSib.kvNodes[leftSib.num-1].key
 		leftSib.next = node.next
+		if node.next != nil {
+			t.store.load(node.next)
+			node.next.prev = leftSib
+			t.store.dirty(node.next)
+		}
 		parent.deleteChild(node)
-		// parent.maxKey = parent.childNodes[parent.num-1].maxKey
-		node.freeLeafNode()
+		t.store.free(node)
 		return
 	}
 	if rightSib != nil {
@@ -182,49 +331,41 @@ func (t *BPTree) kvnMoveOrMerge(parent, node *BPNode) {
 		node.maxKey = rightSib.maxKey
 		node.num += rightSib.num
 		node.next = rightSib.next
+		if rightSib.next != nil {
+			t.store.load(rightSib.next)
+			rightSib.next.prev = node
+			t.store.dirty(rightSib.next)
+		}
 		parent.deleteChild(rightSib)
-		// parent.maxKey = parent.childNodes[parent.num-1].maxKey
-		rightSib.freeLeafNode()
+		t.store.free(rightSib)
 		return
 	}
 }
 
 // childMoveOrMerge 移动或合并索引结点
-func (t *BPTree) childMoveOrMerge(parent, node *BPNode) {
+func (t *BPTree[K, V]) childMoveOrMerge(parent, node *BPNode[K, V]) {
 	if parent == nil {
 		return
 	}
-	var (
-		leftSib  *BPNode
-		rightSib *BPNode
-	)
-	for i := 0; i < parent.num; i++ {
-		if parent.childNodes[i] == node {
-			if i < parent.num-1 {
-				rightSib = parent.childNodes[i+1]
-			}
-			if i > 0 {
-				leftSib = parent.childNodes[i-1]
-			}
-		}
-	}
+	leftSib, rightSib := t.siblings(parent, node)
 
 	// move
 	if leftSib != nil && leftSib.num > t.minNum {
-		copy(node.childNodes[1:], node.childNodes[0:])
+		copy(node.childNodes[1:], node.childNodes[0:node.num])
 		node.childNodes[0] = leftSib.childNodes[leftSib.num-1]
 		node.num++
 		leftSib.num--
+		leftSib.childNodes[leftSib.num] = nil
 		leftSib.maxKey = leftSib.childNodes[leftSib.num-1].maxKey
-		// parent.maxKey = parent.childNodes[parent.num-1].maxKey
 		return
 	}
 	if rightSib != nil && rightSib.num > t.minNum {
 		node.childNodes[node.num] = rightSib.childNodes[0]
 		node.maxKey = rightSib.childNodes[0].maxKey
 		node.num++
+		copy(rightSib.childNodes[0:], rightSib.childNodes[1:rightSib.num])
 		rightSib.num--
-		// parent.maxKey = parent.childNodes[parent.num-1].maxKey
+		rightSib.childNodes[rightSib.num] = nil
 		return
 	}
 	// merge
@@ -232,53 +373,44 @@ func (t *BPTree) childMoveOrMerge(parent, node *BPNode) {
 		copy(leftSib.childNodes[leftSib.num:], node.childNodes[:node.num])
 		leftSib.maxKey = node.maxKey
 		leftSib.num += node.num
-		leftSib.next = node.next
 		parent.deleteChild(node)
-		// parent.maxKey = parent.childNodes[parent.num-1].maxKey
-		node.freeIndexNode()
+		t.store.free(node)
 		return
 	}
 	if rightSib != nil {
 		copy(node.childNodes[node.num:], rightSib.childNodes[:rightSib.num])
 		node.maxKey = rightSib.maxKey
 		node.num += rightSib.num
-		node.next = rightSib.next
 		parent.deleteChild(rightSib)
-		// parent.maxKey = parent.childNodes[parent.num-1].maxKey
-		rightSib.freeIndexNode()
+		t.store.free(rightSib)
 		return
 	}
 }
 
-func (t *BPTree) printInLog() {
+func (t *BPTree[K, V]) printInLog() {
 	if t.root == nil {
 		return
 	}
 	var (
 		flag = true
-		list *BPNode
+		list *BPNode[K, V]
 	)
 	queue := datastruct.NewQueue(10)
 	queue.Push(t.root)
 	for queue.Size() != 0 {
 		size := queue.Size()
 		for i := 0; i < size; i++ {
-			node, _ := queue.Pop().(*BPNode)
-			// if !node.isLeaf {
-			// 	fmt.Printf("maxchild:[%d] ", node.childNodes[node.num-1].maxKey)
-			// } else {
-			// 	fmt.Printf("maxKey:[%d] ", node.maxKey)
-			// }
+			node, _ := queue.Pop().(*BPNode[K, V])
 			for i := 0; i < node.num; i++ {
 				if !node.isLeaf {
-					fmt.Printf("%d ", node.childNodes[i].maxKey)
+					fmt.Printf("%v ", node.childNodes[i].maxKey)
 					queue.Push(node.childNodes[i])
 				} else {
 					if flag {
 						list = node
 						flag = false
 					}
-					fmt.Printf("[%d,%d] ", node.kvNodes[i].key, node.kvNodes[i].value)
+					fmt.Printf("[%v,%v] ", node.kvNodes[i].key, node.kvNodes[i].value)
 				}
 			}
 			fmt.Print("| ")
@@ -289,7 +421,7 @@ func (t *BPTree) printInLog() {
 	fmt.Println("--------------leaf----------------")
 	for list != nil {
 		for i := 0; i < list.num; i++ {
-			fmt.Printf("%d ", list.kvNodes[i].key)
+			fmt.Printf("%v ", list.kvNodes[i].key)
 		}
 		fmt.Print("--> ")
 		list = list.next
@@ -1,12 +1,16 @@
 package bptree
 
 import (
+	"DataStruct/datastruct"
 	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
 	"testing"
 )
 
 func TestBPTree(t *testing.T) {
-	tree := NewBPTree(5)
+	tree := NewBPTree[int64, int](5, datastruct.OrderedComparator[int64]())
 	tree.Insert(39, 39)
 	tree.Insert(22, 22)
 	tree.Insert(97, 97)
@@ -62,3 +66,104 @@ func TestBPTree(t *testing.T) {
 	tree.Insert(97, 97)
 	tree.printInLog()
 }
+
+// 随机插入删除后与有序切片对比
+func TestBPTreeRandom(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 8, 32} {
+		tree := NewBPTree[int, int](order, datastruct.OrderedComparator[int]())
+		ref := make(map[int]int)
+		r := rand.New(rand.NewSource(int64(order)))
+		for i := 0; i < 3000; i++ {
+			key := r.Intn(500)
+			if r.Intn(3) == 0 {
+				_, exist := ref[key]
+				if _, ok := tree.Delete(key); ok != exist {
+					t.Fatalf("order=%d 删除 %d 结果错误", order, key)
+				}
+				delete(ref, key)
+			} else {
+				tree.Insert(key, i)
+				ref[key] = i
+			}
+		}
+		if tree.Len() != len(ref) {
+			t.Fatalf("order=%d Len=%d, 期望 %d", order, tree.Len(), len(ref))
+		}
+		keys := make([]int, 0, len(ref))
+		for k := range ref {
+			keys = append(keys, k)
+			if v, ok := tree.Get(k); !ok || v != ref[k] {
+				t.Fatalf("order=%d Get(%d)=%d,%v, 期望 %d", order, k, v, ok, ref[k])
+			}
+		}
+		sort.Ints(keys)
+
+		var asc []int
+		tree.Ascend(func(k, v int) bool {
+			asc = append(asc, k)
+			return true
+		})
+		if !slices.Equal(asc, keys) {
+			t.Fatalf("order=%d Ascend 顺序错误", order)
+		}
+		var desc []int
+		tree.Descend(func(k, v int) bool {
+			desc = append(desc, k)
+			return true
+		})
+		slices.Reverse(desc)
+		if !slices.Equal(desc, keys) {
+			t.Fatalf("order=%d Descend 顺序错误", order)
+		}
+	}
+}
+
+func TestBPTreeRangeAndSeek(t *testing.T) {
+	tree := NewBPTree[int, string](4, datastruct.OrderedComparator[int]())
+	for i := 0; i < 100; i += 2 {
+		tree.Insert(i, fmt.Sprint(i))
+	}
+	if _, ok := tree.Get(3); ok {
+		t.Error("不应该找到 3")
+	}
+
+	var got []int
+	tree.Range(11, 21, func(k int, v string) bool {
+		got = append(got, k)
+		return true
+	})
+	if !slices.Equal(got, []int{12, 14, 16, 18, 20}) {
+		t.Errorf("Range(11, 21) = %v", got)
+	}
+
+	// 提前终止
+	got = got[:0]
+	tree.Ascend(func(k int, v string) bool {
+		got = append(got, k)
+		return len(got) < 3
+	})
+	if !slices.Equal(got, []int{0, 2, 4}) {
+		t.Errorf("Ascend 提前终止 = %v", got)
+	}
+
+	it := tree.Seek(51)
+	if !it.Valid() || it.Key() != 52 || it.Value() != "52" {
+		t.Fatalf("Seek(51) 应指向 52")
+	}
+	it.Prev()
+	it.Prev()
+	if !it.Valid() || it.Key() != 48 {
+		t.Fatalf("Prev 两次应指向 48")
+	}
+	if it = tree.Seek(99); it.Valid() {
+		t.Errorf("Seek(99) 应无效，实际指向 %d", it.Key())
+	}
+	if it = tree.Seek(-1); !it.Valid() || it.Key() != 0 {
+		t.Errorf("Seek(-1) 应指向 0")
+	}
+
+	empty := NewBPTree[int, int](4, datastruct.OrderedComparator[int]())
+	if empty.First().Valid() || empty.Last().Valid() || empty.Seek(0).Valid() {
+		t.Error("空树迭代器应无效")
+	}
+}
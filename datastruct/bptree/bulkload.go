@@ -0,0 +1,70 @@
+package bptree
+
+import (
+	"DataStruct/datastruct"
+	"math"
+)
+
+// BulkLoad 由按 key 严格升序的序列自底向上构建内存 b+ 树
+//
+//	@order 阶数（最小为 3）
+//	@fillFactor 结点的填充率，取值 (0, 1]，超出范围时按 1 处理；结点的大小不会低于 b+ 树的下限
+//	@cmp key 的比较函数
+//	@seq 有序的 kv 序列，出现乱序或重复的 key 时返回 *datastruct.UnsortedInputError
+func BulkLoad[K, V any](order int, fillFactor float64, cmp datastruct.Comparator[K], seq datastruct.Seq2[K, V]) (*BPTree[K, V], error) {
+	t := NewBPTree[K, V](order, cmp)
+	var kvns []*KvNode[K, V]
+	err := datastruct.ForEachSorted(seq, cmp, func(key K, value V) {
+		kvns = append(kvns, newKvNode(key, value))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(kvns) == 0 {
+		return t, nil
+	}
+
+	if fillFactor <= 0 || fillFactor > 1 {
+		fillFactor = 1
+	}
+	target := int(math.Round(fillFactor * float64(t.order)))
+	target = min(max(target, t.minNum), t.order)
+
+	// 叶子层，按顺序串成链表
+	var (
+		nodes []*BPNode[K, V]
+		pos   int
+		prev  *BPNode[K, V]
+	)
+	for _, size := range datastruct.SplitSizes(len(kvns), target, t.minNum) {
+		leaf := t.store.newNode(true)
+		copy(leaf.kvNodes, kvns[pos:pos+size])
+		leaf.num = size
+		leaf.maxKey = leaf.kvNodes[size-1].key
+		if prev != nil {
+			prev.next = leaf
+			leaf.prev = prev
+		}
+		prev = leaf
+		nodes = append(nodes, leaf)
+		pos += size
+	}
+
+	// 逐层构建索引结点，直到只剩一个根结点
+	for len(nodes) > 1 {
+		var parents []*BPNode[K, V]
+		pos = 0
+		for _, size := range datastruct.SplitSizes(len(nodes), target, t.minNum) {
+			parent := t.store.newNode(false)
+			copy(parent.childNodes, nodes[pos:pos+size])
+			parent.num = size
+			parent.maxKey = parent.childNodes[size-1].maxKey
+			parents = append(parents, parent)
+			pos += size
+		}
+		nodes = parents
+	}
+	t.root = nodes[0]
+	t.length = len(kvns)
+	return t, nil
+}
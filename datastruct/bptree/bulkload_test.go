@@ -0,0 +1,116 @@
+package bptree
+
+import (
+	"DataStruct/datastruct"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func sliceSeq(keys []int) datastruct.Seq2[int, int] {
+	return func(yield func(key, value int) bool) {
+		for _, k := range keys {
+			if !yield(k, k*10) {
+				return
+			}
+		}
+	}
+}
+
+// checkShape 校验 b+ 树结点大小、叶子深度与叶子链表
+func checkShape[K, V any](t *testing.T, tree *BPTree[K, V]) {
+	t.Helper()
+	var (
+		leaves    []*BPNode[K, V]
+		leafDepth = -1
+		walk      func(node *BPNode[K, V], depth int)
+	)
+	walk = func(node *BPNode[K, V], depth int) {
+		if node != tree.root && (node.num < tree.minNum || node.num > tree.order) {
+			t.Fatalf("结点大小 %d 超出范围 [%d, %d]", node.num, tree.minNum, tree.order)
+		}
+		if node.isLeaf {
+			if leafDepth == -1 {
+				leafDepth = depth
+			} else if leafDepth != depth {
+				t.Fatalf("叶子结点深度不一致: %d, %d", leafDepth, depth)
+			}
+			leaves = append(leaves, node)
+			return
+		}
+		if node == tree.root && node.num < 2 {
+			t.Fatalf("根索引结点只有 %d 个孩子", node.num)
+		}
+		for i := 0; i < node.num; i++ {
+			walk(node.childNodes[i], depth+1)
+		}
+		if tree.cmp(node.maxKey, node.childNodes[node.num-1].maxKey) != 0 {
+			t.Fatalf("索引结点 maxKey 错误")
+		}
+	}
+	walk(tree.root, 0)
+	for i, leaf := range leaves {
+		if (i > 0 && leaf.prev != leaves[i-1]) || (i < len(leaves)-1 && leaf.next != leaves[i+1]) {
+			t.Fatalf("叶子链表错误")
+		}
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+}
+
+func TestBulkLoad(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 8, 32} {
+		for _, fill := range []float64{0, 0.1, 0.5, 0.75, 1} {
+			for n := 0; n < 500; n += 13 {
+				keys := make([]int, n)
+				for i := range keys {
+					keys[i] = i * 2
+				}
+				tree, err := BulkLoad[int, int](order, fill, datastruct.OrderedComparator[int](), sliceSeq(keys))
+				if err != nil {
+					t.Fatal(err)
+				}
+				checkShape(t, tree)
+				if tree.Len() != n {
+					t.Fatalf("Len=%d, 期望 %d", tree.Len(), n)
+				}
+				var got []int
+				tree.Ascend(func(k, v int) bool {
+					if v != k*10 {
+						t.Fatalf("key %d 的 value 为 %d", k, v)
+					}
+					got = append(got, k)
+					return true
+				})
+				if !slices.Equal(got, keys) && n > 0 {
+					t.Fatalf("order=%d fill=%v n=%d: 遍历结果 %v", order, fill, n, got)
+				}
+
+				// 批量加载后的树可以继续插入与删除
+				for i := 0; i < n; i += 3 {
+					tree.Insert(i*2+1, 0)
+					if _, ok := tree.Delete(i * 2); !ok {
+						t.Fatalf("删除 %d 失败", i*2)
+					}
+				}
+				checkShape(t, tree)
+				if tree.Len() != n {
+					t.Fatalf("Len=%d, 期望 %d", tree.Len(), n)
+				}
+			}
+		}
+	}
+}
+
+func TestBulkLoadUnsorted(t *testing.T) {
+	cmp := datastruct.OrderedComparator[int]()
+	_, err := BulkLoad[int, int](4, 1, cmp, sliceSeq([]int{1, 2, 3, 3}))
+	var ue *datastruct.UnsortedInputError
+	if !errors.As(err, &ue) || !errors.Is(err, datastruct.ErrDuplicateKey) || ue.Index() != 3 {
+		t.Errorf("期望 ErrDuplicateKey, 实际 %v", err)
+	}
+	if _, err = BulkLoad[int, int](4, 1, cmp, sliceSeq([]int{1, 3, 2})); !errors.Is(err, datastruct.ErrUnsortedInput) {
+		t.Errorf("期望 ErrUnsortedInput, 实际 %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package bptree
+
+import (
+	"DataStruct/datastruct"
+	"container/list"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrClosed 树已经关闭
+var ErrClosed = errors.New("bptree: tree is closed")
+
+const (
+	defaultPageSize  = 4096 // 默认页大小
+	defaultCacheSize = 256  // 默认页缓存大小（结点数）
+	defaultDiskOrder = 64   // 磁盘存储默认阶数
+)
+
+// options 磁盘存储的配置
+type options struct {
+	order     int
+	pageSize  int
+	cacheSize int
+	sync      bool
+}
+
+// Option 磁盘存储的配置项
+type Option func(*options)
+
+// WithOrder 设置阶数，仅在创建新文件时生效，已有文件使用文件头中记录的阶数
+func WithOrder(order int) Option {
+	return func(o *options) {
+		if order >= 3 {
+			o.order = order
+		}
+	}
+}
+
+// WithPageSize 设置页大小，仅在创建新文件时生效，已有文件使用文件头中记录的页大小
+func WithPageSize(size int) Option {
+	return func(o *options) {
+		if size >= minPageSize {
+			o.pageSize = size
+		}
+	}
+}
+
+// WithCacheSize 设置页缓存最多保存的结点数
+func WithCacheSize(pages int) Option {
+	return func(o *options) {
+		if pages > 0 {
+			o.cacheSize = pages
+		}
+	}
+}
+
+// WithSyncWrites 设置每条 WAL 记录写入后是否 fsync（默认为 true）
+//
+// 关闭后进程崩溃不会丢失数据，但操作系统崩溃或断电可能丢失最近的修改
+func WithSyncWrites(sync bool) Option {
+	return func(o *options) {
+		o.sync = sync
+	}
+}
+
+// Open 打开基于文件的 b+ 树，文件不存在时创建
+//
+// 结点按页保存在文件中，访问时加载到页缓存，缓存满时按 LRU 淘汰未修改的结点。
+// 每次插入删除先追加到 path+".wal" 预写日志，修改过的结点在 Checkpoint 时才写回数据文件，
+// 打开时若发现上次没有正常关闭，会在数据文件的基础上重放日志恢复。读写错误通过 Err 获取。
+//
+//	@path 数据文件路径
+//	@cmp key 的比较函数
+//	@keyCodec、valueCodec key 与 value 的编码，单个结点编码后必须能放入一页
+func Open[K, V any](path string, cmp datastruct.Comparator[K], keyCodec datastruct.Codec[K], valueCodec datastruct.Codec[V], opts ...Option) (*BPTree[K, V], error) {
+	if cmp == nil || keyCodec == nil || valueCodec == nil {
+		return nil, fmt.Errorf("bptree: comparator and codecs must not be nil")
+	}
+	o := &options{
+		order:     defaultDiskOrder,
+		pageSize:  defaultPageSize,
+		cacheSize: defaultCacheSize,
+		sync:      true,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	w, err := openWAL(path+".wal", o.sync)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	p := &filePager[K, V]{
+		file:       file,
+		pageSize:   o.pageSize,
+		order:      o.order,
+		cacheSize:  o.cacheSize,
+		keyCodec:   keyCodec,
+		valueCodec: valueCodec,
+		nodes:      make(map[pageID]*BPNode[K, V]),
+		lru:        list.New(),
+		pageCount:  1,
+		wal:        w,
+	}
+	t, err := open(p, cmp)
+	if err != nil {
+		p.close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// open 崩溃恢复后加载或新建树
+func open[K, V any](p *filePager[K, V], cmp datastruct.Comparator[K]) (*BPTree[K, V], error) {
+	ops, err := p.recover()
+	if err != nil {
+		return nil, err
+	}
+	info, err := p.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var t *BPTree[K, V]
+	if info.Size() == 0 { // 新文件
+		p.buf = make([]byte, p.pageSize)
+		t = newBPTree[K, V](p.order, cmp, p, nil, 0)
+	} else {
+		h, err := p.readHeader()
+		if err != nil {
+			return nil, err
+		}
+		t = newBPTree[K, V](p.order, cmp, p, p.ref(h.root), int(h.length))
+		if !t.tryLoad(t.root) {
+			return nil, t.err
+		}
+	}
+
+	// 重放上次 checkpoint 之后的操作
+	p.replaying = true
+	for _, r := range ops {
+		key, value, err := p.decodeLog(r)
+		if err != nil {
+			return nil, err
+		}
+		if r.typ == walInsert {
+			t.Insert(key, value)
+		} else {
+			t.Delete(key)
+		}
+	}
+	p.replaying = false
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	if info.Size() == 0 || len(ops) > 0 || p.wal.size > 0 {
+		if err := t.Checkpoint(); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// Checkpoint 将修改写入数据文件并清空预写日志，内存存储时不做任何事
+func (t *BPTree[K, V]) Checkpoint() error {
+	if t.err != nil {
+		return t.err
+	}
+	if err := t.store.checkpoint(t.root, t.length); err != nil {
+		t.err = err
+	}
+	return t.err
+}
+
+// Close Checkpoint 后关闭存储，之后不能再使用该树
+func (t *BPTree[K, V]) Close() error {
+	err := t.Checkpoint()
+	if cerr := t.store.close(); err == nil {
+		err = cerr
+	}
+	if t.err == nil {
+		t.err = ErrClosed
+	}
+	return err
+}
@@ -0,0 +1,316 @@
+package bptree
+
+import (
+	"DataStruct/datastruct"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func openTestTree(t *testing.T, path string, opts ...Option) *BPTree[int64, string] {
+	t.Helper()
+	tree, err := Open[int64, string](path, datastruct.OrderedComparator[int64](),
+		datastruct.IntegerCodec[int64]{}, datastruct.StringCodec{}, opts...)
+	if err != nil {
+		t.Fatalf("Open 失败: %v", err)
+	}
+	return tree
+}
+
+// checkTree 校验树中的数据与 ref 一致
+func checkTree(t *testing.T, tree *BPTree[int64, string], ref map[int64]string) {
+	t.Helper()
+	if tree.Len() != len(ref) {
+		t.Fatalf("Len=%d, 期望 %d", tree.Len(), len(ref))
+	}
+	keys := make([]int64, 0, len(ref))
+	for k, v := range ref {
+		keys = append(keys, k)
+		if got, ok := tree.Get(k); !ok || got != v {
+			t.Fatalf("Get(%d)=%q,%v, 期望 %q", k, got, ok, v)
+		}
+	}
+	slices.Sort(keys)
+	var asc, desc []int64
+	tree.Ascend(func(k int64, v string) bool {
+		asc = append(asc, k)
+		return true
+	})
+	tree.Descend(func(k int64, v string) bool {
+		desc = append(desc, k)
+		return true
+	})
+	slices.Reverse(desc)
+	if !slices.Equal(asc, keys) || !slices.Equal(desc, keys) {
+		t.Fatalf("遍历结果与期望不一致")
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+}
+
+func TestDiskBPTreeReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	// 小页缓存，保证操作过程中不断淘汰与重新加载结点
+	tree := openTestTree(t, path, WithOrder(8), WithPageSize(512), WithCacheSize(4))
+	ref := make(map[int64]string)
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 2000; i++ {
+			key := r.Int63n(3000)
+			if r.Intn(4) == 0 {
+				tree.Delete(key)
+				delete(ref, key)
+			} else {
+				val := fmt.Sprintf("v%d-%d", key, i)
+				tree.Insert(key, val)
+				ref[key] = val
+			}
+		}
+		checkTree(t, tree, ref)
+		if err := tree.Close(); err != nil {
+			t.Fatalf("Close 失败: %v", err)
+		}
+		if tree.Insert(1, "x"); !errors.Is(tree.Err(), ErrClosed) {
+			t.Fatalf("关闭后 Err 应为 ErrClosed, 实际 %v", tree.Err())
+		}
+
+		// 重新打开，阶数与页大小以文件头为准
+		tree = openTestTree(t, path, WithOrder(100), WithCacheSize(4))
+		if tree.order != 8 {
+			t.Fatalf("重新打开后 order=%d, 期望 8", tree.order)
+		}
+		checkTree(t, tree, ref)
+	}
+
+	var got []int64
+	tree.Range(100, 200, func(k int64, v string) bool {
+		got = append(got, k)
+		return true
+	})
+	var want []int64
+	for k := range ref {
+		if k >= 100 && k < 200 {
+			want = append(want, k)
+		}
+	}
+	sort.Slice(want, func(i, j int) bool { return want[i] < want[j] })
+	if !slices.Equal(got, want) {
+		t.Fatalf("Range(100, 200) = %v, 期望 %v", got, want)
+	}
+	tree.Close()
+}
+
+func TestDiskBPTreeFreeList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTestTree(t, path, WithOrder(4), WithPageSize(256), WithCacheSize(8))
+	for i := int64(0); i < 500; i++ {
+		tree.Insert(i, "value")
+	}
+	for i := int64(0); i < 500; i++ {
+		tree.Delete(i)
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+	info, _ := os.Stat(path)
+	size := info.Size()
+
+	// 释放的页应被重新使用，文件不再增长
+	tree = openTestTree(t, path)
+	if tree.Len() != 0 {
+		t.Fatalf("Len=%d, 期望 0", tree.Len())
+	}
+	for i := int64(0); i < 500; i++ {
+		tree.Insert(i, "value")
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ = os.Stat(path); info.Size() > size {
+		t.Errorf("文件大小从 %d 增长到 %d，空闲页未被复用", size, info.Size())
+	}
+}
+
+func TestDiskBPTreeNodesBounded(t *testing.T) {
+	const (
+		order     = 4
+		cacheSize = 4
+		n         = 2000
+	)
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTestTree(t, path, WithOrder(order), WithPageSize(256), WithCacheSize(cacheSize), WithSyncWrites(false))
+	p := tree.store.(*filePager[int64, string])
+	// 已加载的结点不超过 cacheSize 个，每个最多引用 order+1 个结点，prune 后 nodes 至多翻倍
+	limit := 2 * (cacheSize*(order+1) + 1)
+	check := func(op string) {
+		t.Helper()
+		if len(p.nodes) > limit {
+			t.Fatalf("%s 后 nodes 有 %d 个结点, 期望不超过 %d", op, len(p.nodes), limit)
+		}
+	}
+	for i := int64(0); i < n; i++ {
+		tree.Insert(i, "value")
+		check("Insert")
+	}
+	if err := tree.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 读取的页数远多于页缓存
+	tree = openTestTree(t, path, WithCacheSize(cacheSize))
+	p = tree.store.(*filePager[int64, string])
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		key := r.Int63n(n)
+		if v, ok := tree.Get(key); !ok || v != "value" {
+			t.Fatalf("Get(%d)=%q,%v", key, v, ok)
+		}
+		check("Get")
+	}
+	count := 0
+	for it := tree.First(); it.Valid(); it.Next() {
+		count++
+	}
+	check("遍历")
+	if count != n {
+		t.Fatalf("遍历到 %d 个, 期望 %d", count, n)
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	tree.Close()
+}
+
+func TestDiskBPTreeRejectedWrites(t *testing.T) {
+	t.Run("kv 超过页大小", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tree.db")
+		// 每个 kv 最多 (128 - 11) / 4 = 29 字节
+		tree := openTestTree(t, path, WithOrder(4), WithPageSize(128), WithCacheSize(1))
+		for i := int64(0); i < 20; i++ {
+			tree.Insert(i, "v")
+		}
+		if _, _, err := tree.TryPut(100, strings.Repeat("x", 64)); !errors.Is(err, ErrEntryTooLarge) {
+			t.Fatalf("期望 ErrEntryTooLarge, 实际 %v", err)
+		}
+		tree.Insert(101, strings.Repeat("x", 64))
+		if _, _, err := tree.TryPut(5, strings.Repeat("x", 64)); !errors.Is(err, ErrEntryTooLarge) {
+			t.Fatalf("覆盖已有 key 期望 ErrEntryTooLarge, 实际 %v", err)
+		}
+		if tree.Err() != nil || tree.Len() != 20 {
+			t.Fatalf("拒绝写入后 Err=%v Len=%d, 期望 nil 20", tree.Err(), tree.Len())
+		}
+		if v, _ := tree.Get(5); v != "v" {
+			t.Fatalf("Get(5)=%q, 被拒绝的写入不应修改树", v)
+		}
+		if err := tree.Close(); err != nil {
+			t.Fatalf("Close 失败: %v", err)
+		}
+
+		// 被拒绝的写入没有进入 WAL，文件可以重新打开
+		tree = openTestTree(t, path)
+		defer tree.Close()
+		if _, ok := tree.Get(100); ok || tree.Len() != 20 {
+			t.Fatalf("重新打开后 Len=%d, Get(100)=%v", tree.Len(), ok)
+		}
+		tree.Insert(100, "v")
+		if err := tree.Verify(); err != nil || tree.Err() != nil {
+			t.Fatalf("Verify=%v Err=%v", err, tree.Err())
+		}
+	})
+	t.Run("value 无法编码", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "tree.db")
+		tree, err := Open[int64, float64](path, datastruct.OrderedComparator[int64](),
+			datastruct.IntegerCodec[int64]{}, datastruct.JSONCodec[float64]{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tree.Insert(1, 1.5)
+		if _, _, err := tree.TryPut(2, math.NaN()); err == nil {
+			t.Fatal("NaN 应返回编码错误")
+		}
+		if tree.Err() != nil {
+			t.Fatalf("编码错误不应使树停止工作, Err=%v", tree.Err())
+		}
+		tree.Insert(3, 2.5)
+		if v, ok := tree.Get(3); !ok || v != 2.5 || tree.Len() != 2 {
+			t.Fatalf("Get(3)=%v,%v Len=%d", v, ok, tree.Len())
+		}
+		if _, _, err := tree.TryDelete(3); err != nil {
+			t.Fatalf("TryDelete 失败: %v", err)
+		}
+		if err := tree.Close(); err != nil {
+			t.Fatalf("Close 失败: %v", err)
+		}
+		if _, _, err := tree.TryPut(4, 1); !errors.Is(err, ErrClosed) {
+			t.Fatalf("关闭后 TryPut 期望 ErrClosed, 实际 %v", err)
+		}
+	})
+}
+
+// 遍历过程中的其他操作会淘汰迭代器所在的叶子结点
+func TestDiskBPTreeScanWithLookups(t *testing.T) {
+	const n = 500
+	tree := openTestTree(t, filepath.Join(t.TempDir(), "tree.db"),
+		WithOrder(4), WithPageSize(256), WithCacheSize(1), WithSyncWrites(false))
+	defer tree.Close()
+	for i := int64(0); i < n; i++ {
+		tree.Insert(i, fmt.Sprint(i))
+	}
+	if err := tree.Checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	tree.Ascend(func(k int64, v string) bool {
+		if got, ok := tree.Get(n - 1 - k); !ok || got != fmt.Sprint(n-1-k) {
+			t.Fatalf("Get(%d)=%q,%v", n-1-k, got, ok)
+		}
+		count++
+		return true
+	})
+	if count != n {
+		t.Fatalf("Ascend 中查找, 遍历到 %d 个, 期望 %d", count, n)
+	}
+	count = 0
+	tree.Descend(func(k int64, v string) bool {
+		tree.Get(0)
+		count++
+		return true
+	})
+	if count != n {
+		t.Fatalf("Descend 中查找, 遍历到 %d 个, 期望 %d", count, n)
+	}
+
+	// 两个迭代器交替移动
+	a, b := tree.First(), tree.Last()
+	for i := int64(0); i < n; i++ {
+		if !a.Valid() || !b.Valid() || a.Key() != i || b.Key() != n-1-i {
+			t.Fatalf("第 %d 步: 两个迭代器交替移动时提前结束", i)
+		}
+		a.Next()
+		b.Prev()
+	}
+	if a.Valid() || b.Valid() || tree.Err() != nil {
+		t.Fatalf("遍历结束后迭代器仍有效或 Err=%v", tree.Err())
+	}
+}
+
+func TestDiskBPTreeErrors(t *testing.T) {
+	dir := t.TempDir()
+	// 文件格式错误
+	path := filepath.Join(dir, "corrupt.db")
+	os.WriteFile(path, []byte(strings.Repeat("garbage!", 100)), 0o644)
+	_, err := Open[int64, string](path, datastruct.OrderedComparator[int64](),
+		datastruct.IntegerCodec[int64]{}, datastruct.StringCodec{})
+	if !errors.Is(err, ErrCorruptFile) {
+		t.Errorf("期望 ErrCorruptFile, 实际 %v", err)
+	}
+}
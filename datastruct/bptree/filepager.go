@@ -0,0 +1,630 @@
+package bptree
+
+import (
+	"DataStruct/datastruct"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+)
+
+// pageID 页号，第 0 页为文件头，因此 0 也用来表示空指针
+type pageID = uint32
+
+const (
+	fileMagic      = "BPTREE\x00\x01"
+	fileHeaderSize = 36 // magic(8) + pageSize(4) + order(4) + root(4) + pageCount(4) + freeHead(4) + length(8)
+	pageHeaderSize = 11 // kind(1) + num(2) + prev(4) + next(4)
+
+	pageKindLeaf  = 1 // 叶子结点页
+	pageKindIndex = 2 // 索引结点页
+	pageKindFree  = 3 // 空闲页，next 指向下一个空闲页
+
+	minPageSize = 128
+)
+
+var (
+	// ErrPageOverflow 结点编码后超过页大小，需要减小阶数或增大页大小
+	ErrPageOverflow = errors.New("bptree: node does not fit in a page")
+	// ErrEntryTooLarge kv 编码后超过每个 kv 在页中可占用的空间（(页大小 - 页头) / 阶数），写入被拒绝
+	ErrEntryTooLarge = errors.New("bptree: entry does not fit in a page")
+	// ErrCorruptFile 文件格式错误
+	ErrCorruptFile = errors.New("bptree: corrupt file")
+)
+
+// filePager 基于文件的存储后端
+//
+// 文件由固定大小的页组成，第 0 页为文件头，记录页大小、阶数、根结点页号、
+// 页数、空闲链表头与 kv 数量。每个结点占用一页：叶子结点保存 kv 与前后叶子结点页号，
+// 索引结点保存每个孩子的页号与其最大 key。
+//
+// 结点对象由 nodes 统一管理，同一页在内存中只有一个 *BPNode，
+// 页缓存只回收结点内容（kvNodes/childNodes），保留 num、maxKey 等元数据，
+// 因此索引结点被淘汰后孩子结点仍可按 maxKey 路由。
+// 缓存淘汰只在每个公开操作结束时（release）进行，保证操作过程中引用的结点不会被回收。
+// 被淘汰的结点对象数量超过上限时，release 从 nodes 中删除既不是根结点、也不被已加载结点引用的结点（prune），
+// 之后再访问该页时由 ref 重新创建，因此 nodes 的大小与页缓存成正比而不是与访问过的页数成正比。
+// 被删除的结点只可能还被迭代器持有，它未被修改且与重新创建的结点对应同一页，迭代器在树被修改后本就失效。
+//
+// 每次插入删除在修改结点前先写入 WAL。被修改的结点在 checkpoint 之前不会写回数据文件（no-steal），
+// 因此数据文件始终是上一次 checkpoint 的状态，崩溃后在其基础上重放 WAL 即可恢复。
+// checkpoint 先将所有页镜像与提交记录写入 WAL，再写数据文件，最后清空 WAL，
+// 写数据文件的过程中崩溃时可以用 WAL 中的页镜像重做。
+type filePager[K, V any] struct {
+	file       *os.File
+	pageSize   int
+	order      int
+	cacheSize  int                      // 页缓存可保存的结点内容数
+	keyCodec   datastruct.Codec[K]      // key 编码
+	valueCodec datastruct.Codec[V]      // value 编码
+	nodes      map[pageID]*BPNode[K, V] // 页号 -> 结点
+	pruneAt    int                      // nodes 超过该数量时 prune
+	lru        *list.List               // 已加载内容的结点，最近使用的在前
+	pageCount  pageID                   // 文件中的页数（包括文件头）
+	freeList   []pageID                 // 空闲页
+	freeDirty  bool                     // 空闲链表是否需要写回
+	dirtyNodes int                      // 被修改且未写回的结点数
+	buf        []byte                   // 页读写缓冲区
+	wal        *wal                     // 预写日志
+	replaying  bool                     // 是否正在重放 WAL（不再写日志，也不自动 checkpoint）
+}
+
+// pageImage checkpoint 中要写入数据文件的一页
+type pageImage struct {
+	id   pageID
+	data []byte
+}
+
+var _ pager[int, int] = (*filePager[int, int])(nil)
+
+// fileHeader 文件头
+type fileHeader struct {
+	pageSize  uint32
+	order     uint32
+	root      pageID
+	pageCount pageID
+	freeHead  pageID
+	length    uint64
+}
+
+func (h *fileHeader) encode(buf []byte) {
+	copy(buf, fileMagic)
+	binary.BigEndian.PutUint32(buf[8:], h.pageSize)
+	binary.BigEndian.PutUint32(buf[12:], h.order)
+	binary.BigEndian.PutUint32(buf[16:], h.root)
+	binary.BigEndian.PutUint32(buf[20:], h.pageCount)
+	binary.BigEndian.PutUint32(buf[24:], h.freeHead)
+	binary.BigEndian.PutUint64(buf[28:], h.length)
+}
+
+func (h *fileHeader) decode(buf []byte) error {
+	if len(buf) < fileHeaderSize || string(buf[:8]) != fileMagic {
+		return ErrCorruptFile
+	}
+	h.pageSize = binary.BigEndian.Uint32(buf[8:])
+	h.order = binary.BigEndian.Uint32(buf[12:])
+	h.root = binary.BigEndian.Uint32(buf[16:])
+	h.pageCount = binary.BigEndian.Uint32(buf[20:])
+	h.freeHead = binary.BigEndian.Uint32(buf[24:])
+	h.length = binary.BigEndian.Uint64(buf[28:])
+	if h.pageSize < minPageSize || h.order < 3 || h.root == 0 || h.root >= h.pageCount {
+		return ErrCorruptFile
+	}
+	return nil
+}
+
+// readHeader 读取文件头与空闲链表
+func (p *filePager[K, V]) readHeader() (*fileHeader, error) {
+	buf := make([]byte, fileHeaderSize)
+	if _, err := p.file.ReadAt(buf, 0); err != nil {
+		return nil, fmt.Errorf("bptree: read header: %w", err)
+	}
+	h := &fileHeader{}
+	if err := h.decode(buf); err != nil {
+		return nil, err
+	}
+	p.pageSize = int(h.pageSize)
+	p.order = int(h.order)
+	p.pageCount = h.pageCount
+	p.buf = make([]byte, p.pageSize)
+
+	// 读取空闲链表
+	p.freeList = p.freeList[:0]
+	for id := h.freeHead; id != 0; {
+		if id >= p.pageCount || len(p.freeList) >= int(p.pageCount) {
+			return nil, ErrCorruptFile
+		}
+		if err := p.readPage(id); err != nil {
+			return nil, err
+		}
+		if p.buf[0] != pageKindFree {
+			return nil, ErrCorruptFile
+		}
+		p.freeList = append(p.freeList, id)
+		id = binary.BigEndian.Uint32(p.buf[7:])
+	}
+	return h, nil
+}
+
+// headerImages 生成文件头的页镜像，空闲链表修改过时一并生成空闲页的页镜像
+func (p *filePager[K, V]) headerImages(root pageID, length int) []pageImage {
+	var images []pageImage
+	if p.freeDirty {
+		for i, id := range p.freeList {
+			page := make([]byte, p.pageSize)
+			page[0] = pageKindFree
+			if i+1 < len(p.freeList) {
+				binary.BigEndian.PutUint32(page[7:], p.freeList[i+1])
+			}
+			images = append(images, pageImage{id: id, data: page})
+		}
+	}
+	h := &fileHeader{
+		pageSize:  uint32(p.pageSize),
+		order:     uint32(p.order),
+		root:      root,
+		pageCount: p.pageCount,
+		length:    uint64(length),
+	}
+	if len(p.freeList) > 0 {
+		h.freeHead = p.freeList[0]
+	}
+	page := make([]byte, p.pageSize)
+	h.encode(page)
+	return append(images, pageImage{id: 0, data: page})
+}
+
+func (p *filePager[K, V]) readPage(id pageID) error {
+	if _, err := p.file.ReadAt(p.buf, int64(id)*int64(p.pageSize)); err != nil {
+		return fmt.Errorf("bptree: read page %d: %w", id, err)
+	}
+	return nil
+}
+
+func (p *filePager[K, V]) writePage(id pageID, page []byte) error {
+	if _, err := p.file.WriteAt(page, int64(id)*int64(len(page))); err != nil {
+		return fmt.Errorf("bptree: write page %d: %w", id, err)
+	}
+	return nil
+}
+
+// ref 返回页号对应的结点对象，不存在时创建一个未加载内容的结点
+func (p *filePager[K, V]) ref(id pageID) *BPNode[K, V] {
+	if id == 0 {
+		return nil
+	}
+	node, ok := p.nodes[id]
+	if !ok {
+		node = &BPNode[K, V]{id: id}
+		p.nodes[id] = node
+	}
+	return node
+}
+
+func (p *filePager[K, V]) newNode(leaf bool) *BPNode[K, V] {
+	var id pageID
+	if n := len(p.freeList); n > 0 {
+		id = p.freeList[n-1]
+		p.freeList = p.freeList[:n-1]
+		p.freeDirty = true
+	} else {
+		id = p.pageCount
+		p.pageCount++
+	}
+	var node *BPNode[K, V]
+	if leaf {
+		node = newLeafNode[K, V](p.order)
+	} else {
+		node = newIndexNode[K, V](p.order)
+	}
+	node.id = id
+	node.loaded = true
+	node.elem = p.lru.PushFront(node)
+	p.dirty(node)
+	p.nodes[id] = node
+	return node
+}
+
+func (p *filePager[K, V]) load(node *BPNode[K, V]) {
+	if node.loaded {
+		p.lru.MoveToFront(node.elem)
+		return
+	}
+	if err := p.readPage(node.id); err != nil {
+		panic(storageError{err})
+	}
+	if err := p.decode(node, p.buf); err != nil {
+		panic(storageError{fmt.Errorf("bptree: decode page %d: %w", node.id, err)})
+	}
+	node.loaded = true
+	node.elem = p.lru.PushFront(node)
+}
+
+func (p *filePager[K, V]) dirty(node *BPNode[K, V]) {
+	if !node.dirty {
+		node.dirty = true
+		p.dirtyNodes++
+	}
+}
+
+func (p *filePager[K, V]) free(node *BPNode[K, V]) {
+	if node.loaded {
+		p.lru.Remove(node.elem)
+	}
+	if node.dirty {
+		p.dirtyNodes--
+	}
+	delete(p.nodes, node.id)
+	p.freeList = append(p.freeList, node.id)
+	p.freeDirty = true
+	*node = BPNode[K, V]{}
+}
+
+// log 校验并编码 kv 后追加到 WAL
+//
+// 叶子结点最多 order 个 kv、索引结点最多 order 个孩子，每个 kv 编码后不超过 (页大小 - 页头) / 阶数 时
+// 任何结点都能放入一页。超过时拒绝写入，否则该记录在每次 checkpoint 与重放时都会失败，文件再也无法打开。
+func (p *filePager[K, V]) log(typ byte, key K, value V) error {
+	if p.replaying {
+		return nil
+	}
+	key1, err := p.keyCodec.Append(nil, key)
+	if err != nil {
+		return err
+	}
+	payload := binary.AppendUvarint(make([]byte, 0, len(key1)+8), uint64(len(key1)))
+	payload = append(payload, key1...)
+	if typ == walInsert {
+		keyEnd := len(payload)
+		if payload, err = p.valueCodec.Append(payload, value); err != nil {
+			return err
+		}
+		valueSize := len(payload) - keyEnd
+		leafEntry := keyEnd + uvarintSize(valueSize) + valueSize
+		indexEntry := 4 + keyEnd
+		if limit := (p.pageSize - pageHeaderSize) / p.order; max(leafEntry, indexEntry) > limit {
+			return fmt.Errorf("%w: %d bytes, limit %d", ErrEntryTooLarge, max(leafEntry, indexEntry), limit)
+		}
+	}
+	if err := p.wal.append(typ, payload); err != nil {
+		panic(storageError{err})
+	}
+	return nil
+}
+
+// uvarintSize x 的 uvarint 编码长度
+func uvarintSize(x int) int {
+	return (bits.Len64(uint64(x)|1) + 6) / 7
+}
+
+// decodeLog 解码 log 写入的插入删除记录
+func (p *filePager[K, V]) decodeLog(r walRecord) (key K, value V, err error) {
+	n, size := binary.Uvarint(r.payload)
+	if size <= 0 || uint64(len(r.payload)-size) < n {
+		return key, value, ErrCorruptFile
+	}
+	if key, err = p.keyCodec.Decode(r.payload[size : size+int(n)]); err != nil {
+		return
+	}
+	if r.typ == walInsert {
+		value, err = p.valueCodec.Decode(r.payload[size+int(n):])
+	}
+	return
+}
+
+// release 淘汰最久未使用的未修改结点，被修改的结点过多时自动 checkpoint
+func (p *filePager[K, V]) release(root *BPNode[K, V], length int) error {
+	if p.dirtyNodes > p.cacheSize && !p.replaying {
+		if err := p.checkpoint(root, length); err != nil {
+			return err
+		}
+	}
+	for e := p.lru.Back(); e != nil && p.lru.Len() > p.cacheSize; {
+		prev := e.Prev()
+		if node := e.Value.(*BPNode[K, V]); !node.dirty {
+			p.evict(node)
+		}
+		e = prev
+	}
+	if len(p.nodes) > max(p.pruneAt, 2*p.cacheSize*(p.order+1)) {
+		p.prune(root)
+	}
+	return nil
+}
+
+// prune 从 nodes 中删除未加载内容、且不被根结点与已加载结点引用的结点
+//
+// 每次 prune 后 nodes 至少翻倍才会再次 prune，均摊到每个新建的结点对象上是 O(1)
+func (p *filePager[K, V]) prune(root *BPNode[K, V]) {
+	reachable := make(map[*BPNode[K, V]]bool, p.lru.Len()*(p.order+1)+1)
+	reachable[root] = true
+	for e := p.lru.Front(); e != nil; e = e.Next() {
+		node := e.Value.(*BPNode[K, V])
+		if node.isLeaf {
+			reachable[node.prev] = true
+			reachable[node.next] = true
+			continue
+		}
+		for _, child := range node.childNodes[:node.num] {
+			reachable[child] = true
+		}
+	}
+	for id, node := range p.nodes {
+		if !node.loaded && !reachable[node] {
+			delete(p.nodes, id)
+		}
+	}
+	p.pruneAt = 2 * len(p.nodes)
+}
+
+// evict 回收未修改结点的内容
+func (p *filePager[K, V]) evict(node *BPNode[K, V]) {
+	p.lru.Remove(node.elem)
+	node.elem = nil
+	node.loaded = false
+	node.kvNodes = nil
+	node.childNodes = nil
+	node.prev = nil
+	node.next = nil
+}
+
+func (p *filePager[K, V]) checkpoint(root *BPNode[K, V], length int) error {
+	images, nodes, err := p.logCheckpoint(root, length)
+	if err != nil {
+		return err
+	}
+
+	// 3. 写数据文件
+	if err := p.applyImages(images); err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		node.dirty = false
+	}
+	p.dirtyNodes = 0
+	p.freeDirty = false
+
+	// 4. 数据文件已是最新状态，清空 WAL
+	return p.wal.reset()
+}
+
+// logCheckpoint 生成所有修改过的页的镜像并写入 WAL，返回页镜像与对应的结点
+func (p *filePager[K, V]) logCheckpoint(root *BPNode[K, V], length int) ([]pageImage, []*BPNode[K, V], error) {
+	// 1. 生成所有修改过的页的镜像
+	var (
+		images []pageImage
+		nodes  []*BPNode[K, V]
+	)
+	for e := p.lru.Front(); e != nil; e = e.Next() {
+		node := e.Value.(*BPNode[K, V])
+		if !node.dirty {
+			continue
+		}
+		page, err := p.encode(node, make([]byte, 0, p.pageSize))
+		if err != nil {
+			return nil, nil, err
+		}
+		images = append(images, pageImage{id: node.id, data: page[:p.pageSize]})
+		nodes = append(nodes, node)
+	}
+	images = append(images, p.headerImages(root.id, length)...)
+
+	// 2. 页镜像与提交记录写入 WAL
+	for _, img := range images {
+		payload := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(img.data)), img.id)
+		if err := p.wal.write(walPage, append(payload, img.data...)); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := p.wal.write(walCommit, nil); err != nil {
+		return nil, nil, err
+	}
+	if err := p.wal.fsync(); err != nil {
+		return nil, nil, err
+	}
+	return images, nodes, nil
+}
+
+// applyImages 将页镜像写入数据文件并 fsync
+func (p *filePager[K, V]) applyImages(images []pageImage) error {
+	for _, img := range images {
+		if err := p.writePage(img.id, img.data); err != nil {
+			return err
+		}
+	}
+	if err := p.file.Sync(); err != nil {
+		return fmt.Errorf("bptree: sync: %w", err)
+	}
+	return nil
+}
+
+// recover 崩溃恢复：若 WAL 中有已提交的 checkpoint 则用其页镜像重做数据文件，
+// 返回之后需要重放的插入删除记录
+func (p *filePager[K, V]) recover() ([]walRecord, error) {
+	records, err := p.wal.records()
+	if err != nil {
+		return nil, err
+	}
+	commit := -1
+	for i, r := range records {
+		if r.typ == walCommit {
+			commit = i
+		}
+	}
+	if commit >= 0 {
+		start := commit
+		for start > 0 && records[start-1].typ == walPage {
+			start--
+		}
+		images := make([]pageImage, 0, commit-start)
+		for _, r := range records[start:commit] {
+			if len(r.payload) < 4+minPageSize {
+				return nil, ErrCorruptFile
+			}
+			images = append(images, pageImage{id: binary.BigEndian.Uint32(r.payload), data: r.payload[4:]})
+		}
+		if err := p.applyImages(images); err != nil {
+			return nil, err
+		}
+		records = records[commit+1:]
+	}
+
+	ops := records[:0]
+	for _, r := range records {
+		// 未提交的 checkpoint 的页镜像没有写入数据文件，直接忽略
+		if r.typ == walInsert || r.typ == walDelete {
+			ops = append(ops, r)
+		}
+	}
+	return ops, nil
+}
+
+func (p *filePager[K, V]) close() error {
+	err := p.wal.close()
+	if ferr := p.file.Close(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// encode 结点编码
+//
+//	页头: kind(1) num(2) prev(4) next(4)
+//	叶子结点: [uvarint(len(key)) key uvarint(len(value)) value] * num
+//	索引结点: [child(4) uvarint(len(maxKey)) maxKey] * num
+func (p *filePager[K, V]) encode(node *BPNode[K, V], buf []byte) ([]byte, error) {
+	var prev, next pageID
+	if node.prev != nil {
+		prev = node.prev.id
+	}
+	if node.next != nil {
+		next = node.next.id
+	}
+	kind := byte(pageKindIndex)
+	if node.isLeaf {
+		kind = pageKindLeaf
+	}
+	buf = append(buf, kind)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(node.num))
+	buf = binary.BigEndian.AppendUint32(buf, prev)
+	buf = binary.BigEndian.AppendUint32(buf, next)
+
+	var (
+		scratch []byte
+		err     error
+	)
+	for i := 0; i < node.num; i++ {
+		if node.isLeaf {
+			kvn := node.kvNodes[i]
+			if scratch, err = p.keyCodec.Append(scratch[:0], kvn.key); err != nil {
+				return nil, err
+			}
+			buf = binary.AppendUvarint(buf, uint64(len(scratch)))
+			buf = append(buf, scratch...)
+			if scratch, err = p.valueCodec.Append(scratch[:0], kvn.value); err != nil {
+				return nil, err
+			}
+			buf = binary.AppendUvarint(buf, uint64(len(scratch)))
+			buf = append(buf, scratch...)
+		} else {
+			child := node.childNodes[i]
+			buf = binary.BigEndian.AppendUint32(buf, child.id)
+			if scratch, err = p.keyCodec.Append(scratch[:0], child.maxKey); err != nil {
+				return nil, err
+			}
+			buf = binary.AppendUvarint(buf, uint64(len(scratch)))
+			buf = append(buf, scratch...)
+		}
+		if len(buf) > p.pageSize {
+			return nil, fmt.Errorf("%w: page %d", ErrPageOverflow, node.id)
+		}
+	}
+	return buf, nil
+}
+
+// decode 结点解码，索引结点的孩子结点若未加载则用页中记录的 maxKey 初始化
+func (p *filePager[K, V]) decode(node *BPNode[K, V], buf []byte) error {
+	kind := buf[0]
+	if kind != pageKindLeaf && kind != pageKindIndex {
+		return ErrCorruptFile
+	}
+	num := int(binary.BigEndian.Uint16(buf[1:]))
+	if num > p.order {
+		return ErrCorruptFile
+	}
+	node.isLeaf = kind == pageKindLeaf
+	node.num = num
+	if node.isLeaf {
+		node.kvNodes = make([]*KvNode[K, V], p.order+1)
+		node.prev = p.ref(binary.BigEndian.Uint32(buf[3:]))
+		node.next = p.ref(binary.BigEndian.Uint32(buf[7:]))
+	} else {
+		node.childNodes = make([]*BPNode[K, V], p.order+1)
+	}
+
+	pos := pageHeaderSize
+	field := func() ([]byte, error) {
+		n, size := binary.Uvarint(buf[pos:])
+		if size <= 0 || uint64(len(buf)-pos-size) < n {
+			return nil, ErrCorruptFile
+		}
+		pos += size
+		b := buf[pos : pos+int(n)]
+		pos += int(n)
+		return b, nil
+	}
+	for i := 0; i < num; i++ {
+		if node.isLeaf {
+			kb, err := field()
+			if err != nil {
+				return err
+			}
+			vb, err := field()
+			if err != nil {
+				return err
+			}
+			key, err := p.keyCodec.Decode(kb)
+			if err != nil {
+				return err
+			}
+			value, err := p.valueCodec.Decode(vb)
+			if err != nil {
+				return err
+			}
+			node.kvNodes[i] = newKvNode(key, value)
+		} else {
+			if pos+4 > len(buf) {
+				return ErrCorruptFile
+			}
+			id := binary.BigEndian.Uint32(buf[pos:])
+			pos += 4
+			if id == 0 || id >= p.pageCount {
+				return ErrCorruptFile
+			}
+			kb, err := field()
+			if err != nil {
+				return err
+			}
+			child := p.ref(id)
+			if !child.loaded {
+				if child.maxKey, err = p.keyCodec.Decode(kb); err != nil {
+					return err
+				}
+			}
+			node.childNodes[i] = child
+		}
+	}
+	if num > 0 {
+		if node.isLeaf {
+			node.maxKey = node.kvNodes[num-1].key
+		} else {
+			node.maxKey = node.childNodes[num-1].maxKey
+		}
+	} else {
+		var zero K
+		node.maxKey = zero
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package bptree
+
+// Iterator 沿叶子结点链表遍历的迭代器
+// 迭代过程中修改树（Insert/Delete）会使迭代器失效
+type Iterator[K, V any] struct {
+	t     *BPTree[K, V]
+	node  *BPNode[K, V] // 当前叶子结点
+	index int           // 当前 kv 在叶子结点中的下标
+}
+
+// Valid 迭代器是否指向一个有效的 kv
+func (it *Iterator[K, V]) Valid() bool {
+	return it.node != nil && it.index >= 0 && it.index < it.node.num
+}
+
+// Key 当前 kv 的 key
+func (it *Iterator[K, V]) Key() K {
+	return it.kv().key
+}
+
+// Value 当前 kv 的 value
+func (it *Iterator[K, V]) Value() V {
+	return it.kv().value
+}
+
+// kv 当前 kv，叶子结点可能已被页缓存淘汰，需要重新加载
+func (it *Iterator[K, V]) kv() *KvNode[K, V] {
+	if !it.t.tryLoad(it.node) {
+		return &KvNode[K, V]{}
+	}
+	return it.node.kvNodes[it.index]
+}
+
+// moveTo 移动到 node 的第 index 个 kv（index < 0 代表最后一个），加载失败时迭代器失效
+func (it *Iterator[K, V]) moveTo(node *BPNode[K, V], index int) {
+	it.node = node
+	if node == nil {
+		return
+	}
+	if !it.t.tryLoad(node) {
+		it.node = nil
+		return
+	}
+	if index < 0 {
+		index = node.num - 1
+	}
+	it.index = index
+	it.t.release()
+}
+
+// Next 后移一位，越过当前叶子结点时沿 next 指针进入下一个叶子结点
+func (it *Iterator[K, V]) Next() {
+	if it.node == nil {
+		return
+	}
+	it.index++
+	if it.index >= it.node.num && it.reload() {
+		it.moveTo(it.node.next, 0)
+	}
+}
+
+// Prev 前移一位，越过当前叶子结点时沿 prev 指针进入上一个叶子结点
+func (it *Iterator[K, V]) Prev() {
+	if it.node == nil {
+		return
+	}
+	it.index--
+	if it.index < 0 && it.reload() {
+		it.moveTo(it.node.prev, -1)
+	}
+}
+
+// reload 重新加载当前叶子结点，失败时迭代器失效
+//
+// 两次移动之间的其他操作（如遍历回调中的 Get、另一个迭代器）可能使当前叶子结点被页缓存淘汰，
+// 淘汰会清空 prev/next，因此沿链表移动前需要先重新加载
+func (it *Iterator[K, V]) reload() bool {
+	if !it.t.tryLoad(it.node) {
+		it.node = nil
+		return false
+	}
+	return true
+}
+
+// findLeaf 找到 key 所在（或应插入）的叶子结点
+func (t *BPTree[K, V]) findLeaf(key K) *BPNode[K, V] {
+	node := t.root
+	t.store.load(node)
+	for !node.isLeaf {
+		node = node.childNodes[node.childIndex(key, t.cmp)]
+		t.store.load(node)
+	}
+	return node
+}
+
+// Get 查找指定 key 的 value
+func (t *BPTree[K, V]) Get(key K) (value V, ok bool) {
+	if t.err != nil {
+		return
+	}
+	defer t.catch()
+	leaf := t.findLeaf(key)
+	if i, found := leaf.search(key, t.cmp); found {
+		value, ok = leaf.kvNodes[i].value, true
+	}
+	t.release()
+	return
+}
+
+// Seek 返回指向第一个 >= key 的 kv 的迭代器
+func (t *BPTree[K, V]) Seek(key K) (it *Iterator[K, V]) {
+	it = &Iterator[K, V]{t: t}
+	if t.err != nil {
+		return
+	}
+	defer t.catch()
+	leaf := t.findLeaf(key)
+	i, _ := leaf.search(key, t.cmp)
+	if i >= leaf.num { // key 大于该叶子结点所有 key，移到下一个叶子结点
+		it.moveTo(leaf.next, 0)
+	} else {
+		it.node, it.index = leaf, i
+	}
+	t.release()
+	return
+}
+
+// First 返回指向最小 kv 的迭代器
+func (t *BPTree[K, V]) First() *Iterator[K, V] {
+	return t.edge(func(node *BPNode[K, V]) int { return 0 })
+}
+
+// Last 返回指向最大 kv 的迭代器
+func (t *BPTree[K, V]) Last() *Iterator[K, V] {
+	return t.edge(func(node *BPNode[K, V]) int { return node.num - 1 })
+}
+
+// edge 沿 pick 选出的孩子结点走到叶子结点，返回指向该叶子结点第 pick 个 kv 的迭代器
+func (t *BPTree[K, V]) edge(pick func(node *BPNode[K, V]) int) (it *Iterator[K, V]) {
+	it = &Iterator[K, V]{t: t}
+	if t.err != nil {
+		return
+	}
+	defer t.catch()
+	node := t.root
+	t.store.load(node)
+	for !node.isLeaf {
+		node = node.childNodes[pick(node)]
+		t.store.load(node)
+	}
+	it.node, it.index = node, pick(node)
+	t.release()
+	return
+}
+
+// Ascend 按 key 升序遍历，fn 返回 false 时停止
+func (t *BPTree[K, V]) Ascend(fn func(key K, value V) bool) {
+	for it := t.First(); it.Valid(); it.Next() {
+		if !fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
+
+// Descend 按 key 降序遍历，fn 返回 false 时停止
+func (t *BPTree[K, V]) Descend(fn func(key K, value V) bool) {
+	for it := t.Last(); it.Valid(); it.Prev() {
+		if !fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
+
+// Range 按 key 升序遍历 [lo, hi) 区间，fn 返回 false 时停止
+func (t *BPTree[K, V]) Range(lo, hi K, fn func(key K, value V) bool) {
+	for it := t.Seek(lo); it.Valid() && t.cmp(it.Key(), hi) < 0; it.Next() {
+		if !fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
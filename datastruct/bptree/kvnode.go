@@ -1,14 +1,14 @@
 package bptree
 
 // KvNode BPNode中的成员
-type KvNode struct {
-	key   int64
-	value interface{}
+type KvNode[K, V any] struct {
+	key   K
+	value V
 }
 
 // newKvNode 新建KvNode成员
-func newKvNode(key int64, value interface{}) *KvNode {
-	return &KvNode{
+func newKvNode[K, V any](key K, value V) *KvNode[K, V] {
+	return &KvNode[K, V]{
 		key:   key,
 		value: value,
 	}
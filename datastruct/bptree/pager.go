@@ -0,0 +1,65 @@
+package bptree
+
+// pager 结点的存储后端
+//
+// BPTree 的所有结点都通过 pager 分配与释放；修改前调用 log 记录操作，访问结点内容前调用 load，
+// 修改结点后调用 dirty，每个公开操作结束时调用 release 以便后端回收缓存。
+type pager[K, V any] interface {
+	// log 在修改结点前记录一次插入（walInsert）或删除（walDelete）操作
+	//
+	// kv 无法编码或放不进一页时返回 error，树不做修改；写日志失败时 panic(storageError)
+	log(typ byte, key K, value V) error
+	// newNode 分配新结点
+	newNode(leaf bool) *BPNode[K, V]
+	// load 确保结点内容在内存中，读取失败时 panic(storageError)
+	load(node *BPNode[K, V])
+	// dirty 标记结点内容已修改
+	dirty(node *BPNode[K, V])
+	// free 释放结点
+	free(node *BPNode[K, V])
+	// release 一次操作结束，按需淘汰缓存
+	release(root *BPNode[K, V], length int) error
+	// checkpoint 将修改过的结点、根结点与 kv 数量写入存储
+	checkpoint(root *BPNode[K, V], length int) error
+	// close 关闭存储
+	close() error
+}
+
+// storageError 存储后端读写失败，由 load 通过 panic 抛出，在公开方法中记录为 t.err
+type storageError struct {
+	err error
+}
+
+// memPager 内存存储后端，结点始终在内存中
+type memPager[K, V any] struct {
+	order int
+}
+
+var _ pager[int, int] = (*memPager[int, int])(nil)
+
+func (p *memPager[K, V]) log(typ byte, key K, value V) error { return nil }
+
+func (p *memPager[K, V]) newNode(leaf bool) *BPNode[K, V] {
+	if leaf {
+		return newLeafNode[K, V](p.order)
+	}
+	return newIndexNode[K, V](p.order)
+}
+
+func (p *memPager[K, V]) load(node *BPNode[K, V]) {}
+
+func (p *memPager[K, V]) dirty(node *BPNode[K, V]) {}
+
+func (p *memPager[K, V]) free(node *BPNode[K, V]) {
+	if node.isLeaf {
+		node.freeLeafNode()
+	} else {
+		node.freeIndexNode()
+	}
+}
+
+func (p *memPager[K, V]) release(root *BPNode[K, V], length int) error { return nil }
+
+func (p *memPager[K, V]) checkpoint(root *BPNode[K, V], length int) error { return nil }
+
+func (p *memPager[K, V]) close() error { return nil }
@@ -0,0 +1,100 @@
+package bptree
+
+import (
+	"DataStruct/datastruct"
+	"fmt"
+)
+
+// Verify 校验 b+ 树的不变式：非根结点大小在 [minNum, order] 之间、根索引结点至少有 2 个孩子、
+// 叶子结点内 key 严格有序、索引结点记录的 maxKey 与子树一致且孩子之间严格有序、叶子结点在同一层、
+// 叶子链表按顺序串起全部叶子结点、kv 数量等于 Len，返回发现的第一处错误（*datastruct.InvariantError）
+//
+// 磁盘存储的树会读取全部结点，读取失败时返回 Err
+func (t *BPTree[K, V]) Verify() (err error) {
+	if t.err != nil {
+		return t.err
+	}
+	v := &verifier[K, V]{t: t, leafDepth: -1}
+	func() {
+		defer t.catch()
+		if err = v.verify(t.root, "root", 0, nil); err != nil {
+			return
+		}
+		if v.last != nil && v.last.next != nil {
+			err = datastruct.NewInvariantError(v.lastPath, "last leaf has next")
+		} else if v.count != t.length {
+			err = datastruct.NewInvariantError("root", "%d keys, length %d", v.count, t.length)
+		}
+	}()
+	t.release()
+	if err == nil {
+		err = t.err
+	}
+	return err
+}
+
+// verifier 校验过程中的状态
+type verifier[K, V any] struct {
+	t         *BPTree[K, V]
+	leafDepth int           // 第一个叶子结点的深度
+	count     int           // 已校验的 kv 数量
+	last      *BPNode[K, V] // 上一个叶子结点
+	lastPath  string        // 上一个叶子结点的路径
+}
+
+// verify 校验子树，子树中所有 key 都必须大于 lo（nil 表示无边界）
+func (v *verifier[K, V]) verify(node *BPNode[K, V], path string, depth int, lo *K) error {
+	t := v.t
+	t.store.load(node)
+	switch {
+	case node != t.root && (node.num < t.minNum || node.num > t.order):
+		return datastruct.NewInvariantError(path, "size %d, expected [%d, %d]", node.num, t.minNum, t.order)
+	case node == t.root && !node.isLeaf && node.num < 2:
+		return datastruct.NewInvariantError(path, "root index has %d children", node.num)
+	case node.num > t.order:
+		return datastruct.NewInvariantError(path, "size %d > order %d", node.num, t.order)
+	}
+
+	if node.isLeaf {
+		for i := 0; i < node.num; i++ {
+			key := node.kvNodes[i].key
+			if (i == 0 && lo != nil && t.cmp(key, *lo) <= 0) || (i > 0 && t.cmp(node.kvNodes[i-1].key, key) >= 0) {
+				return datastruct.NewInvariantError(path, "key %v at %d out of order", key, i)
+			}
+		}
+		if node.num > 0 && t.cmp(node.maxKey, node.kvNodes[node.num-1].key) != 0 {
+			return datastruct.NewInvariantError(path, "maxKey %v, expected %v", node.maxKey, node.kvNodes[node.num-1].key)
+		}
+		if v.leafDepth == -1 {
+			v.leafDepth = depth
+		} else if v.leafDepth != depth {
+			return datastruct.NewInvariantError(path, "leaf at depth %d, expected %d", depth, v.leafDepth)
+		}
+		// 叶子链表应与中序遍历的顺序一致
+		if node.prev != v.last {
+			return datastruct.NewInvariantError(path, "leaf prev is not the previous leaf")
+		}
+		if v.last != nil && v.last.next != node {
+			return datastruct.NewInvariantError(v.lastPath, "leaf next is not the following leaf")
+		}
+		v.last, v.lastPath = node, path
+		v.count += node.num
+		return nil
+	}
+
+	for i := 0; i < node.num; i++ {
+		child := node.childNodes[i]
+		if child == nil {
+			return datastruct.NewInvariantError(path, "nil child at %d", i)
+		}
+		if err := v.verify(child, fmt.Sprintf("%s/%d", path, i), depth+1, lo); err != nil {
+			return err
+		}
+		// 之后的孩子中的 key 都必须大于当前孩子的 maxKey
+		lo = &child.maxKey
+	}
+	if t.cmp(node.maxKey, node.childNodes[node.num-1].maxKey) != 0 {
+		return datastruct.NewInvariantError(path, "maxKey %v, expected %v", node.maxKey, node.childNodes[node.num-1].maxKey)
+	}
+	return nil
+}
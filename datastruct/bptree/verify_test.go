@@ -0,0 +1,80 @@
+package bptree
+
+import (
+	"DataStruct/datastruct"
+	"errors"
+	"math/rand"
+	"path/filepath"
+	"testing"
+)
+
+func TestBPTreeVerify(t *testing.T) {
+	build := func() *BPTree[int, int] {
+		tree := NewBPTree[int, int](4, datastruct.OrderedComparator[int]())
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 40; i++ {
+			tree.Insert(r.Intn(1000), i)
+		}
+		for i := 0; i < 10; i++ {
+			tree.Delete(r.Intn(1000))
+		}
+		return tree
+	}
+	if err := NewBPTree[int, int](4, datastruct.OrderedComparator[int]()).Verify(); err != nil {
+		t.Fatalf("空树校验失败: %v", err)
+	}
+	if err := build().Verify(); err != nil {
+		t.Fatalf("随机插入删除后校验失败: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		corrupt func(tree *BPTree[int, int])
+		path    string
+	}{
+		{"kv 数量错误", func(tree *BPTree[int, int]) { tree.length-- }, "root"},
+		{"叶子结点无序", func(tree *BPTree[int, int]) {
+			leaf := tree.root.childNodes[0].childNodes[1]
+			leaf.kvNodes[0], leaf.kvNodes[1] = leaf.kvNodes[1], leaf.kvNodes[0]
+		}, "root/0/1"},
+		{"maxKey 错误", func(tree *BPTree[int, int]) { tree.root.childNodes[1].maxKey++ }, "root/1"},
+		{"叶子链表断开", func(tree *BPTree[int, int]) {
+			leaf := tree.root.childNodes[1].childNodes[0]
+			leaf.prev = nil
+		}, "root/1/0"},
+		{"结点过小", func(tree *BPTree[int, int]) {
+			leaf := tree.root.childNodes[1].childNodes[0]
+			leaf.num = 1
+			leaf.maxKey = leaf.kvNodes[0].key
+		}, "root/1/0"},
+	}
+	for _, c := range cases {
+		tree := build()
+		if tree.root.isLeaf || tree.root.childNodes[0].isLeaf || !tree.root.childNodes[0].childNodes[0].isLeaf {
+			t.Fatal("测试需要 3 层的树")
+		}
+		c.corrupt(tree)
+		err := tree.Verify()
+		var ie *datastruct.InvariantError
+		if !errors.Is(err, datastruct.ErrInvariant) || !errors.As(err, &ie) || ie.Path() != c.path {
+			t.Errorf("%s: 期望 %s 处的错误, 实际 %v", c.name, c.path, err)
+		}
+	}
+}
+
+func TestDiskBPTreeVerify(t *testing.T) {
+	tree := openTestTree(t, filepath.Join(t.TempDir(), "tree.db"), WithOrder(5), WithPageSize(256), WithCacheSize(2))
+	for i := int64(0); i < 500; i++ {
+		tree.Insert(i*7%500, "v")
+	}
+	if err := tree.Checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tree.Verify(); err != nil {
+		t.Fatalf("磁盘存储校验失败: %v", err)
+	}
+	tree.Close()
+	if err := tree.Verify(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("关闭后 Verify 应返回 ErrClosed, 实际 %v", err)
+	}
+}
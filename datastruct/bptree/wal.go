@@ -0,0 +1,120 @@
+package bptree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+)
+
+// WAL 记录类型
+const (
+	walInsert = 1 // 插入: uvarint(len(key)) key value
+	walDelete = 2 // 删除: key
+	walPage   = 3 // checkpoint 中的页镜像: pageID(4) page
+	walCommit = 4 // checkpoint 的页镜像已全部写入 WAL
+)
+
+const walRecordHeaderSize = 9 // crc(4) + len(4) + type(1)
+
+// walRecord WAL 中的一条记录
+type walRecord struct {
+	typ     byte
+	payload []byte
+}
+
+// wal 预写日志
+//
+// 每条记录的格式为 crc(4) len(4) type(1) payload(len)，crc 覆盖 len、type 与 payload。
+// 读取时遇到长度不足或校验失败的记录即认为日志在此处被截断（崩溃时的撕裂写），之后的内容全部丢弃。
+type wal struct {
+	file *os.File
+	sync bool   // 每条记录写入后是否 fsync
+	size int64  // 日志有效长度
+	buf  []byte // 记录编码缓冲区
+}
+
+// openWAL 打开预写日志文件，不存在时创建
+func openWAL(path string, sync bool) (*wal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &wal{file: file, sync: sync}, nil
+}
+
+// append 追加一条记录，按配置 fsync
+func (w *wal) append(typ byte, payload []byte) error {
+	if err := w.write(typ, payload); err != nil {
+		return err
+	}
+	if w.sync {
+		return w.fsync()
+	}
+	return nil
+}
+
+// write 追加一条记录，不 fsync
+func (w *wal) write(typ byte, payload []byte) error {
+	w.buf = w.buf[:0]
+	w.buf = binary.BigEndian.AppendUint32(w.buf, 0)
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(len(payload)))
+	w.buf = append(w.buf, typ)
+	w.buf = append(w.buf, payload...)
+	binary.BigEndian.PutUint32(w.buf, crc32.ChecksumIEEE(w.buf[4:]))
+	if _, err := w.file.WriteAt(w.buf, w.size); err != nil {
+		return fmt.Errorf("bptree: write wal: %w", err)
+	}
+	w.size += int64(len(w.buf))
+	return nil
+}
+
+// records 读取日志中完整且校验通过的记录，并将有效长度定位到最后一条有效记录之后
+func (w *wal) records() ([]walRecord, error) {
+	data, err := io.ReadAll(io.NewSectionReader(w.file, 0, 1<<62))
+	if err != nil {
+		return nil, fmt.Errorf("bptree: read wal: %w", err)
+	}
+	var (
+		records []walRecord
+		pos     int
+	)
+	for len(data)-pos >= walRecordHeaderSize {
+		n := int(binary.BigEndian.Uint32(data[pos+4:]))
+		end := pos + walRecordHeaderSize + n
+		if end > len(data) {
+			break
+		}
+		if crc32.ChecksumIEEE(data[pos+4:end]) != binary.BigEndian.Uint32(data[pos:]) {
+			break
+		}
+		records = append(records, walRecord{
+			typ:     data[pos+8],
+			payload: data[pos+walRecordHeaderSize : end],
+		})
+		pos = end
+	}
+	w.size = int64(pos)
+	return records, nil
+}
+
+func (w *wal) fsync() error {
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("bptree: sync wal: %w", err)
+	}
+	return nil
+}
+
+// reset 清空日志
+func (w *wal) reset() error {
+	if err := w.file.Truncate(0); err != nil {
+		return fmt.Errorf("bptree: truncate wal: %w", err)
+	}
+	w.size = 0
+	return w.fsync()
+}
+
+func (w *wal) close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,163 @@
+package bptree
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// walOp 测试中执行的一次操作
+type walOp struct {
+	insert bool
+	key    int64
+	value  string
+}
+
+func applyOps(ref map[int64]string, ops []walOp) map[int64]string {
+	res := make(map[int64]string, len(ref))
+	for k, v := range ref {
+		res[k] = v
+	}
+	for _, op := range ops {
+		if op.insert {
+			res[op.key] = op.value
+		} else {
+			delete(res, op.key)
+		}
+	}
+	return res
+}
+
+// crashCopy 模拟崩溃：复制当前的数据文件，并将 WAL 截断到 walSize 后复制到新目录
+func crashCopy(t *testing.T, path string, wal []byte, walSize int) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(t.TempDir(), "crash.db")
+	if err := os.WriteFile(dst, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst+".wal", wal[:walSize], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dst
+}
+
+func TestWALRecoverTornWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	// 页缓存足够大，保证测试期间不会自动 checkpoint
+	tree := openTestTree(t, path, WithOrder(6), WithPageSize(256), WithCacheSize(1<<20), WithSyncWrites(false))
+	r := rand.New(rand.NewSource(3))
+	base := make(map[int64]string)
+	for i := int64(0); i < 300; i++ {
+		tree.Insert(i, fmt.Sprint("base", i))
+		base[i] = fmt.Sprint("base", i)
+	}
+	if err := tree.Checkpoint(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 记录每次操作后 WAL 的长度
+	p := tree.store.(*filePager[int64, string])
+	var (
+		ops     []walOp
+		offsets = []int64{p.wal.size}
+	)
+	for i := 0; i < 400; i++ {
+		op := walOp{insert: r.Intn(3) != 0, key: r.Int63n(600), value: fmt.Sprint("v", i)}
+		if op.insert {
+			tree.Insert(op.key, op.value)
+		} else {
+			tree.Delete(op.key)
+		}
+		ops = append(ops, op)
+		offsets = append(offsets, p.wal.size)
+	}
+	wal, err := os.ReadFile(path + ".wal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(wal)) != offsets[len(offsets)-1] {
+		t.Fatalf("WAL 长度 %d, 期望 %d", len(wal), offsets[len(offsets)-1])
+	}
+
+	// 在任意位置截断 WAL，恢复后应等于完整写入的操作前缀
+	cuts := []int{0, 1, len(wal) - 1, len(wal)}
+	for i := 0; i < 30; i++ {
+		cuts = append(cuts, r.Intn(len(wal)+1))
+	}
+	for _, cut := range cuts {
+		n := 0
+		for n+1 < len(offsets) && offsets[n+1] <= int64(cut) {
+			n++
+		}
+		dst := crashCopy(t, path, wal, cut)
+		recovered := openTestTree(t, dst)
+		checkTree(t, recovered, applyOps(base, ops[:n]))
+		if err := recovered.Close(); err != nil {
+			t.Fatal(err)
+		}
+		// 恢复后 WAL 被清空，再次打开结果不变
+		if info, _ := os.Stat(dst + ".wal"); info.Size() != 0 {
+			t.Fatalf("恢复后 WAL 长度 %d, 期望 0", info.Size())
+		}
+		recovered = openTestTree(t, dst)
+		checkTree(t, recovered, applyOps(base, ops[:n]))
+		recovered.Close()
+	}
+
+	// 中间某条记录损坏时，之后的记录全部丢弃
+	corrupt := append([]byte(nil), wal...)
+	corrupt[offsets[100]+walRecordHeaderSize] ^= 0xff
+	dst := crashCopy(t, path, corrupt, len(corrupt))
+	recovered := openTestTree(t, dst)
+	checkTree(t, recovered, applyOps(base, ops[:100]))
+	recovered.Close()
+	tree.Close()
+}
+
+func TestWALRecoverCheckpointCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tree.db")
+	tree := openTestTree(t, path, WithOrder(4), WithPageSize(256), WithCacheSize(1<<20), WithSyncWrites(false))
+	ref := make(map[int64]string)
+	for i := int64(0); i < 500; i++ {
+		tree.Insert(i*7%500, fmt.Sprint(i))
+		ref[i*7%500] = fmt.Sprint(i)
+	}
+	for i := int64(0); i < 500; i += 3 {
+		tree.Delete(i)
+		delete(ref, i)
+	}
+
+	// 页镜像已写入 WAL，写数据文件前崩溃
+	p := tree.store.(*filePager[int64, string])
+	images, _, err := p.logCheckpoint(tree.root, tree.length)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal, _ := os.ReadFile(path + ".wal")
+	recovered := openTestTree(t, crashCopy(t, path, wal, len(wal)))
+	checkTree(t, recovered, ref)
+	recovered.Close()
+
+	// 写数据文件到一半时崩溃
+	dst := crashCopy(t, path, wal, len(wal))
+	f, _ := os.OpenFile(dst, os.O_RDWR, 0o644)
+	for _, img := range images[:len(images)/2] {
+		f.WriteAt(img.data, int64(img.id)*int64(len(img.data)))
+	}
+	f.Close()
+	recovered = openTestTree(t, dst)
+	checkTree(t, recovered, ref)
+	recovered.Close()
+
+	// 提交记录写入前崩溃，页镜像被忽略，通过重放操作恢复
+	recovered = openTestTree(t, crashCopy(t, path, wal, len(wal)-walRecordHeaderSize))
+	checkTree(t, recovered, ref)
+	recovered.Close()
+	tree.Close()
+}
@@ -1,13 +1,19 @@
 package btree
 
+import "sort"
+
+// copyOnWrite 写时复制的上下文，结点只有在 cow 与树的 cow 相同时才能被该树直接修改
+type copyOnWrite struct {
+	_ byte // 非零大小，保证每次 new 得到不同的地址
+}
+
 // BNode B树的结点
 type BNode struct {
-	isleaf     bool      // 是否为叶子结点
-	num        int       // 当前结点 key 的个数
-	pindex     int       // 在父结点中的索引 bNodes[i]，-1代表根结点没有父结点
-	kvNodes    []*KvNode // KvNode 数组
-	childNodes []*BNode  // 孩子结点
-	parent     *BNode    // 父结点 BNode
+	isleaf     bool         // 是否为叶子结点
+	num        int          // 当前结点 key 的个数
+	kvNodes    []*KvNode    // KvNode 数组
+	childNodes []*BNode     // 孩子结点
+	cow        *copyOnWrite // 创建该结点的树的写时复制上下文
 }
 
 // newBNode 新建 BNode 结点
@@ -15,18 +21,13 @@ func newBNode(order int) *BNode {
 	return &BNode{
 		isleaf:     true,
 		num:        0,
-		pindex:     -1,
 		kvNodes:    make([]*KvNode, order),
 		childNodes: make([]*BNode, 0),
-		parent:     nil,
 	}
 }
 
 // freeBNode 释放 BNode 结点
 func (bnode *BNode) freeBNode() {
-	if bnode.parent != nil {
-		bnode.parent = nil
-	}
 	if bnode.kvNodes != nil {
 		bnode.kvNodes = nil
 	}
@@ -36,6 +37,44 @@ func (bnode *BNode) freeBNode() {
 	bnode = nil
 }
 
+// clone 复制结点，新结点属于 cow；KvNode 与孩子结点仍然共享
+func (bnode *BNode) clone(cow *copyOnWrite) *BNode {
+	node := &BNode{
+		isleaf:     bnode.isleaf,
+		num:        bnode.num,
+		kvNodes:    make([]*KvNode, len(bnode.kvNodes)),
+		childNodes: make([]*BNode, len(bnode.childNodes), cap(bnode.childNodes)),
+		cow:        cow,
+	}
+	copy(node.kvNodes, bnode.kvNodes)
+	copy(node.childNodes, bnode.childNodes)
+	return node
+}
+
+// search 二分查找第一个 >= key 的位置，并返回是否相等
+func (bnode *BNode) search(key int64) (int, bool) {
+	i := sort.Search(bnode.num, func(i int) bool {
+		return bnode.kvNodes[i].key >= key
+	})
+	return i, i < bnode.num && bnode.kvNodes[i].key == key
+}
+
+// insertKvn 将 kvn 插入到 kvNodes[] 指定位置
+func (bnode *BNode) insertKvn(kvn *KvNode, i int) {
+	copy(bnode.kvNodes[i+1:bnode.num+1], bnode.kvNodes[i:bnode.num])
+	bnode.kvNodes[i] = kvn
+	bnode.num++
+}
+
+// deleteKvn 删除 kvNodes[] 指定位置，返回被删除的 kvn
+func (bnode *BNode) deleteKvn(i int) *KvNode {
+	kvn := bnode.kvNodes[i]
+	copy(bnode.kvNodes[i:], bnode.kvNodes[i+1:bnode.num])
+	bnode.num--
+	bnode.kvNodes[bnode.num] = nil
+	return kvn
+}
+
 // insertSpPos 将 node 插入到 childNodes[] 指定位置
 func (bnode *BNode) insertPosNode(node *BNode, i int) {
 	bnode.childNodes = append(bnode.childNodes, nil)
@@ -48,23 +87,23 @@ func (bnode *BNode) deletePosNode(i int) {
 	bnode.childNodes = append(bnode.childNodes[:i], bnode.childNodes[i+1:]...)
 }
 
-// getLeftSib 获得左兄弟
-func (bnode *BNode) getLeftSib() *BNode {
-	if bnode.parent == nil || bnode.pindex <= 0 {
-		return nil
-	}
-	return bnode.parent.childNodes[bnode.pindex-1]
-}
-
-// getLeftSib 获得右兄弟
-func (bnode *BNode) getRightSib() *BNode {
-	if bnode.parent == nil || bnode.pindex >= bnode.parent.num {
-		return nil
+// split 以 mid 为中心分裂结点，bnode 保留左半部分，返回提升的 kvn 与右半部分
+func (bnode *BNode) split(mid int, right *BNode) *KvNode {
+	upKvNode := bnode.kvNodes[mid]
+	right.isleaf = bnode.isleaf
+	right.num = bnode.num - mid - 1
+	copy(right.kvNodes, bnode.kvNodes[mid+1:bnode.num])
+	clear(bnode.kvNodes[mid:bnode.num])
+	if !bnode.isleaf {
+		right.childNodes = append(right.childNodes, bnode.childNodes[mid+1:]...)
+		clear(bnode.childNodes[mid+1:])
+		bnode.childNodes = bnode.childNodes[:mid+1]
 	}
-	return bnode.parent.childNodes[bnode.pindex+1]
+	bnode.num = mid
+	return upKvNode
 }
 
-// mergeNode 合并，node被释放
+// mergeNode 合并，node 的内容追加到 bnode 之后
 // bnode.kNodes[] + kvn + node.kNodes[]
 func (bnode *BNode) mergeNode(kvn *KvNode, node *BNode) {
 	// 添加kvn
@@ -73,55 +112,36 @@ func (bnode *BNode) mergeNode(kvn *KvNode, node *BNode) {
 	// 追加childNodes
 	if len(node.childNodes) > 0 {
 		bnode.childNodes = append(bnode.childNodes, node.childNodes...)
-		for _, n := range node.childNodes {
-			n.pindex += bnode.num + 1
-			n.parent = bnode
-		}
 	}
 	bnode.num += node.num + 1
-	node.freeBNode()
 }
 
-// moveToRight 右移调整
-func (bnode *BNode) moveToRight(parent, rightSib *BNode) {
+// moveToRight 右移调整，bnode 为 parent.childNodes[i]，rightSib 为 parent.childNodes[i+1]
+func (bnode *BNode) moveToRight(parent, rightSib *BNode, i int) {
 	var (
-		upKvNode   = bnode.kvNodes[bnode.num-1]   // 上移kvn
-		donwKvNode = parent.kvNodes[bnode.pindex] // 下移kvn
+		upKvNode   = bnode.deleteKvn(bnode.num - 1) // 上移kvn
+		donwKvNode = parent.kvNodes[i]               // 下移kvn
 	)
-	parent.kvNodes[bnode.pindex] = upKvNode
-	copy(rightSib.kvNodes[1:], rightSib.kvNodes[0:])
-	rightSib.kvNodes[0] = donwKvNode
+	parent.kvNodes[i] = upKvNode
+	rightSib.insertKvn(donwKvNode, 0)
 	if len(bnode.childNodes) > 0 { // 添加新的子结点
-		for _, n := range rightSib.childNodes {
-			n.pindex += 1
-		}
-		rightSibNewChild := bnode.childNodes[bnode.num]
-		rightSibNewChild.pindex = 0
-		rightSibNewChild.parent = rightSib
-		bnode.deletePosNode(bnode.num)
-		rightSib.insertPosNode(rightSibNewChild, 0)
+		last := len(bnode.childNodes) - 1
+		rightSib.insertPosNode(bnode.childNodes[last], 0)
+		bnode.childNodes[last] = nil
+		bnode.childNodes = bnode.childNodes[:last]
 	}
-	rightSib.num++
-	bnode.num--
 }
 
-// moveToRight 左移调整
-func (bnode *BNode) moveToLeft(parent, leftSib *BNode) {
+// moveToLeft 左移调整，bnode 为 parent.childNodes[i]，leftSib 为 parent.childNodes[i-1]
+func (bnode *BNode) moveToLeft(parent, leftSib *BNode, i int) {
 	var (
-		upKvNode   = bnode.kvNodes[0]               // 上移kvn
-		donwKvNode = parent.kvNodes[bnode.pindex-1] // 下移kvn
+		upKvNode   = bnode.deleteKvn(0)   // 上移kvn
+		donwKvNode = parent.kvNodes[i-1] // 下移kvn
 	)
-	parent.kvNodes[bnode.pindex-1] = upKvNode
-	leftSib.kvNodes[leftSib.num] = donwKvNode
-	copy(bnode.kvNodes[0:], bnode.kvNodes[1:])
+	parent.kvNodes[i-1] = upKvNode
+	leftSib.insertKvn(donwKvNode, leftSib.num)
 	if len(bnode.childNodes) > 0 { // 添加新的子结点
-		leftSibNewChild := bnode.childNodes[0]
-		leftSibNewChild.pindex = leftSib.num + 1
-		leftSibNewChild.parent = leftSib
+		leftSib.childNodes = append(leftSib.childNodes, bnode.childNodes[0])
 		bnode.deletePosNode(0)
-		leftSib.insertPosNode(leftSibNewChild, leftSib.num+1)
 	}
-	leftSib.num++
-	bnode.num--
 }
-
@@ -7,258 +7,229 @@ import (
 )
 
 type BTree struct {
-	root   *BNode // 根结点
-	order  int    // 阶数
-	minNum int    // 结点最少存在 key 的个数(除root外)
+	root     *BNode       // 根结点
+	order    int          // 阶数
+	minNum   int          // 结点最少存在 key 的个数(除root外)
+	length   int          // kv 的数量
+	cow      *copyOnWrite // 写时复制上下文，只有属于该上下文的结点可以直接修改
+	readonly bool         // 是否为只读快照
 }
 
+var _ datastruct.OrderedMap[int64, interface{}] = (*BTree)(nil)
+
 // NewBTree 创建 B 树
 func NewBTree(order int) *BTree {
 	return &BTree{
 		root:   nil,
 		order:  order,
 		minNum: int(math.Ceil(float64(order)/2) - 1),
+		cow:    new(copyOnWrite),
+	}
+}
+
+// Clone 复制 B 树，时间复杂度 O(1)
+//
+// 复制后两棵树共享所有结点，任意一方修改时才按路径复制被修改的结点（写时复制），互不影响。
+// Clone 本身会修改原树，需要与原树的写操作串行执行
+func (btree *BTree) Clone() *BTree {
+	clone := *btree
+	clone.readonly = false
+	// 双方都换成新的上下文，之后共享的结点对两棵树都不可直接修改
+	btree.cow = new(copyOnWrite)
+	clone.cow = new(copyOnWrite)
+	return &clone
+}
+
+// Snapshot 返回当前时刻的只读快照
+//
+// 快照与原树共享未修改的结点，原树之后的写操作不会影响快照，快照可以与原树的写操作并发读取。
+// 对快照调用 Insert、Delete 会 panic
+func (btree *BTree) Snapshot() *BTree {
+	snapshot := btree.Clone()
+	snapshot.readonly = true
+	return snapshot
+}
+
+// checkWritable 只读快照不允许修改
+func (btree *BTree) checkWritable() {
+	if btree.readonly {
+		panic("btree: write to read-only snapshot")
+	}
+}
+
+// newNode 新建属于当前树的结点
+func (btree *BTree) newNode(leaf bool) *BNode {
+	node := newBNode(btree.order)
+	node.isleaf = leaf
+	node.cow = btree.cow
+	return node
+}
+
+// mutable 返回可以直接修改的结点，不属于当前树的结点先复制
+func (btree *BTree) mutable(node *BNode) *BNode {
+	if node.cow == btree.cow {
+		return node
 	}
+	return node.clone(btree.cow)
 }
 
-// Insert 插入
+// mutableChild 返回可以直接修改的第 i 个孩子结点，并替换父结点中的指针
+func (btree *BTree) mutableChild(parent *BNode, i int) *BNode {
+	child := btree.mutable(parent.childNodes[i])
+	parent.childNodes[i] = child
+	return child
+}
+
+// Insert 插入，key 已存在时替换 value
 func (btree *BTree) Insert(key int64, value interface{}) {
+	btree.Put(key, value)
+}
+
+// Put 插入，key 已存在时替换 value 并返回旧的 value 与 replaced=true
+func (btree *BTree) Put(key int64, value interface{}) (old interface{}, replaced bool) {
+	btree.checkWritable()
 	kvnode := newKvNode(key, value)
 	if btree.root == nil {
-		root := newBNode(btree.order)
-		root.kvNodes[0] = kvnode
-		root.num++
+		root := btree.newNode(true)
+		root.insertKvn(kvnode, 0)
 		btree.root = root
+		btree.length++
 		return
 	}
-	if btree.root.num == 0 {
-		btree.root.kvNodes[0] = kvnode
-		btree.root.num++
-	}
-	btree.insert(btree.root, 0, kvnode)
-}
-
-// insert 找到插入点
-func (btree *BTree) insert(pnode *BNode, index int, kvnode *KvNode) {
-	childNum := len(pnode.childNodes)
-	if kvnode.key < pnode.kvNodes[index].key {
-		if childNum > 0 {
-			btree.insert(pnode.childNodes[index], 0, kvnode) // 左孩子找
-		} else {
-			// 插入到结点中(头 或 中间)
-			// 后移
-			copy(pnode.kvNodes[index+1:], pnode.kvNodes[index:])
-			// 插入
-			pnode.kvNodes[index] = kvnode
-			pnode.num++
-			btree.insertCheck(pnode)
-		}
-	} else if kvnode.key > pnode.kvNodes[index].key {
-		if index+1 < pnode.num {
-			btree.insert(pnode, index+1, kvnode) // 后移
-		} else {
-			if childNum > 0 {
-				btree.insert(pnode.childNodes[index+1], 0, kvnode) // 对最后一个成员的右子树遍历
-			} else {
-				// 插入到结点最后(一定为叶子结点)
-				pnode.kvNodes[index+1] = kvnode
-				pnode.num++
-				btree.insertCheck(pnode)
-			}
-		}
+	btree.root = btree.mutable(btree.root)
+	if oldKvn := btree.insert(btree.root, kvnode); oldKvn != nil {
+		old, replaced = oldKvn.value, true
 	} else {
-		pnode.kvNodes[index].value = kvnode.value
+		btree.length++
+	}
+	if btree.root.num >= btree.order { // 根结点分裂，树高加一
+		root := btree.newNode(false)
+		root.childNodes = append(root.childNodes, btree.root)
+		btree.split(root, 0)
+		btree.root = root
 	}
+	return
 }
 
-// insertCheck 插入校验
-func (btree *BTree) insertCheck(node *BNode) {
-	if node.num < btree.order {
-		return
+// insert 在以 node 为根的子树中插入，node 必须可修改；返回被替换的 kvn，新增 key 时返回 nil
+func (btree *BTree) insert(node *BNode, kvnode *KvNode) *KvNode {
+	i, found := node.search(kvnode.key)
+	if found {
+		// KvNode 可能被其他树共享，替换而不是修改
+		old := node.kvNodes[i]
+		node.kvNodes[i] = kvnode
+		return old
 	}
-
-	// 分裂，提升结点
-	mid := node.num / 2
-	var (
-		newKvNode    = newKvNode(node.kvNodes[mid].key, node.kvNodes[mid].value)
-		newLeftNode  = newBNode(btree.order)
-		newRightNode = newBNode(btree.order)
-	)
-	// 分裂
-	if !node.isleaf {
-		newLeftNode.isleaf = false
-		newRightNode.isleaf = false
+	if node.isleaf {
+		node.insertKvn(kvnode, i)
+		return nil
 	}
-	newLeftNode.num = mid
-	newRightNode.num = btree.order - mid - 1
-	newLeftNode.pindex = node.pindex
-	newRightNode.pindex = node.pindex + 1
-	copy(newLeftNode.kvNodes[:], node.kvNodes[:mid])
-	copy(newRightNode.kvNodes[:], node.kvNodes[mid+1:])
-	if len(node.childNodes) > 0 {
-		newLeftNode.childNodes = append(newLeftNode.childNodes, node.childNodes[:mid+1]...)
-		newRightNode.childNodes = append(newRightNode.childNodes, node.childNodes[mid+1:]...)
-		// 重新赋父结点
-		for i, c := range newLeftNode.childNodes {
-			c.parent = newLeftNode
-			c.pindex = i
-		}
-		for i, c := range newRightNode.childNodes {
-			c.parent = newRightNode
-			c.pindex = i
-		}
+	child := btree.mutableChild(node, i)
+	old := btree.insert(child, kvnode)
+	if child.num >= btree.order {
+		btree.split(node, i)
 	}
+	return old
+}
 
-	// 提升
-	if node == btree.root { // 根结点
-		var newRoot = newBNode(btree.order)
-
-		newLeftNode.pindex = 0
-		newRightNode.pindex = 1
-		newLeftNode.parent = newRoot
-		newRightNode.parent = newRoot
-
-		newRoot.kvNodes[0] = newKvNode
-		newRoot.num = 1
-		newRoot.childNodes = append(newRoot.childNodes, newLeftNode)
-		newRoot.childNodes = append(newRoot.childNodes, newRightNode)
-		newRoot.isleaf = false
-		btree.root = newRoot
-		node.freeBNode()
-	} else { // 非根结点
-		var (
-			parent = node.parent
-			i      = node.pindex // 插入点
-		)
-		node.freeBNode()
-		newLeftNode.parent = parent
-		newRightNode.parent = parent
-		if i < parent.num { // 中间 or 头
-			copy(parent.kvNodes[i+1:], parent.kvNodes[i:])
-			parent.kvNodes[i] = newKvNode
-			// 先删除再添加
-			parent.deletePosNode(i)
-			// 注意顺序
-			parent.insertPosNode(newRightNode, i)
-			parent.insertPosNode(newLeftNode, i)
-			// 重新赋 parent.childNodes[].pindex
-			for j := i + 2; j < len(parent.childNodes) && parent.childNodes[j] != nil; j++ {
-				parent.childNodes[j].pindex += 1
-			}
-			parent.num++
-		} else { // 最后
-			parent.kvNodes[i] = newKvNode
-			// 先删除再添加
-			parent.deletePosNode(i)
-			parent.childNodes = append(parent.childNodes, newLeftNode)
-			parent.childNodes = append(parent.childNodes, newRightNode)
-			parent.num++
-		}
-		btree.insertCheck(parent) // 继续递归处理
-	}
+// split 分裂 parent 的第 i 个孩子结点，中间的 key 提升到 parent
+func (btree *BTree) split(parent *BNode, i int) {
+	var (
+		child = parent.childNodes[i]
+		right = btree.newNode(child.isleaf)
+	)
+	upKvNode := child.split(child.num/2, right)
+	parent.insertKvn(upKvNode, i)
+	parent.insertPosNode(right, i+1)
 }
 
-// Delete 删除结点
-func (btree *BTree) Delete(key int64) bool {
+// Delete 删除结点，返回被删除的 value
+func (btree *BTree) Delete(key int64) (interface{}, bool) {
 	if btree == nil || btree.root == nil {
-		return false
+		return nil, false
 	}
-	return btree.delete(btree.root, 0, key)
-}
-
-// delete 找到删除点
-func (btree *BTree) delete(node *BNode, index int, key int64) bool {
-	childNum := len(node.childNodes)
-	if key < node.kvNodes[index].key {
-		if childNum > 0 {
-			return btree.delete(node.childNodes[index], 0, key)
-		}
-		return false
-	} else if key > node.kvNodes[index].key {
-		if index+1 < node.num {
-			return btree.delete(node, index+1, key)
-		} else {
-			if childNum > 0 {
-				return btree.delete(node.childNodes[index+1], 0, key)
-			}
-		}
-		return false
-	} else {
-		if node.isleaf {
-			// 直接覆盖
-			copy(node.kvNodes[index:], node.kvNodes[index+1:])
-			node.num--
-			btree.deleteCheck(node)
+	btree.checkWritable()
+	btree.root = btree.mutable(btree.root)
+	kvn := btree.delete(btree.root, key)
+	if kvn != nil {
+		btree.length--
+	}
+	if btree.root.num == 0 { // 根结点为空，树高减一
+		if btree.root.isleaf {
+			btree.root = nil
 		} else {
-			// 用后继结点替换
-			delNode := node.childNodes[index+1]
-			for !delNode.isleaf {
-				delNode = delNode.childNodes[0]
-			}
-			node.kvNodes[index].key = delNode.kvNodes[0].key
-			node.kvNodes[index].value = delNode.kvNodes[0].value
-			copy(delNode.kvNodes[0:], delNode.kvNodes[1:])
-			delNode.num--
-			btree.deleteCheck(delNode)
+			btree.root = btree.root.childNodes[0]
 		}
-		return true
 	}
+	if kvn == nil {
+		return nil, false
+	}
+	return kvn.value, true
 }
 
-// deleteCheck 删除校验
-func (btree *BTree) deleteCheck(node *BNode) {
-	if node == btree.root || node.num >= btree.minNum {
-		if btree.root.num == 0 && len(btree.root.childNodes) > 0 {
-			btree.root = btree.root.childNodes[0]
-			btree.root.pindex = -1
-			btree.root.parent = nil
+// delete 在以 node 为根的子树中删除 key，node 必须可修改；返回被删除的 kvn，不存在时返回 nil
+func (btree *BTree) delete(node *BNode, key int64) *KvNode {
+	i, found := node.search(key)
+	if node.isleaf {
+		if !found {
+			return nil
 		}
-		return
+		return node.deleteKvn(i)
 	}
-	var (
-		leftSib     = node.getLeftSib()
-		rightSib    = node.getRightSib()
-		leftSibNum  = 0
-		rightSibNum = 0
-	)
-	if leftSib != nil {
-		leftSibNum = leftSib.num
+	if found {
+		// 用后继结点替换
+		kvn := node.kvNodes[i]
+		child := btree.mutableChild(node, i+1)
+		node.kvNodes[i] = btree.deleteMin(child)
+		btree.deleteCheck(node, i+1)
+		return kvn
 	}
-	if rightSib != nil {
-		rightSibNum = rightSib.num
+	child := btree.mutableChild(node, i)
+	kvn := btree.delete(child, key)
+	if kvn == nil {
+		return nil
 	}
+	btree.deleteCheck(node, i)
+	return kvn
+}
 
-	var parent = node.parent
-	if leftSibNum <= btree.minNum && rightSibNum <= btree.minNum {
+// deleteMin 删除以 node 为根的子树中最小的 key，node 必须可修改
+func (btree *BTree) deleteMin(node *BNode) *KvNode {
+	if node.isleaf {
+		return node.deleteKvn(0)
+	}
+	kvn := btree.deleteMin(btree.mutableChild(node, 0))
+	btree.deleteCheck(node, 0)
+	return kvn
+}
+
+// deleteCheck 删除校验，parent 的第 i 个孩子结点 key 不足时向兄弟借或合并
+func (btree *BTree) deleteCheck(parent *BNode, i int) {
+	node := parent.childNodes[i]
+	if node.num >= btree.minNum {
+		return
+	}
+	if i > 0 && parent.childNodes[i-1].num > btree.minNum {
+		leftSib := btree.mutableChild(parent, i-1)
+		leftSib.moveToRight(parent, node, i-1)
+	} else if i < parent.num && parent.childNodes[i+1].num > btree.minNum {
+		rightSib := btree.mutableChild(parent, i+1)
+		rightSib.moveToLeft(parent, node, i+1)
+	} else {
 		// 需要合并结点
-		var downKvn *KvNode = nil
-		if leftSib != nil {
-			downKvn = parent.kvNodes[leftSib.pindex]
-			copy(parent.kvNodes[leftSib.pindex:], parent.kvNodes[leftSib.pindex+1:])
-			leftSib.mergeNode(downKvn, node)
-			for i := node.pindex + 1; i <= parent.num; i++ {
-				// TODO: 合并后记得更改后面结点的pindex
-				parent.childNodes[i].pindex--
-			}
-			parent.deletePosNode(node.pindex)
-			node = nil
-		} else {
-			downKvn = parent.kvNodes[node.pindex]
-			copy(parent.kvNodes[node.pindex:], parent.kvNodes[node.pindex+1:])
-			node.mergeNode(downKvn, rightSib)
-			for i := rightSib.pindex + 1; i <= parent.num; i++ {
-				// TODO: 合并后记得更改后面结点的pindex
-				parent.childNodes[i].pindex--
-			}
-			parent.deletePosNode(rightSib.pindex)
-			rightSib = nil
+		if i == parent.num {
+			i-- // 与左兄弟合并
+		}
+		var (
+			left    = btree.mutableChild(parent, i)
+			right   = parent.childNodes[i+1]
+			downKvn = parent.deleteKvn(i)
+		)
+		left.mergeNode(downKvn, right)
+		parent.deletePosNode(i + 1)
+		if right.cow == btree.cow { // 共享的结点仍被其他树使用，不能释放
+			right.freeBNode()
 		}
-		parent.num--
-		btree.deleteCheck(parent)
-	} else if leftSibNum > btree.minNum {
-		leftSib.moveToRight(parent, node)
-	} else {
-		rightSib.moveToLeft(parent, node)
 	}
 }
 
@@ -272,23 +243,10 @@ func (btree *BTree) PrintTreeInLog() {
 		size := queue.Size()
 		for i := 0; i < size; i++ {
 			node, _ := queue.Pop().(*BNode)
-			// // 打印父结点
-			// if node.parent != nil {
-			// 	fmt.Print("parent:", node.parent.kvNodes[0].key)
-			// }
 			// // 打印结点key的个数
 			// fmt.Print("[", node.isleaf, node.num, "]")
-			// // 打印孩子结点个数 和 pindex
-			// fmt.Print("[", len(node.childNodes), node.pindex, "]")
-			// // 打印左右兄弟
-			// leftSib := node.getLeftSib()
-			// rightSib := node.getRightSib()
-			// if leftSib != nil {
-			// 	fmt.Printf("[左兄弟: %v]", leftSib.kvNodes[leftSib.num-1].key)
-			// }
-			// if rightSib != nil {
-			// 	fmt.Printf("[右兄弟: %v]", rightSib.kvNodes[0].key)
-			// }
+			// // 打印孩子结点个数
+			// fmt.Print("[", len(node.childNodes), "]")
 			len1 := int(node.num)
 			for index, kv := range node.kvNodes {
 				if kv == nil || index >= node.num {
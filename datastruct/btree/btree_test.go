@@ -2,6 +2,9 @@ package btree
 
 import (
 	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
 	"testing"
 )
 
@@ -87,3 +90,191 @@ func TestSlice(t *testing.T) {
 	fmt.Println(s1)
 }
 
+
+// checkBTree 校验 B 树的结构，返回中序遍历得到的 kv
+func checkBTree(t *testing.T, btree *BTree) []KvNode {
+	t.Helper()
+	var (
+		kvs       []KvNode
+		leafDepth = -1
+		walk      func(node *BNode, depth int)
+	)
+	walk = func(node *BNode, depth int) {
+		if node != btree.root && (node.num < btree.minNum || node.num > btree.order-1) {
+			t.Fatalf("结点 key 的个数 %d 超出范围 [%d, %d]", node.num, btree.minNum, btree.order-1)
+		}
+		if node.isleaf != (len(node.childNodes) == 0) {
+			t.Fatalf("isleaf=%v, 孩子个数 %d", node.isleaf, len(node.childNodes))
+		}
+		if node.isleaf {
+			if leafDepth == -1 {
+				leafDepth = depth
+			} else if leafDepth != depth {
+				t.Fatalf("叶子结点深度不一致: %d, %d", leafDepth, depth)
+			}
+			for i := 0; i < node.num; i++ {
+				kvs = append(kvs, *node.kvNodes[i])
+			}
+			return
+		}
+		if len(node.childNodes) != node.num+1 {
+			t.Fatalf("孩子个数 %d, 期望 %d", len(node.childNodes), node.num+1)
+		}
+		for i, c := range node.childNodes {
+			walk(c, depth+1)
+			if i < node.num {
+				kvs = append(kvs, *node.kvNodes[i])
+			}
+		}
+	}
+	if btree.root != nil {
+		if btree.root.num == 0 {
+			t.Fatalf("根结点为空")
+		}
+		walk(btree.root, 0)
+	}
+	for i := 1; i < len(kvs); i++ {
+		if kvs[i-1].key >= kvs[i].key {
+			t.Fatalf("中序遍历结果无序: %d, %d", kvs[i-1].key, kvs[i].key)
+		}
+	}
+	if err := btree.Verify(); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	return kvs
+}
+
+func btreeKeys(kvs []KvNode) []int64 {
+	keys := make([]int64, len(kvs))
+	for i, kv := range kvs {
+		keys[i] = kv.key
+	}
+	return keys
+}
+
+// checkContent 校验树中的 kv 与 ref 一致
+func checkContent(t *testing.T, btree *BTree, ref map[int64]int) {
+	t.Helper()
+	kvs := checkBTree(t, btree)
+	if len(kvs) != len(ref) {
+		t.Fatalf("kv 数量 %d, 期望 %d", len(kvs), len(ref))
+	}
+	for _, kv := range kvs {
+		if v, ok := ref[kv.key]; !ok || v != kv.value {
+			t.Fatalf("key %d 的 value 为 %v, 期望 %d", kv.key, kv.value, v)
+		}
+	}
+}
+
+// 随机插入删除后与 map 对比
+func TestBTreeRandom(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 8, 32} {
+		btree := NewBTree(order)
+		ref := make(map[int64]int)
+		r := rand.New(rand.NewSource(int64(order)))
+		for i := 0; i < 5000; i++ {
+			key := r.Int63n(500)
+			if r.Intn(3) == 0 {
+				_, exist := ref[key]
+				if _, ok := btree.Delete(key); ok != exist {
+					t.Fatalf("order=%d 删除 %d 结果错误", order, key)
+				}
+				delete(ref, key)
+			} else {
+				btree.Insert(key, i)
+				ref[key] = i
+			}
+		}
+		checkContent(t, btree, ref)
+	}
+}
+
+func copyMap(ref map[int64]int) map[int64]int {
+	res := make(map[int64]int, len(ref))
+	for k, v := range ref {
+		res[k] = v
+	}
+	return res
+}
+
+func TestBTreeClone(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	btree := NewBTree(4)
+	ref := make(map[int64]int)
+	for i := 0; i < 1000; i++ {
+		key := r.Int63n(2000)
+		btree.Insert(key, i)
+		ref[key] = i
+	}
+
+	// 多次 Clone 后各自修改，互不影响
+	var (
+		trees = []*BTree{btree}
+		refs  = []map[int64]int{ref}
+	)
+	for round := 0; round < 4; round++ {
+		for i := range trees {
+			trees = append(trees, trees[i].Clone())
+			refs = append(refs, copyMap(refs[i]))
+		}
+		for i, tree := range trees {
+			for j := 0; j < 200; j++ {
+				key := r.Int63n(2000)
+				if r.Intn(2) == 0 {
+					tree.Delete(key)
+					delete(refs[i], key)
+				} else {
+					tree.Insert(key, j)
+					refs[i][key] = j
+				}
+			}
+		}
+		for i, tree := range trees {
+			checkContent(t, tree, refs[i])
+		}
+	}
+}
+
+func TestBTreeSnapshot(t *testing.T) {
+	btree := NewBTree(5)
+	ref := make(map[int64]int)
+	for i := 0; i < 500; i++ {
+		btree.Insert(int64(i), i)
+		ref[int64(i)] = i
+	}
+	snapshot := btree.Snapshot()
+
+	// 写操作与快照的读取并发执行
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 20; i++ {
+				kvs := checkBTree(t, snapshot)
+				if !slices.Equal(btreeKeys(kvs), btreeKeys(checkBTree(t, snapshot))) || len(kvs) != 500 {
+					t.Errorf("快照内容发生变化")
+					return
+				}
+			}
+		}()
+	}
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 3000; i++ {
+		key := r.Int63n(1000)
+		if r.Intn(2) == 0 {
+			btree.Delete(key)
+		} else {
+			btree.Insert(key, -i)
+		}
+	}
+	wg.Wait()
+	checkContent(t, snapshot, ref)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("修改只读快照应 panic")
+		}
+	}()
+	snapshot.Insert(1, 1)
+}
@@ -0,0 +1,64 @@
+package btree
+
+import (
+	"DataStruct/datastruct"
+	"math"
+)
+
+// BulkLoad 由按 key 严格升序的序列自底向上构建 B 树
+//
+//	@order 阶数
+//	@fillFactor 结点的填充率，取值 (0, 1]，超出范围时按 1 处理；结点 key 的个数不会低于 B 树的下限
+//	@seq 有序的 kv 序列，出现乱序或重复的 key 时返回 *datastruct.UnsortedInputError
+func BulkLoad(order int, fillFactor float64, seq datastruct.Seq2[int64, interface{}]) (*BTree, error) {
+	btree := NewBTree(order)
+	var kvns []*KvNode
+	err := datastruct.ForEachSorted(seq, datastruct.OrderedComparator[int64](), func(key int64, value interface{}) {
+		kvns = append(kvns, newKvNode(key, value))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(kvns) == 0 {
+		return btree, nil
+	}
+	btree.length = len(kvns)
+
+	if fillFactor <= 0 || fillFactor > 1 {
+		fillFactor = 1
+	}
+	target := int(math.Round(fillFactor * float64(order-1)))
+	target = max(target, btree.minNum, 1)
+	target = min(target, order-1)
+
+	// 逐层构建：每个结点取若干 key，相邻结点之间的 key 提升到上一层作为分隔
+	var children []*BNode
+	for {
+		// 每个结点连同其后的分隔 key 看作一组，共 len(kvns)+1 组元素（最后一个结点没有分隔 key）
+		sizes := datastruct.SplitSizes(len(kvns)+1, target+1, btree.minNum+1)
+		var (
+			nodes = make([]*BNode, len(sizes))
+			seps  = make([]*KvNode, 0, len(sizes)-1)
+			pos   = 0
+		)
+		for i, size := range sizes {
+			node := btree.newNode(children == nil)
+			node.num = size - 1
+			copy(node.kvNodes, kvns[pos:pos+node.num])
+			if children != nil {
+				node.childNodes = append(node.childNodes, children[pos:pos+node.num+1]...)
+			}
+			pos += node.num
+			if i < len(sizes)-1 {
+				seps = append(seps, kvns[pos])
+				pos++
+			}
+			nodes[i] = node
+		}
+		if len(nodes) == 1 {
+			btree.root = nodes[0]
+			return btree, nil
+		}
+		kvns, children = seps, nodes
+	}
+}
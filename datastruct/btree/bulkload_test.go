@@ -0,0 +1,58 @@
+package btree
+
+import (
+	"DataStruct/datastruct"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func rangeSeq(keys []int64) datastruct.Seq2[int64, interface{}] {
+	return func(yield func(key int64, value interface{}) bool) {
+		for _, k := range keys {
+			if !yield(k, k*10) {
+				return
+			}
+		}
+	}
+}
+
+func TestBulkLoad(t *testing.T) {
+	for _, order := range []int{3, 4, 5, 8} {
+		for _, fill := range []float64{0, 0.1, 0.5, 0.75, 1} {
+			for n := 0; n < 300; n += 7 {
+				keys := make([]int64, n)
+				for i := range keys {
+					keys[i] = int64(i * 2)
+				}
+				btree, err := BulkLoad(order, fill, rangeSeq(keys))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := btreeKeys(checkBTree(t, btree)); !slices.Equal(got, keys) && n > 0 {
+					t.Fatalf("order=%d fill=%v n=%d: 遍历结果 %v", order, fill, n, got)
+				}
+
+				// 批量加载后的树可以继续插入与删除
+				for i := 0; i < n; i += 3 {
+					btree.Insert(int64(i*2+1), nil)
+					if _, ok := btree.Delete(int64(i * 2)); !ok {
+						t.Fatalf("删除 %d 失败", i*2)
+					}
+				}
+				checkBTree(t, btree)
+			}
+		}
+	}
+}
+
+func TestBulkLoadUnsorted(t *testing.T) {
+	_, err := BulkLoad(5, 1, rangeSeq([]int64{1, 2, 3, 3}))
+	var ue *datastruct.UnsortedInputError
+	if !errors.As(err, &ue) || !errors.Is(err, datastruct.ErrDuplicateKey) || ue.Index() != 3 {
+		t.Errorf("期望 ErrDuplicateKey, 实际 %v", err)
+	}
+	if _, err = BulkLoad(5, 1, rangeSeq([]int64{1, 3, 2})); !errors.Is(err, datastruct.ErrUnsortedInput) {
+		t.Errorf("期望 ErrUnsortedInput, 实际 %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package btree
+
+// Len kv 的数量
+func (btree *BTree) Len() int {
+	return btree.length
+}
+
+// Get 查找 key 对应的 value
+func (btree *BTree) Get(key int64) (interface{}, bool) {
+	for node := btree.root; node != nil; {
+		i, found := node.search(key)
+		if found {
+			return node.kvNodes[i].value, true
+		}
+		if node.isleaf {
+			break
+		}
+		node = node.childNodes[i]
+	}
+	return nil, false
+}
+
+// Has 判断 key 是否存在
+func (btree *BTree) Has(key int64) bool {
+	_, ok := btree.Get(key)
+	return ok
+}
+
+// Min 最小的 key 及其 value，空树返回 ok=false
+func (btree *BTree) Min() (key int64, value interface{}, ok bool) {
+	node := btree.root
+	if node == nil {
+		return 0, nil, false
+	}
+	for !node.isleaf {
+		node = node.childNodes[0]
+	}
+	kvn := node.kvNodes[0]
+	return kvn.key, kvn.value, true
+}
+
+// Max 最大的 key 及其 value，空树返回 ok=false
+func (btree *BTree) Max() (key int64, value interface{}, ok bool) {
+	node := btree.root
+	if node == nil {
+		return 0, nil, false
+	}
+	for !node.isleaf {
+		node = node.childNodes[node.num]
+	}
+	kvn := node.kvNodes[node.num-1]
+	return kvn.key, kvn.value, true
+}
+
+// Floor 小于等于 key 的最大 key 及其 value，不存在时返回 ok=false
+func (btree *BTree) Floor(key int64) (int64, interface{}, bool) {
+	var floor *KvNode
+	for node := btree.root; node != nil; {
+		i, found := node.search(key)
+		if found {
+			floor = node.kvNodes[i]
+			break
+		}
+		if i > 0 { // kvNodes[i-1] < key，子树中可能还有更接近的
+			floor = node.kvNodes[i-1]
+		}
+		if node.isleaf {
+			break
+		}
+		node = node.childNodes[i]
+	}
+	if floor == nil {
+		return 0, nil, false
+	}
+	return floor.key, floor.value, true
+}
+
+// Ceiling 大于等于 key 的最小 key 及其 value，不存在时返回 ok=false
+func (btree *BTree) Ceiling(key int64) (int64, interface{}, bool) {
+	var ceiling *KvNode
+	for node := btree.root; node != nil; {
+		i, found := node.search(key)
+		if i < node.num { // kvNodes[i] >= key，子树中可能还有更接近的
+			ceiling = node.kvNodes[i]
+		}
+		if found || node.isleaf {
+			break
+		}
+		node = node.childNodes[i]
+	}
+	if ceiling == nil {
+		return 0, nil, false
+	}
+	return ceiling.key, ceiling.value, true
+}
+
+// Ascend 按 key 升序遍历，fn 返回 false 时停止
+func (btree *BTree) Ascend(fn func(key int64, value interface{}) bool) {
+	if btree.root != nil {
+		btree.ascend(btree.root, fn)
+	}
+}
+
+// ascend 中序遍历子树，返回是否继续
+func (btree *BTree) ascend(node *BNode, fn func(key int64, value interface{}) bool) bool {
+	for i := 0; i < node.num; i++ {
+		if !node.isleaf && !btree.ascend(node.childNodes[i], fn) {
+			return false
+		}
+		if !fn(node.kvNodes[i].key, node.kvNodes[i].value) {
+			return false
+		}
+	}
+	return node.isleaf || btree.ascend(node.childNodes[node.num], fn)
+}
+
+// Range 按 key 升序遍历 [lo, hi) 区间，fn 返回 false 时停止
+func (btree *BTree) Range(lo, hi int64, fn func(key int64, value interface{}) bool) {
+	if btree.root != nil {
+		btree.ascendRange(btree.root, lo, hi, fn)
+	}
+}
+
+// ascendRange 中序遍历子树中 [lo, hi) 区间的 kv，跳过小于 lo 的孩子结点，返回是否继续
+func (btree *BTree) ascendRange(node *BNode, lo, hi int64, fn func(key int64, value interface{}) bool) bool {
+	i, _ := node.search(lo)
+	for ; i < node.num; i++ {
+		if !node.isleaf && !btree.ascendRange(node.childNodes[i], lo, hi, fn) {
+			return false
+		}
+		if node.kvNodes[i].key >= hi || !fn(node.kvNodes[i].key, node.kvNodes[i].value) {
+			return false
+		}
+	}
+	return node.isleaf || btree.ascendRange(node.childNodes[node.num], lo, hi, fn)
+}
+
+// Descend 按 key 降序遍历，fn 返回 false 时停止
+func (btree *BTree) Descend(fn func(key int64, value interface{}) bool) {
+	if btree.root != nil {
+		btree.descend(btree.root, fn)
+	}
+}
+
+// descend 逆序遍历子树，返回是否继续
+func (btree *BTree) descend(node *BNode, fn func(key int64, value interface{}) bool) bool {
+	for i := node.num - 1; i >= 0; i-- {
+		if !node.isleaf && !btree.descend(node.childNodes[i+1], fn) {
+			return false
+		}
+		if !fn(node.kvNodes[i].key, node.kvNodes[i].value) {
+			return false
+		}
+	}
+	return node.isleaf || btree.descend(node.childNodes[0], fn)
+}
@@ -0,0 +1,144 @@
+package btree
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+)
+
+func TestBTreeQuery(t *testing.T) {
+	for _, order := range []int{3, 4, 7} {
+		btree := NewBTree(order)
+		if _, _, ok := btree.Min(); ok {
+			t.Fatal("空树 Min 应返回 ok=false")
+		}
+		if _, _, ok := btree.Floor(0); ok {
+			t.Fatal("空树 Floor 应返回 ok=false")
+		}
+
+		ref := make(map[int64]int)
+		r := rand.New(rand.NewSource(int64(order)))
+		for i := 0; i < 2000; i++ {
+			key := r.Int63n(400) * 2 // 只有偶数 key
+			if r.Intn(4) == 0 {
+				btree.Delete(key)
+				delete(ref, key)
+			} else {
+				btree.Insert(key, i)
+				ref[key] = i
+			}
+		}
+		if btree.Len() != len(ref) {
+			t.Fatalf("order=%d Len=%d, 期望 %d", order, btree.Len(), len(ref))
+		}
+		keys := make([]int64, 0, len(ref))
+		for k := range ref {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+
+		for key := int64(-1); key <= 801; key++ {
+			v, ok := btree.Get(key)
+			if want, exist := ref[key]; ok != exist || (ok && v != want) {
+				t.Fatalf("Get(%d)=%v,%v, 期望 %v,%v", key, v, ok, want, exist)
+			}
+			if btree.Has(key) != ok {
+				t.Fatalf("Has(%d) 与 Get 不一致", key)
+			}
+
+			// 参照有序切片计算 Floor 与 Ceiling
+			i := sort.Search(len(keys), func(i int) bool { return keys[i] > key })
+			fk, fv, ok := btree.Floor(key)
+			if ok != (i > 0) || (ok && (fk != keys[i-1] || fv != ref[fk])) {
+				t.Fatalf("Floor(%d)=%d,%v 错误", key, fk, ok)
+			}
+			i = sort.Search(len(keys), func(i int) bool { return keys[i] >= key })
+			ck, cv, ok := btree.Ceiling(key)
+			if ok != (i < len(keys)) || (ok && (ck != keys[i] || cv != ref[ck])) {
+				t.Fatalf("Ceiling(%d)=%d,%v 错误", key, ck, ok)
+			}
+		}
+
+		if k, v, ok := btree.Min(); !ok || k != keys[0] || v != ref[k] {
+			t.Fatalf("Min=%d, 期望 %d", k, keys[0])
+		}
+		if k, v, ok := btree.Max(); !ok || k != keys[len(keys)-1] || v != ref[k] {
+			t.Fatalf("Max=%d, 期望 %d", k, keys[len(keys)-1])
+		}
+
+		var asc, desc []int64
+		btree.Ascend(func(key int64, value interface{}) bool {
+			asc = append(asc, key)
+			return true
+		})
+		btree.Descend(func(key int64, value interface{}) bool {
+			desc = append(desc, key)
+			return true
+		})
+		slices.Reverse(desc)
+		if !slices.Equal(asc, keys) || !slices.Equal(desc, keys) {
+			t.Fatalf("order=%d 遍历结果与期望不一致", order)
+		}
+
+		// 提前终止
+		asc = asc[:0]
+		btree.Ascend(func(key int64, value interface{}) bool {
+			asc = append(asc, key)
+			return len(asc) < 10
+		})
+		if !slices.Equal(asc, keys[:10]) {
+			t.Fatalf("Ascend 提前终止 = %v", asc)
+		}
+		desc = desc[:0]
+		btree.Descend(func(key int64, value interface{}) bool {
+			desc = append(desc, key)
+			return len(desc) < 10
+		})
+		slices.Reverse(desc)
+		if !slices.Equal(desc, keys[len(keys)-10:]) {
+			t.Fatalf("Descend 提前终止 = %v", desc)
+		}
+
+		// 区间遍历，lo、hi 覆盖奇数、偶数以及超出范围的情况
+		for _, bound := range [][2]int64{{-5, 1000}, {101, 301}, {100, 300}, {300, 100}, {799, 900}} {
+			var got, want []int64
+			btree.Range(bound[0], bound[1], func(key int64, value interface{}) bool {
+				if value != ref[key] {
+					t.Fatalf("Range key %d 的 value 为 %v", key, value)
+				}
+				got = append(got, key)
+				return true
+			})
+			for _, k := range keys {
+				if k >= bound[0] && k < bound[1] {
+					want = append(want, k)
+				}
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("order=%d Range(%d, %d)=%v, 期望 %v", order, bound[0], bound[1], got, want)
+			}
+		}
+	}
+}
+
+func TestBTreeLen(t *testing.T) {
+	btree, err := BulkLoad(5, 1, rangeSeq([]int64{1, 2, 3, 4, 5}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone := btree.Clone()
+	btree.Insert(6, nil)
+	btree.Insert(1, nil) // 替换不改变数量
+	clone.Delete(1)
+	clone.Delete(100)
+	if btree.Len() != 6 || clone.Len() != 4 {
+		t.Fatalf("Len=%d,%d, 期望 6,4", btree.Len(), clone.Len())
+	}
+	for i := int64(1); i <= 6; i++ {
+		btree.Delete(i)
+	}
+	if btree.Len() != 0 || btree.root != nil {
+		t.Fatalf("全部删除后 Len=%d", btree.Len())
+	}
+}
@@ -0,0 +1,88 @@
+package btree
+
+import (
+	"DataStruct/datastruct"
+	"fmt"
+)
+
+// Verify 校验 B 树的不变式：结点 key 的个数在 [minNum, order-1] 之间（根结点至少 1 个）、
+// 结点内与子树之间 key 严格有序、孩子个数比 key 多 1、叶子结点在同一层、kv 数量等于 Len，
+// 返回发现的第一处错误（*datastruct.InvariantError）
+func (btree *BTree) Verify() error {
+	if btree.root == nil {
+		if btree.length != 0 {
+			return datastruct.NewInvariantError("root", "empty tree with length %d", btree.length)
+		}
+		return nil
+	}
+	v := &verifier{btree: btree, leafDepth: -1}
+	if err := v.verify(btree.root, "root", 0, nil, nil); err != nil {
+		return err
+	}
+	if v.count != btree.length {
+		return datastruct.NewInvariantError("root", "%d keys, length %d", v.count, btree.length)
+	}
+	return nil
+}
+
+// verifier 校验过程中的状态
+type verifier struct {
+	btree     *BTree
+	leafDepth int // 第一个叶子结点的深度
+	count     int // 已校验的 key 的个数
+}
+
+// verify 校验子树，lo、hi 为子树中 key 的开区间边界（nil 表示无边界）
+func (v *verifier) verify(node *BNode, path string, depth int, lo, hi *int64) error {
+	minNum := v.btree.minNum
+	if node == v.btree.root {
+		minNum = 1
+	}
+	if node.num < minNum || node.num > v.btree.order-1 {
+		return datastruct.NewInvariantError(path, "%d keys, expected [%d, %d]", node.num, minNum, v.btree.order-1)
+	}
+	for i := 0; i < node.num; i++ {
+		kvn := node.kvNodes[i]
+		if kvn == nil {
+			return datastruct.NewInvariantError(path, "nil key at %d", i)
+		}
+		if (i > 0 && node.kvNodes[i-1].key >= kvn.key) || (i == 0 && lo != nil && kvn.key <= *lo) {
+			return datastruct.NewInvariantError(path, "key %d at %d out of order", kvn.key, i)
+		}
+		if i == node.num-1 && hi != nil && kvn.key >= *hi {
+			return datastruct.NewInvariantError(path, "key %d >= upper bound %d", kvn.key, *hi)
+		}
+	}
+	v.count += node.num
+
+	if node.isleaf {
+		if len(node.childNodes) != 0 {
+			return datastruct.NewInvariantError(path, "leaf has %d children", len(node.childNodes))
+		}
+		if v.leafDepth == -1 {
+			v.leafDepth = depth
+		} else if v.leafDepth != depth {
+			return datastruct.NewInvariantError(path, "leaf at depth %d, expected %d", depth, v.leafDepth)
+		}
+		return nil
+	}
+	if len(node.childNodes) != node.num+1 {
+		return datastruct.NewInvariantError(path, "%d children for %d keys", len(node.childNodes), node.num)
+	}
+	for i, child := range node.childNodes {
+		if child == nil {
+			return datastruct.NewInvariantError(path, "nil child at %d", i)
+		}
+		clo, chi := lo, hi
+		if i > 0 {
+			clo = &node.kvNodes[i-1].key
+		}
+		if i < node.num {
+			chi = &node.kvNodes[i].key
+		}
+		if err := v.verify(child, fmt.Sprintf("%s/%d", path, i), depth+1, clo, chi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
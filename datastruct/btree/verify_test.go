@@ -0,0 +1,68 @@
+package btree
+
+import (
+	"DataStruct/datastruct"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestBTreeVerify(t *testing.T) {
+	build := func() *BTree {
+		btree := NewBTree(5)
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < 300; i++ {
+			btree.Insert(r.Int63n(1000), i)
+		}
+		for i := 0; i < 100; i++ {
+			btree.Delete(r.Int63n(1000))
+		}
+		return btree
+	}
+	if err := NewBTree(5).Verify(); err != nil {
+		t.Fatalf("空树校验失败: %v", err)
+	}
+	if err := build().Verify(); err != nil {
+		t.Fatalf("随机插入删除后校验失败: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		corrupt func(btree *BTree)
+		path    string
+	}{
+		{"kv 数量错误", func(btree *BTree) { btree.length++ }, "root"},
+		{"结点内无序", func(btree *BTree) {
+			leaf := btree.root.childNodes[1].childNodes[0]
+			leaf.kvNodes[0], leaf.kvNodes[1] = leaf.kvNodes[1], leaf.kvNodes[0]
+		}, "root/1/0"},
+		{"超出分隔 key", func(btree *BTree) {
+			leaf := btree.root.childNodes[0].childNodes[1]
+			leaf.kvNodes[0] = newKvNode(btree.root.childNodes[0].kvNodes[0].key, nil)
+		}, "root/0/1"},
+		{"结点 key 不足", func(btree *BTree) {
+			leaf := btree.root.childNodes[0].childNodes[0]
+			leaf.num = 1
+		}, "root/0/0"},
+		{"叶子深度不一致", func(btree *BTree) {
+			// 用最左的叶子结点替换整棵子树
+			leaf := btree.root.childNodes[1]
+			for !leaf.isleaf {
+				leaf = leaf.childNodes[0]
+			}
+			btree.root.childNodes[1] = leaf
+		}, "root/1"},
+	}
+	for _, c := range cases {
+		btree := build()
+		if len(btree.root.childNodes) < 2 || btree.root.childNodes[0].isleaf {
+			t.Fatal("测试需要至少 3 层的树")
+		}
+		c.corrupt(btree)
+		err := btree.Verify()
+		var ie *datastruct.InvariantError
+		if !errors.Is(err, datastruct.ErrInvariant) || !errors.As(err, &ie) || ie.Path() != c.path {
+			t.Errorf("%s: 期望 %s 处的错误, 实际 %v", c.name, c.path, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package datastruct
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Codec 值的序列化与反序列化，用于将数据结构持久化到磁盘
+type Codec[T any] interface {
+	// Append 将 v 编码后追加到 dst 并返回新的切片，v 无法编码时返回 error
+	Append(dst []byte, v T) ([]byte, error)
+	// Decode 解码 Append 生成的完整编码，src 在返回后可能被复用，不能被结果引用
+	Decode(src []byte) (T, error)
+}
+
+// Integer 整数类型约束
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// IntegerCodec 整数编码为 8 字节大端序
+type IntegerCodec[T Integer] struct{}
+
+func (IntegerCodec[T]) Append(dst []byte, v T) ([]byte, error) {
+	return binary.BigEndian.AppendUint64(dst, uint64(v)), nil
+}
+
+func (IntegerCodec[T]) Decode(src []byte) (T, error) {
+	if len(src) != 8 {
+		return 0, fmt.Errorf("integer codec: invalid length %d", len(src))
+	}
+	return T(binary.BigEndian.Uint64(src)), nil
+}
+
+// Float64Codec 浮点数编码为 8 字节 IEEE 754
+type Float64Codec struct{}
+
+func (Float64Codec) Append(dst []byte, v float64) ([]byte, error) {
+	return binary.BigEndian.AppendUint64(dst, math.Float64bits(v)), nil
+}
+
+func (Float64Codec) Decode(src []byte) (float64, error) {
+	if len(src) != 8 {
+		return 0, fmt.Errorf("float64 codec: invalid length %d", len(src))
+	}
+	return math.Float64frombits(binary.BigEndian.Uint64(src)), nil
+}
+
+// StringCodec 字符串原样编码
+type StringCodec struct{}
+
+func (StringCodec) Append(dst []byte, v string) ([]byte, error) {
+	return append(dst, v...), nil
+}
+
+func (StringCodec) Decode(src []byte) (string, error) {
+	return string(src), nil
+}
+
+// BytesCodec 字节切片原样编码，解码时复制一份
+type BytesCodec struct{}
+
+func (BytesCodec) Append(dst []byte, v []byte) ([]byte, error) {
+	return append(dst, v...), nil
+}
+
+func (BytesCodec) Decode(src []byte) ([]byte, error) {
+	return append([]byte(nil), src...), nil
+}
+
+// JSONCodec 使用 encoding/json 编码任意类型
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Append(dst []byte, v T) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return dst, fmt.Errorf("json codec: %w", err)
+	}
+	return append(dst, b...), nil
+}
+
+func (JSONCodec[T]) Decode(src []byte) (T, error) {
+	var v T
+	err := json.Unmarshal(src, &v)
+	return v, err
+}
@@ -0,0 +1,63 @@
+package datastruct
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+// mustAppend 编码 v 并追加到 dst，编码失败时测试失败
+func mustAppend[T any](t *testing.T, c Codec[T], dst []byte, v T) []byte {
+	t.Helper()
+	buf, err := c.Append(dst, v)
+	if err != nil {
+		t.Fatalf("编码 %v 失败: %v", v, err)
+	}
+	return buf
+}
+
+func TestCodec(t *testing.T) {
+	ic := IntegerCodec[int64]{}
+	for _, v := range []int64{0, 1, -1, 1 << 62, -1 << 63} {
+		got, err := ic.Decode(mustAppend[int64](t, ic, nil, v))
+		if err != nil || got != v {
+			t.Errorf("IntegerCodec %d 解码为 %d, %v", v, got, err)
+		}
+	}
+	if _, err := ic.Decode([]byte{1, 2}); err == nil {
+		t.Error("长度错误应返回 error")
+	}
+
+	fc := Float64Codec{}
+	if got, err := fc.Decode(mustAppend[float64](t, fc, nil, 3.25)); err != nil || got != 3.25 {
+		t.Errorf("Float64Codec 解码为 %v, %v", got, err)
+	}
+
+	sc := StringCodec{}
+	buf := mustAppend[string](t, sc, []byte("prefix:"), "value")
+	if got, _ := sc.Decode(buf[len("prefix:"):]); got != "value" {
+		t.Errorf("StringCodec 解码为 %q", got)
+	}
+
+	bc := BytesCodec{}
+	src := mustAppend[[]byte](t, bc, nil, []byte{1, 2, 3})
+	got, _ := bc.Decode(src)
+	src[0] = 9
+	if !bytes.Equal(got, []byte{1, 2, 3}) {
+		t.Errorf("BytesCodec 解码结果应与输入独立, got %v", got)
+	}
+
+	type point struct{ X, Y int }
+	jc := JSONCodec[point]{}
+	if p, err := jc.Decode(mustAppend[point](t, jc, nil, point{1, 2})); err != nil || p != (point{1, 2}) {
+		t.Errorf("JSONCodec 解码为 %v, %v", p, err)
+	}
+
+	// 无法编码的值返回 error 而不是 panic，dst 保持不变
+	if buf, err := (JSONCodec[float64]{}).Append([]byte("prefix"), math.NaN()); err == nil || string(buf) != "prefix" {
+		t.Errorf("JSONCodec 编码 NaN 应返回 error, got %q, %v", buf, err)
+	}
+	if _, err := (JSONCodec[chan int]{}).Append(nil, make(chan int)); err == nil {
+		t.Error("JSONCodec 编码 chan 应返回 error")
+	}
+}
@@ -0,0 +1,37 @@
+package datastruct
+
+import "cmp"
+
+// Comparator 比较函数，a<b 返回负数，a==b 返回 0，a>b 返回正数
+type Comparator[K any] func(a, b K) int
+
+// OrderedComparator 返回有序类型（整数、浮点数、字符串）的默认比较函数
+func OrderedComparator[K cmp.Ordered]() Comparator[K] {
+	return cmp.Compare[K]
+}
+
+// ComparatorBy 按 field 取出的字段比较
+func ComparatorBy[K any, F cmp.Ordered](field func(K) F) Comparator[K] {
+	return func(a, b K) int {
+		return cmp.Compare(field(a), field(b))
+	}
+}
+
+// ChainComparators 多字段比较，依次使用 cmps 比较，前一个相等时才使用下一个
+func ChainComparators[K any](cmps ...Comparator[K]) Comparator[K] {
+	return func(a, b K) int {
+		for _, c := range cmps {
+			if r := c(a, b); r != 0 {
+				return r
+			}
+		}
+		return 0
+	}
+}
+
+// ReverseComparator 反转比较结果，用于降序排列
+func ReverseComparator[K any](c Comparator[K]) Comparator[K] {
+	return func(a, b K) int {
+		return c(b, a)
+	}
+}
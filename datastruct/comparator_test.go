@@ -0,0 +1,32 @@
+package datastruct
+
+import "testing"
+
+func TestComparator(t *testing.T) {
+	type pair struct {
+		a int
+		b string
+	}
+	cmp := ChainComparators(
+		ComparatorBy(func(p pair) int { return p.a }),
+		ReverseComparator(ComparatorBy(func(p pair) string { return p.b })),
+	)
+	cases := []struct {
+		x, y pair
+		want int
+	}{
+		{pair{1, "a"}, pair{2, "a"}, -1},
+		{pair{2, "a"}, pair{1, "z"}, 1},
+		{pair{1, "a"}, pair{1, "b"}, 1},
+		{pair{1, "b"}, pair{1, "a"}, -1},
+		{pair{1, "a"}, pair{1, "a"}, 0},
+	}
+	for _, c := range cases {
+		if got := cmp(c.x, c.y); got != c.want {
+			t.Errorf("cmp(%v, %v)=%d, 期望 %d", c.x, c.y, got, c.want)
+		}
+	}
+	if ChainComparators[int]()(1, 2) != 0 {
+		t.Error("没有比较函数时应返回 0")
+	}
+}
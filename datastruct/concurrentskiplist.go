@@ -0,0 +1,254 @@
+package datastruct
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// 无锁并发跳跃表，参考 Herlihy、Shavit《The Art of Multiprocessor Programming》中的 LockFreeSkipList
+//
+// 每一层的后继指针与该层的删除标记放在同一个不可变的 markedRef 中，通过 CAS 整体替换。
+// 删除分两步：先将 value CAS 为 nil（逻辑删除，删除的线性化点），再从上到下标记每一层，
+// 被标记的节点由之后经过的 find 通过 CAS 摘除（物理删除）
+
+// markedRef 带删除标记的后继指针，创建后不再修改
+type markedRef[K, V any] struct {
+	node   *concurrentNode[K, V]
+	marked bool // 持有该指针的节点在这一层已被删除
+}
+
+// concurrentNode 并发跳跃表节点
+type concurrentNode[K, V any] struct {
+	key   K
+	value atomic.Pointer[V]                 // 为 nil 时节点已被逻辑删除
+	next  []atomic.Pointer[markedRef[K, V]] // 每一层的后继指针
+}
+
+func newConcurrentNode[K, V any](key K, value *V, level int) *concurrentNode[K, V] {
+	node := &concurrentNode[K, V]{key: key, next: make([]atomic.Pointer[markedRef[K, V]], level)}
+	node.value.Store(value)
+	for i := range node.next {
+		node.next[i].Store(&markedRef[K, V]{})
+	}
+	return node
+}
+
+// casNext 第 level 层的后继为 (expect, expectMark) 时替换为 (update, updateMark)
+func (n *concurrentNode[K, V]) casNext(level int, expect *concurrentNode[K, V], expectMark bool, update *concurrentNode[K, V], updateMark bool) bool {
+	ref := n.next[level].Load()
+	if ref.node != expect || ref.marked != expectMark {
+		return false
+	}
+	return n.next[level].CompareAndSwap(ref, &markedRef[K, V]{node: update, marked: updateMark})
+}
+
+// mark 从上到下标记每一层，可以被多个协程重复调用
+func (n *concurrentNode[K, V]) mark() {
+	for i := len(n.next) - 1; i >= 0; i-- {
+		for ref := n.next[i].Load(); !ref.marked; ref = n.next[i].Load() {
+			n.next[i].CompareAndSwap(ref, &markedRef[K, V]{node: ref.node, marked: true})
+		}
+	}
+}
+
+// ConcurrentSkipList 无锁并发跳跃表，可以被多个协程同时读写
+//
+// Get、Put、Delete 是线性一致的；Len、Ascend、Range 是弱一致的，只反映遍历期间的某个中间状态
+type ConcurrentSkipList[K, V any] struct {
+	head   *concurrentNode[K, V] // 头节点，不保存 kv
+	cmp    Comparator[K]         // key 比较函数
+	level  atomic.Int32          // 当前使用的层数，只增不减
+	length atomic.Int64          // 节点数量
+}
+
+var _ OrderedMap[int, int] = (*ConcurrentSkipList[int, int])(nil)
+
+// NewConcurrentSkipList 新建并发跳跃表
+//
+//	@cmp key 的比较函数
+func NewConcurrentSkipList[K, V any](cmp Comparator[K]) *ConcurrentSkipList[K, V] {
+	if cmp == nil {
+		panic("ConcurrentSkipList comparator is nil")
+	}
+	l := &ConcurrentSkipList[K, V]{
+		head: newConcurrentNode[K, V](*new(K), nil, defaultMaxLevel),
+		cmp:  cmp,
+	}
+	l.level.Store(1)
+	return l
+}
+
+// randomLevel 通过概率计算新节点的层数，rand 的全局函数可以并发调用
+func (l *ConcurrentSkipList[K, V]) randomLevel() int {
+	level := 1
+	for level < defaultMaxLevel && rand.Float64() < defaultSkipLinkedP {
+		level++
+	}
+	return level
+}
+
+// find 查找 key，记录每一层最后一个 < key 的节点与其后继，途中摘除被标记的节点
+func (l *ConcurrentSkipList[K, V]) find(key K, preds, succs []*concurrentNode[K, V]) bool {
+retry:
+	pred := l.head
+	for i := int(l.level.Load()) - 1; i >= 0; i-- {
+		curr := pred.next[i].Load().node
+		for curr != nil {
+			ref := curr.next[i].Load()
+			for ref.marked { // curr 在这一层已被删除，将其摘除
+				if !pred.casNext(i, curr, false, ref.node, false) {
+					goto retry
+				}
+				if curr = ref.node; curr == nil {
+					break
+				}
+				ref = curr.next[i].Load()
+			}
+			if curr == nil || l.cmp(curr.key, key) >= 0 {
+				break
+			}
+			pred, curr = curr, ref.node
+		}
+		preds[i], succs[i] = pred, curr
+	}
+	return succs[0] != nil && l.cmp(succs[0].key, key) == 0
+}
+
+// search 不修改跳跃表地查找 key 所在的节点，跳过被标记的节点
+func (l *ConcurrentSkipList[K, V]) search(key K) *concurrentNode[K, V] {
+	pred := l.head
+	var curr *concurrentNode[K, V]
+	for i := int(l.level.Load()) - 1; i >= 0; i-- {
+		curr = pred.next[i].Load().node
+		for curr != nil {
+			ref := curr.next[i].Load()
+			if ref.marked {
+				curr = ref.node
+				continue
+			}
+			if l.cmp(curr.key, key) >= 0 {
+				break
+			}
+			pred, curr = curr, ref.node
+		}
+	}
+	if curr != nil && l.cmp(curr.key, key) == 0 {
+		return curr
+	}
+	return nil
+}
+
+// Len 节点数量，并发修改时只是近似值
+func (l *ConcurrentSkipList[K, V]) Len() int {
+	return int(l.length.Load())
+}
+
+// Get 查找 key 对应的 value
+func (l *ConcurrentSkipList[K, V]) Get(key K) (V, bool) {
+	if node := l.search(key); node != nil {
+		if value := node.value.Load(); value != nil {
+			return *value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Put 插入或更新 key，key 已存在时返回旧的 value 与 replaced=true
+func (l *ConcurrentSkipList[K, V]) Put(key K, value V) (old V, replaced bool) {
+	var preds, succs [defaultMaxLevel]*concurrentNode[K, V]
+	level := l.randomLevel()
+	for current := l.level.Load(); int(current) < level; current = l.level.Load() {
+		if l.level.CompareAndSwap(current, int32(level)) {
+			break
+		}
+	}
+
+	for {
+		if l.find(key, preds[:], succs[:]) {
+			node := succs[0]
+			if prev := node.value.Load(); prev == nil {
+				node.mark() // 节点正在被删除，帮助标记后重新查找，由 find 摘除
+			} else if node.value.CompareAndSwap(prev, &value) {
+				return *prev, true
+			}
+			continue
+		}
+
+		node := newConcurrentNode(key, &value, level)
+		for i := 0; i < level; i++ {
+			node.next[i].Store(&markedRef[K, V]{node: succs[i]})
+		}
+		// 链入最底层即插入成功
+		if !preds[0].casNext(0, succs[0], false, node, false) {
+			continue
+		}
+		l.length.Add(1)
+		l.linkLevels(node, level, preds[:], succs[:])
+		return
+	}
+}
+
+// linkLevels 将已经链入最底层的 node 依次链入上面的层，node 被删除时停止
+func (l *ConcurrentSkipList[K, V]) linkLevels(node *concurrentNode[K, V], level int, preds, succs []*concurrentNode[K, V]) {
+	for i := 1; i < level; i++ {
+		for {
+			// 重新查找后 node 在这一层的后继可能变化
+			ref := node.next[i].Load()
+			if ref.marked {
+				return
+			}
+			if ref.node != succs[i] && !node.casNext(i, ref.node, false, succs[i], false) {
+				continue
+			}
+			if preds[i].casNext(i, succs[i], false, node, false) {
+				break
+			}
+			l.find(node.key, preds, succs)
+		}
+	}
+}
+
+// Delete 删除 key，返回被删除的 value
+func (l *ConcurrentSkipList[K, V]) Delete(key K) (V, bool) {
+	var preds, succs [defaultMaxLevel]*concurrentNode[K, V]
+	for l.find(key, preds[:], succs[:]) {
+		node := succs[0]
+		value := node.value.Load()
+		if value == nil { // 已被其他协程删除
+			break
+		}
+		if node.value.CompareAndSwap(value, nil) {
+			l.length.Add(-1)
+			node.mark()
+			l.find(key, preds[:], succs[:]) // 摘除节点
+			return *value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// Ascend 按 key 升序遍历，fn 返回 false 时停止
+func (l *ConcurrentSkipList[K, V]) Ascend(fn func(key K, value V) bool) {
+	l.ascend(l.head.next[0].Load().node, nil, fn)
+}
+
+// Range 按 key 升序遍历 [lo, hi) 区间，fn 返回 false 时停止
+func (l *ConcurrentSkipList[K, V]) Range(lo, hi K, fn func(key K, value V) bool) {
+	var preds, succs [defaultMaxLevel]*concurrentNode[K, V]
+	l.find(lo, preds[:], succs[:])
+	l.ascend(succs[0], &hi, fn)
+}
+
+// ascend 从 node 开始沿最底层遍历到 hi（不包含），跳过已删除的节点
+func (l *ConcurrentSkipList[K, V]) ascend(node *concurrentNode[K, V], hi *K, fn func(key K, value V) bool) {
+	for ; node != nil; node = node.next[0].Load().node {
+		if hi != nil && l.cmp(node.key, *hi) >= 0 {
+			return
+		}
+		if value := node.value.Load(); value != nil && !fn(node.key, *value) {
+			return
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package datastruct
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// 并发读写，每个协程只修改自己的 key，结束后与各自的参考实现对比
+func TestConcurrentSkipListDisjoint(t *testing.T) {
+	const (
+		workers = 8
+		keys    = 500
+		ops     = 5000
+	)
+	l := NewConcurrentSkipList[int, int](OrderedComparator[int]())
+	refs := make([]map[int]int, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		refs[w] = make(map[int]int)
+		wg.Add(1)
+		go func(w int, ref map[int]int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < ops; i++ {
+				key := r.Intn(keys)*workers + w // 协程 w 只使用模 workers 余 w 的 key
+				want, exist := ref[key]
+				switch r.Intn(3) {
+				case 0:
+					if v, ok := l.Delete(key); ok != exist || v != want {
+						t.Errorf("Delete(%d)=%d,%v, 期望 %d,%v", key, v, ok, want, exist)
+						return
+					}
+					delete(ref, key)
+				case 1:
+					if old, ok := l.Put(key, i); ok != exist || old != want {
+						t.Errorf("Put(%d)=%d,%v, 期望 %d,%v", key, old, ok, want, exist)
+						return
+					}
+					ref[key] = i
+				default:
+					if v, ok := l.Get(key); ok != exist || v != want {
+						t.Errorf("Get(%d)=%d,%v, 期望 %d,%v", key, v, ok, want, exist)
+						return
+					}
+				}
+			}
+		}(w, refs[w])
+	}
+	wg.Wait()
+
+	total := 0
+	for _, ref := range refs {
+		total += len(ref)
+	}
+	if l.Len() != total {
+		t.Fatalf("Len=%d, 期望 %d", l.Len(), total)
+	}
+	prev, count := -1, 0
+	l.Ascend(func(k, v int) bool {
+		if k <= prev {
+			t.Fatalf("遍历顺序错误 %d <= %d", k, prev)
+		}
+		if want, ok := refs[k%workers][k]; !ok || want != v {
+			t.Fatalf("key %d 的 value 为 %d, 期望 %d,%v", k, v, want, ok)
+		}
+		prev, count = k, count+1
+		return true
+	})
+	if count != total {
+		t.Fatalf("遍历到 %d 个 kv, 期望 %d", count, total)
+	}
+	checkConcurrentSkipList(t, l)
+}
+
+// 多个协程同时修改少量相同的 key，同时有协程遍历
+func TestConcurrentSkipListContended(t *testing.T) {
+	const (
+		workers = 8
+		keys    = 16
+		ops     = 20000
+	)
+	l := NewConcurrentSkipList[int, int](OrderedComparator[int]())
+	var (
+		wg   sync.WaitGroup
+		done atomic.Bool
+		puts atomic.Int64 // 新插入的数量
+		dels atomic.Int64 // 删除成功的数量
+	)
+	wg.Add(1)
+	go func() { // 遍历的结果始终有序
+		defer wg.Done()
+		for !done.Load() {
+			prev := -1
+			l.Ascend(func(k, v int) bool {
+				if k <= prev {
+					t.Errorf("遍历顺序错误 %d <= %d", k, prev)
+					return false
+				}
+				prev = k
+				return true
+			})
+		}
+	}()
+	var workerWg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		workerWg.Add(1)
+		go func(w int) {
+			defer workerWg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < ops; i++ {
+				key := r.Intn(keys)
+				switch r.Intn(3) {
+				case 0:
+					if _, ok := l.Delete(key); ok {
+						dels.Add(1)
+					}
+				case 1:
+					if _, replaced := l.Put(key, w); !replaced {
+						puts.Add(1)
+					}
+				default:
+					if v, ok := l.Get(key); ok && (v < 0 || v >= workers) {
+						t.Errorf("Get(%d)=%d, 不是任何协程写入的值", key, v)
+					}
+				}
+			}
+		}(w)
+	}
+	workerWg.Wait()
+	done.Store(true)
+	wg.Wait()
+
+	count := 0
+	l.Ascend(func(k, v int) bool {
+		count++
+		return true
+	})
+	if want := int(puts.Load() - dels.Load()); l.Len() != want || count != want {
+		t.Fatalf("Len=%d, 遍历到 %d 个, 期望 %d", l.Len(), count, want)
+	}
+	checkConcurrentSkipList(t, l)
+}
+
+// checkConcurrentSkipList 静止状态下检查每一层有序、没有被标记或已删除的节点，且上层的节点都在最底层
+func checkConcurrentSkipList[K, V any](t *testing.T, l *ConcurrentSkipList[K, V]) {
+	t.Helper()
+	bottom := make(map[*concurrentNode[K, V]]bool)
+	for i := 0; i < int(l.level.Load()); i++ {
+		prev := l.head
+		for ref := l.head.next[i].Load(); ref.node != nil; ref = ref.node.next[i].Load() {
+			node := ref.node
+			if node.next[i].Load().marked || node.value.Load() == nil {
+				t.Fatalf("第 %d 层存在已删除的节点 %v", i, node.key)
+			}
+			if prev != l.head && l.cmp(prev.key, node.key) >= 0 {
+				t.Fatalf("第 %d 层顺序错误 %v >= %v", i, prev.key, node.key)
+			}
+			if i == 0 {
+				bottom[node] = true
+			} else if !bottom[node] {
+				t.Fatalf("第 %d 层的节点 %v 不在最底层", i, node.key)
+			}
+			prev = node
+		}
+	}
+	if len(bottom) != l.Len() {
+		t.Fatalf("最底层有 %d 个节点, Len=%d", len(bottom), l.Len())
+	}
+}
+
+// ------------------------------- 线性一致性检查 ---------------------------------
+
+// historyOp 一次操作的调用与返回
+type historyOp struct {
+	kind      int // opGet、opPut、opDelete
+	key       int
+	arg       int // Put 写入的 value
+	value     int // 返回的 value
+	ok        bool
+	call, ret int64 // 调用与返回的逻辑时间
+	goroutine int   // 发起操作的协程，仅用于输出
+}
+
+const (
+	opGet = iota
+	opPut
+	opDelete
+)
+
+func (op historyOp) String() string {
+	name := [...]string{"Get", "Put", "Delete"}[op.kind]
+	return fmt.Sprintf("g%d %s(%d, %d)=%d,%v [%d,%d]", op.goroutine, name, op.key, op.arg, op.value, op.ok, op.call, op.ret)
+}
+
+// registerState 单个 key 的状态
+type registerState struct {
+	value   int
+	present bool
+}
+
+// step 在 state 上执行 op，返回值与 op 记录的一致时返回新的状态
+func (s registerState) step(op historyOp) (registerState, bool) {
+	if op.ok != s.present || (s.present && op.value != s.value) {
+		return s, false
+	}
+	switch op.kind {
+	case opPut:
+		return registerState{value: op.arg, present: true}, true
+	case opDelete:
+		return registerState{}, true
+	}
+	return s, true
+}
+
+// linearizable 检查单个 key 的历史是否线性一致（Wing & Gong 回溯搜索，记忆化已搜索过的状态）
+//
+// 不同 key 是相互独立的对象，由线性一致性的局部性，每个 key 都线性一致时整个历史线性一致
+func linearizable(ops []historyOp) bool {
+	if len(ops) > 64 {
+		panic("too many operations")
+	}
+	type visit struct {
+		done  uint64
+		state registerState
+	}
+	visited := make(map[visit]bool)
+	all := uint64(1)<<len(ops) - 1
+	var search func(done uint64, state registerState) bool
+	search = func(done uint64, state registerState) bool {
+		if done == all {
+			return true
+		}
+		if visited[visit{done, state}] {
+			return false
+		}
+		visited[visit{done, state}] = true
+		// 只有在所有未线性化的操作返回之前调用的操作才能作为下一个
+		minRet := int64(1<<63 - 1)
+		for i, op := range ops {
+			if done&(1<<i) == 0 {
+				minRet = min(minRet, op.ret)
+			}
+		}
+		for i, op := range ops {
+			if done&(1<<i) != 0 || op.call > minRet {
+				continue
+			}
+			if next, ok := state.step(op); ok && search(done|1<<i, next) {
+				return true
+			}
+		}
+		return false
+	}
+	return search(0, registerState{})
+}
+
+func TestLinearizableChecker(t *testing.T) {
+	// 顺序执行：Put(1) 后 Get 读到旧值，不是线性一致的
+	bad := []historyOp{
+		{kind: opPut, arg: 1, call: 1, ret: 2},
+		{kind: opGet, value: 0, ok: false, call: 3, ret: 4},
+	}
+	if linearizable(bad) {
+		t.Fatal("应检查出不线性一致的历史")
+	}
+	// 并发执行：Get 可以排在 Put 之前
+	good := []historyOp{
+		{kind: opPut, arg: 1, call: 1, ret: 4},
+		{kind: opGet, value: 0, ok: false, call: 2, ret: 3},
+		{kind: opDelete, value: 1, ok: true, call: 5, ret: 6},
+	}
+	if !linearizable(good) {
+		t.Fatal("线性一致的历史被误判")
+	}
+	// 两个 Delete 都删除成功
+	twice := []historyOp{
+		{kind: opPut, arg: 1, call: 1, ret: 2},
+		{kind: opDelete, value: 1, ok: true, call: 3, ret: 6},
+		{kind: opDelete, value: 1, ok: true, call: 4, ret: 5},
+	}
+	if linearizable(twice) {
+		t.Fatal("同一个值不能被删除两次")
+	}
+}
+
+func TestConcurrentSkipListLinearizable(t *testing.T) {
+	const (
+		rounds  = 300
+		workers = 4
+		keys    = 3
+		ops     = 12 // 每个协程每轮的操作数，每个 key 的历史不超过 64 个操作
+	)
+	for round := 0; round < rounds; round++ {
+		l := NewConcurrentSkipList[int, int](OrderedComparator[int]())
+		var (
+			clock   atomic.Int64
+			wg      sync.WaitGroup
+			history = make([][]historyOp, workers)
+		)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				r := rand.New(rand.NewSource(int64(round*workers + w)))
+				for i := 0; i < ops; i++ {
+					op := historyOp{kind: r.Intn(3), key: r.Intn(keys), arg: w*ops + i + 1, goroutine: w}
+					op.call = clock.Add(1)
+					switch op.kind {
+					case opGet:
+						op.value, op.ok = l.Get(op.key)
+					case opPut:
+						op.value, op.ok = l.Put(op.key, op.arg)
+					case opDelete:
+						op.value, op.ok = l.Delete(op.key)
+					}
+					op.ret = clock.Add(1)
+					history[w] = append(history[w], op)
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		byKey := make([][]historyOp, keys)
+		for _, ops := range history {
+			for _, op := range ops {
+				byKey[op.key] = append(byKey[op.key], op)
+			}
+		}
+		for key, ops := range byKey {
+			if !linearizable(ops) {
+				t.Fatalf("第 %d 轮 key %d 的历史不是线性一致的: %v", round, key, ops)
+			}
+		}
+	}
+}
+
+func BenchmarkConcurrentSkipListParallel(b *testing.B) {
+	l := NewConcurrentSkipList[int, int](OrderedComparator[int]())
+	for i := 0; i < 1<<16; i += 2 {
+		l.Put(i, i)
+	}
+	var seed atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(seed.Add(1)))
+		for pb.Next() {
+			key := r.Intn(1 << 16)
+			switch r.Intn(10) {
+			case 0:
+				l.Put(key, key)
+			case 1:
+				l.Delete(key)
+			default:
+				l.Get(key)
+			}
+		}
+	})
+}
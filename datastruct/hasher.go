@@ -0,0 +1,126 @@
+package datastruct
+
+import (
+	"github.com/OneOfOne/xxhash"
+	"hash/maphash"
+	"reflect"
+	"unsafe"
+)
+
+// Hasher 带种子的哈希函数，相同的 seed 与 key 必须得到相同的哈希值，相等的 key 必须得到相同的哈希值
+//
+// 哈希表在创建时随机生成 seed，攻击者无法预先构造大量冲突的 key（hash flooding）
+type Hasher[K any] func(seed uint64, key K) uint64
+
+// mixHash 将 64 位整数打散（splitmix64 的终结函数），是双射，不会引入冲突
+func mixHash(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// StringHasher 字符串的哈希函数（xxhash）
+func StringHasher[K ~string]() Hasher[K] {
+	return func(seed uint64, key K) uint64 {
+		return xxhash.ChecksumString64S(string(key), seed)
+	}
+}
+
+// IntegerHasher 整数的哈希函数
+func IntegerHasher[K Integer]() Hasher[K] {
+	return func(seed uint64, key K) uint64 {
+		return mixHash(uint64(key) + seed)
+	}
+}
+
+// PointerHasher 指针的哈希函数，按地址而不是指向的内容计算哈希值
+func PointerHasher[T any]() Hasher[*T] {
+	return func(seed uint64, key *T) uint64 {
+		return mixHash(uint64(uintptr(unsafe.Pointer(key))) + seed)
+	}
+}
+
+// ByteArrayHasher 字节数组（如 [16]byte、[32]byte）的哈希函数，K 不是字节数组时 panic
+func ByteArrayHasher[K comparable]() Hasher[K] {
+	t := reflect.TypeFor[K]()
+	if t.Kind() != reflect.Array || t.Elem().Kind() != reflect.Uint8 {
+		panic("ByteArrayHasher: " + t.String() + " is not a byte array")
+	}
+	return memoryHasher[K]()
+}
+
+// memoryHasher 直接对 key 的内存计算哈希值，只能用于没有填充字节、指针、浮点数的类型
+func memoryHasher[K any]() Hasher[K] {
+	size := int(reflect.TypeFor[K]().Size())
+	return func(seed uint64, key K) uint64 {
+		return xxhash.Checksum64S(unsafe.Slice((*byte)(unsafe.Pointer(&key)), size), seed)
+	}
+}
+
+// HasherBy 按 field 取出的字段计算哈希值
+func HasherBy[K, F any](field func(K) F, h Hasher[F]) Hasher[K] {
+	return func(seed uint64, key K) uint64 {
+		return h(seed, field(key))
+	}
+}
+
+// CombineHashers 组合多个哈希函数，用于结构体 key，前一个的结果作为下一个的 seed
+//
+//	h := CombineHashers(
+//		HasherBy(func(k Key) string { return k.Name }, StringHasher[string]()),
+//		HasherBy(func(k Key) int { return k.ID }, IntegerHasher[int]()),
+//	)
+func CombineHashers[K any](hashers ...Hasher[K]) Hasher[K] {
+	return func(seed uint64, key K) uint64 {
+		for _, h := range hashers {
+			seed = h(seed, key)
+		}
+		return seed
+	}
+}
+
+// DefaultHasher 根据 K 的底层类型选择哈希函数
+//
+// 字符串、整数、布尔、指针、通道与字节数组使用专门的哈希函数，指针与通道按地址计算；
+// 其他类型（结构体、接口、浮点数等）使用 maphash.Comparable，与内置 map 的相等语义一致
+func DefaultHasher[K comparable]() Hasher[K] {
+	t := reflect.TypeFor[K]()
+	switch t.Kind() {
+	case reflect.String:
+		return func(seed uint64, key K) uint64 {
+			return xxhash.ChecksumString64S(*(*string)(unsafe.Pointer(&key)), seed)
+		}
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Pointer, reflect.UnsafePointer, reflect.Chan:
+		switch t.Size() {
+		case 1:
+			return func(seed uint64, key K) uint64 {
+				return mixHash(uint64(*(*uint8)(unsafe.Pointer(&key))) + seed)
+			}
+		case 2:
+			return func(seed uint64, key K) uint64 {
+				return mixHash(uint64(*(*uint16)(unsafe.Pointer(&key))) + seed)
+			}
+		case 4:
+			return func(seed uint64, key K) uint64 {
+				return mixHash(uint64(*(*uint32)(unsafe.Pointer(&key))) + seed)
+			}
+		case 8:
+			return func(seed uint64, key K) uint64 {
+				return mixHash(*(*uint64)(unsafe.Pointer(&key)) + seed)
+			}
+		}
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return memoryHasher[K]()
+		}
+	}
+	mapSeed := maphash.MakeSeed()
+	return func(seed uint64, key K) uint64 {
+		return mixHash(maphash.Comparable(mapSeed, key) + seed)
+	}
+}
@@ -0,0 +1,200 @@
+package datastruct
+
+import (
+	"strconv"
+	"testing"
+)
+
+type hasherKey struct {
+	name string
+	id   int
+}
+
+type hasherName string
+
+func TestHasher(t *testing.T) {
+	const seed = 12345
+	t.Run("相等的 key 哈希值相同，不同种子哈希值不同", func(t *testing.T) {
+		s := DefaultHasher[string]()
+		a, b := "hello", string([]byte("hello"))
+		if s(seed, a) != s(seed, b) || s(seed, a) == s(seed+1, a) {
+			t.Fatal("字符串哈希值错误")
+		}
+		if DefaultHasher[hasherName]()(seed, "hello") != StringHasher[string]()(seed, "hello") {
+			t.Fatal("自定义字符串类型应与 string 的哈希值相同")
+		}
+		if DefaultHasher[int]()(seed, -1) != IntegerHasher[int]()(seed, -1) {
+			t.Fatal("DefaultHasher 与 IntegerHasher 的哈希值不同")
+		}
+		if DefaultHasher[int8]()(seed, -1) == DefaultHasher[int8]()(seed, 1) {
+			t.Fatal("int8 哈希值冲突")
+		}
+	})
+	t.Run("指针按地址计算哈希值", func(t *testing.T) {
+		p, q := &hasherKey{"a", 1}, &hasherKey{"a", 1}
+		for _, h := range []Hasher[*hasherKey]{DefaultHasher[*hasherKey](), PointerHasher[hasherKey]()} {
+			if h(seed, p) != h(seed, p) || h(seed, p) == h(seed, q) {
+				t.Fatal("指针哈希值应只与地址有关")
+			}
+		}
+	})
+	t.Run("字节数组", func(t *testing.T) {
+		h := ByteArrayHasher[[16]byte]()
+		a, b := [16]byte{1, 2, 3}, [16]byte{1, 2, 3}
+		if h(seed, a) != h(seed, b) || h(seed, a) == h(seed, [16]byte{1, 2, 4}) {
+			t.Fatal("字节数组哈希值错误")
+		}
+		if DefaultHasher[[16]byte]()(seed, a) != h(seed, a) {
+			t.Fatal("DefaultHasher 与 ByteArrayHasher 的哈希值不同")
+		}
+		defer func() {
+			if recover() == nil {
+				t.Fatal("非字节数组应 panic")
+			}
+		}()
+		ByteArrayHasher[[2]int]()
+	})
+	t.Run("其他类型", func(t *testing.T) {
+		type key struct {
+			f float64
+			v any
+		}
+		h := DefaultHasher[key]()
+		// +0 与 -0 相等，哈希值也必须相同
+		negZero := 0.0
+		negZero = -negZero
+		if h(seed, key{0, "x"}) != h(seed, key{negZero, "x"}) || h(seed, key{0, "x"}) == h(seed, key{0, "y"}) {
+			t.Fatal("结构体哈希值错误")
+		}
+	})
+	t.Run("组合", func(t *testing.T) {
+		h := CombineHashers(
+			HasherBy(func(k hasherKey) string { return k.name }, StringHasher[string]()),
+			HasherBy(func(k hasherKey) int { return k.id }, IntegerHasher[int]()),
+		)
+		if h(seed, hasherKey{"a", 1}) != h(seed, hasherKey{"a", 1}) ||
+			h(seed, hasherKey{"a", 1}) == h(seed, hasherKey{"a", 2}) ||
+			h(seed, hasherKey{"a", 1}) == h(seed, hasherKey{"b", 1}) {
+			t.Fatal("组合哈希值错误")
+		}
+	})
+}
+
+func TestHasherNoAllocation(t *testing.T) {
+	key := hasherKey{"name", 1}
+	combined := CombineHashers(
+		HasherBy(func(k hasherKey) string { return k.name }, StringHasher[string]()),
+		HasherBy(func(k hasherKey) int { return k.id }, IntegerHasher[int]()),
+	)
+	hashers := map[string]func(){
+		"string":    func() { stringHasher(1, "hello") },
+		"int":       func() { intHasher(1, 42) },
+		"uint16":    func() { uint16Hasher(1, 42) },
+		"pointer":   func() { pointerHasher(1, &key) },
+		"[32]byte":  func() { arrayHasher(1, [32]byte{1}) },
+		"composite": func() { combined(1, key) },
+	}
+	for name, fn := range hashers {
+		if allocs := testing.AllocsPerRun(100, fn); allocs != 0 {
+			t.Errorf("%s 的哈希函数每次分配 %v 次", name, allocs)
+		}
+	}
+}
+
+var (
+	stringHasher  = DefaultHasher[string]()
+	intHasher     = DefaultHasher[int]()
+	uint16Hasher  = DefaultHasher[uint16]()
+	pointerHasher = DefaultHasher[*hasherKey]()
+	arrayHasher   = DefaultHasher[[32]byte]()
+)
+
+// 连续的整数应均匀地分布在低位
+func TestHasherDistribution(t *testing.T) {
+	const (
+		keys    = 1 << 16
+		buckets = 1 << 10
+	)
+	h := DefaultHasher[int]()
+	counts := make([]int, buckets)
+	for i := 0; i < keys; i++ {
+		counts[h(0, i*buckets)&(buckets-1)]++ // 低位全为 0 的 key
+	}
+	for i, c := range counts {
+		// 期望每个桶 64 个，允许较大的偏差
+		if c < 16 || c > 160 {
+			t.Fatalf("第 %d 个桶有 %d 个 key, 分布不均匀", i, c)
+		}
+	}
+}
+
+func TestHashMapWithHasher(t *testing.T) {
+	t.Run("结构体 key", func(t *testing.T) {
+		hasher := CombineHashers(
+			HasherBy(func(k hasherKey) string { return k.name }, StringHasher[string]()),
+			HasherBy(func(k hasherKey) int { return k.id }, IntegerHasher[int]()),
+		)
+		hm := NewHashMap2[hasherKey, int](WithHasher[hasherKey, int](hasher), WithHashSeed[hasherKey, int](1))
+		sm := NewSwissMap[hasherKey, int](WithSwissMapHasher[hasherKey, int](hasher), WithSwissMapHashSeed[hasherKey, int](1))
+		for i := 0; i < 1000; i++ {
+			hm.Put(hasherKey{strconv.Itoa(i % 10), i}, i)
+			sm.Put(hasherKey{strconv.Itoa(i % 10), i}, i)
+		}
+		for i := 0; i < 1000; i++ {
+			v1, ok1 := hm.Get(hasherKey{strconv.Itoa(i % 10), i})
+			v2, ok2 := sm.Get(hasherKey{strconv.Itoa(i % 10), i})
+			if !ok1 || !ok2 || v1 != i || v2 != i {
+				t.Fatalf("Get(%d)=%d,%v %d,%v", i, v1, ok1, v2, ok2)
+			}
+		}
+	})
+	t.Run("内容相同的指针是不同的 key", func(t *testing.T) {
+		p, q := &hasherKey{"a", 1}, &hasherKey{"a", 1}
+		hm := NewHashMap2[*hasherKey, int]()
+		sm := NewSwissMap[*hasherKey, int]()
+		hm.Put(p, 1)
+		sm.Put(p, 1)
+		if _, ok := hm.Get(q); ok {
+			t.Fatal("HashMap2 不应找到另一个指针")
+		}
+		if _, ok := sm.Get(q); ok {
+			t.Fatal("SwissMap 不应找到另一个指针")
+		}
+		hm.Put(q, 2)
+		sm.Put(q, 2)
+		if hm.Len() != 2 || sm.Len() != 2 {
+			t.Fatalf("Len=%d %d, 期望 2", hm.Len(), sm.Len())
+		}
+	})
+}
+
+func BenchmarkHasher(b *testing.B) {
+	key := hasherKey{"benchmark-key", 42}
+	combined := CombineHashers(
+		HasherBy(func(k hasherKey) string { return k.name }, StringHasher[string]()),
+		HasherBy(func(k hasherKey) int { return k.id }, IntegerHasher[int]()),
+	)
+	structHasher := DefaultHasher[hasherKey]()
+	b.Run("string", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			stringHasher(1, key.name)
+		}
+	})
+	b.Run("int", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			intHasher(1, i)
+		}
+	})
+	b.Run("struct/combined", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			combined(1, key)
+		}
+	})
+	b.Run("struct/default", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			structHasher(1, key)
+		}
+	})
+}
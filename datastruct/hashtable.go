@@ -10,9 +10,10 @@ import (
 
 const (
 	// 扩容因子
-	HASHMAP_LOAD_FACTOR  = 0.7 // 扩容因子
-	HASHMAP_DEFAULT_SIZE = 16  // 默认大小
-	REHASH_STEP          = 10  // 每次操作迁移的节点数量
+	HASHMAP_LOAD_FACTOR   = 0.7 // 扩容因子
+	HASHMAP_DEFAULT_SIZE  = 16  // 默认大小
+	REHASH_STEP           = 10  // 每次操作迁移的节点数量
+	HASHMAP_SHRINK_FACTOR = 0.1 // 缩容因子
 )
 
 // 哈希表
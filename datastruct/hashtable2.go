@@ -1,12 +1,11 @@
 package datastruct
 
 import (
-	"encoding/json"
-	"fmt"
-	"github.com/OneOfOne/xxhash"
-	"reflect"
+	"iter"
+	"math/rand"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // 每个桶包含独立的锁和节点链表
@@ -22,28 +21,55 @@ type hashMapNode[U comparable, T any] struct {
 }
 
 type HashMap2[U comparable, T any] struct {
-	buckets       []*bucket[U, T]    // 新桶数组
-	oldBuckets    []*bucket[U, T]    // 旧桶数组（扩容时使用）
-	capacity      int32              // 当前容量（2的幂次）
-	size          int32              // 元素数量
-	capacityMask  int32              // 容量掩码 (capacity-1)
-	hashAlgorithm func(key U) uint64 // 哈希算法
-	rehashIndex   int32              // 扩容迁移索引
-	resizingNum   int32              // 正在迁移桶数
-	isResizing    atomic.Bool        // 扩容状态标记
-	globalLock    sync.RWMutex       // 仅用于保护扩容元数据
+	buckets      []*bucket[U, T] // 新桶数组
+	oldBuckets   []*bucket[U, T] // 旧桶数组（扩容时使用）
+	capacity     int32           // 当前容量（2的幂次）
+	size         int32           // 元素数量
+	capacityMask int32           // 容量掩码 (capacity-1)
+	hasher       Hasher[U]       // 哈希函数
+	seed         uint64          // 哈希种子，默认每个 map 随机生成
+	rehashIndex  int32           // 扩容迁移索引
+	resizingNum  int32           // 正在迁移桶数
+	isResizing   atomic.Bool     // 扩容状态标记
+	globalLock   sync.RWMutex    // 仅用于保护扩容元数据
+
+	loadFactor     float64       // 元素数量超过 容量*loadFactor 时扩容
+	shrinkFactor   float64       // 元素数量低于 容量*shrinkFactor 时缩容，0 表示不缩容
+	rehashStep     int           // 每次迁移的桶数量
+	minCapacity    int32         // 缩容后的最小容量
+	rehashInterval time.Duration // 后台迁移的间隔，0 表示不启动后台迁移
+	stop           chan struct{} // 关闭后通知后台迁移协程退出
+	done           chan struct{} // 后台迁移协程退出后关闭
+	closeOnce      sync.Once
 }
 
 type HashMapOption[U comparable, T any] func(*HashMap2[U, T])
 
+// WithHashAlgorithm 设置不使用种子的哈希算法
 func WithHashAlgorithm[U comparable, T any](hashAlgorithm func(key U) uint64) HashMapOption[U, T] {
 	return func(m *HashMap2[U, T]) {
 		if hashAlgorithm != nil {
-			m.hashAlgorithm = hashAlgorithm
+			m.hasher = func(_ uint64, key U) uint64 { return hashAlgorithm(key) }
 		}
 	}
 }
 
+// WithHasher 设置哈希函数（默认为 DefaultHasher）
+func WithHasher[U comparable, T any](hasher Hasher[U]) HashMapOption[U, T] {
+	return func(m *HashMap2[U, T]) {
+		if hasher != nil {
+			m.hasher = hasher
+		}
+	}
+}
+
+// WithHashSeed 设置固定的哈希种子（默认随机生成），用于复现问题
+func WithHashSeed[U comparable, T any](seed uint64) HashMapOption[U, T] {
+	return func(m *HashMap2[U, T]) {
+		m.seed = seed
+	}
+}
+
 func WithInitialCapacity[U comparable, T any](capacity int) HashMapOption[U, T] {
 	return func(m *HashMap2[U, T]) {
 		if capacity > 0 {
@@ -62,11 +88,55 @@ func WithInitialCapacity[U comparable, T any](capacity int) HashMapOption[U, T]
 	}
 }
 
+// WithLoadFactor 设置扩容的负载因子（默认为 HASHMAP_LOAD_FACTOR），元素数量超过 容量*loadFactor 时容量翻倍
+func WithLoadFactor[U comparable, T any](loadFactor float64) HashMapOption[U, T] {
+	return func(m *HashMap2[U, T]) {
+		if loadFactor > 0 {
+			m.loadFactor = loadFactor
+		}
+	}
+}
+
+// WithShrinkThreshold 设置缩容阈值（默认为 HASHMAP_SHRINK_FACTOR），0 表示不缩容
+//
+// 元素数量低于 容量*threshold 时缩容到负载为 loadFactor/2 的容量，但不低于初始容量。
+// threshold 不小于 loadFactor/2 时缩容后会立即再次扩容，此时使用 loadFactor/4
+func WithShrinkThreshold[U comparable, T any](threshold float64) HashMapOption[U, T] {
+	return func(m *HashMap2[U, T]) {
+		if threshold >= 0 {
+			m.shrinkFactor = threshold
+		}
+	}
+}
+
+// WithRehashStep 设置每次操作迁移的桶数量（默认为 REHASH_STEP）
+func WithRehashStep[U comparable, T any](step int) HashMapOption[U, T] {
+	return func(m *HashMap2[U, T]) {
+		if step > 0 {
+			m.rehashStep = step
+		}
+	}
+}
+
+// WithBackgroundRehash 启动后台协程，每隔 interval 迁移一批桶并检查是否需要扩缩容，
+// 没有读写的 map 也能完成迁移。不再使用时需要调用 Close 停止协程
+func WithBackgroundRehash[U comparable, T any](interval time.Duration) HashMapOption[U, T] {
+	return func(m *HashMap2[U, T]) {
+		if interval > 0 {
+			m.rehashInterval = interval
+		}
+	}
+}
+
 func NewHashMap2[U comparable, T any](options ...HashMapOption[U, T]) *HashMap2[U, T] {
 	hashMap := &HashMap2[U, T]{
-		capacity:      int32(HASHMAP_DEFAULT_SIZE),
-		capacityMask:  int32(HASHMAP_DEFAULT_SIZE - 1),
-		hashAlgorithm: defaultHashAlgorithm[U],
+		capacity:     int32(HASHMAP_DEFAULT_SIZE),
+		capacityMask: int32(HASHMAP_DEFAULT_SIZE - 1),
+		hasher:       DefaultHasher[U](),
+		seed:         rand.Uint64(),
+		loadFactor:   HASHMAP_LOAD_FACTOR,
+		shrinkFactor: HASHMAP_SHRINK_FACTOR,
+		rehashStep:   REHASH_STEP,
 	}
 
 	// 初始化默认桶
@@ -80,52 +150,45 @@ func NewHashMap2[U comparable, T any](options ...HashMapOption[U, T]) *HashMap2[
 	for _, option := range options {
 		option(hashMap)
 	}
+	hashMap.minCapacity = hashMap.capacity
+	if hashMap.shrinkFactor*2 >= hashMap.loadFactor {
+		hashMap.shrinkFactor = hashMap.loadFactor / 4
+	}
+	if hashMap.rehashInterval > 0 {
+		hashMap.stop = make(chan struct{})
+		hashMap.done = make(chan struct{})
+		go hashMap.backgroundRehash()
+	}
 
 	return hashMap
 }
 
-func defaultHashAlgorithm[T comparable](key T) uint64 {
-	h := xxhash.New64()
-
-	val := reflect.ValueOf(key)
-	switch val.Kind() {
-	case reflect.String:
-		h.WriteString(val.String())
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		num := uint64(val.Int())
-		h.Write([]byte{
-			byte(num >> 56), byte(num >> 48), byte(num >> 40), byte(num >> 32),
-			byte(num >> 24), byte(num >> 16), byte(num >> 8), byte(num),
-		})
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		num := val.Uint()
-		h.Write([]byte{
-			byte(num >> 56), byte(num >> 48), byte(num >> 40), byte(num >> 32),
-			byte(num >> 24), byte(num >> 16), byte(num >> 8), byte(num),
-		})
-	case reflect.Bool:
-		if val.Bool() {
-			h.Write([]byte("1"))
-		} else {
-			h.Write([]byte("0"))
-		}
-	case reflect.Ptr, reflect.Struct, reflect.Array, reflect.Slice:
-		if bytes, err := json.Marshal(key); err == nil {
-			h.Write(bytes)
-		} else {
-			addr := val.UnsafeAddr()
-			h.WriteString(reflect.TypeOf(key).String())
-			h.Write([]byte{
-				byte(addr >> 56), byte(addr >> 48), byte(addr >> 40), byte(addr >> 32),
-				byte(addr >> 24), byte(addr >> 16), byte(addr >> 8), byte(addr),
-			})
+// backgroundRehash 后台迁移协程
+func (m *HashMap2[U, T]) backgroundRehash() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.rehashInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.globalLock.Lock()
+			m.rehash()
+			m.tryResize()
+			m.globalLock.Unlock()
 		}
-	default:
-		h.WriteString(reflect.TypeOf(key).String())
-		h.WriteString(fmt.Sprintf("%v", key))
 	}
+}
 
-	return h.Sum64()
+// Close 停止后台迁移协程，可以重复调用，之后 map 仍然可以读写，只是不再后台迁移
+func (m *HashMap2[U, T]) Close() {
+	m.closeOnce.Do(func() {
+		if m.stop != nil {
+			close(m.stop)
+			<-m.done
+		}
+	})
 }
 
 func pow2(n int) int {
@@ -145,7 +208,7 @@ func pow2(n int) int {
 // 计算新桶索引（带边界检查）
 func (m *HashMap2[U, T]) hashIndex(key U) int {
 	mask := atomic.LoadInt32(&m.capacityMask)
-	hash := m.hashAlgorithm(key)
+	hash := m.hasher(m.seed, key)
 	index := int(hash & uint64(mask))
 
 	// 双重保险：确保索引在有效范围内
@@ -161,7 +224,7 @@ func (m *HashMap2[U, T]) oldHashIndex(key U, oldCap int) int {
 		return -1
 	}
 	oldMask := oldCap - 1
-	hash := m.hashAlgorithm(key)
+	hash := m.hasher(m.seed, key)
 	index := int(hash & uint64(oldMask))
 
 	if index < 0 || index >= oldCap {
@@ -170,7 +233,9 @@ func (m *HashMap2[U, T]) oldHashIndex(key U, oldCap int) int {
 	return index
 }
 
-// 渐进式扩容
+// 渐进式扩容，每次迁移 rehashStep 个桶
+//
+// 只能在持有 globalLock 写锁时调用：迁移途中的节点既不在旧桶也不在新桶，不能有并发的读
 func (m *HashMap2[U, T]) rehash() {
 	if !m.isResizing.Load() {
 		return
@@ -185,7 +250,7 @@ func (m *HashMap2[U, T]) rehash() {
 		return
 	}
 
-	for m.isResizing.Load() && migrated < REHASH_STEP {
+	for m.isResizing.Load() && migrated < m.rehashStep {
 		currentIdx := atomic.LoadInt32(&m.rehashIndex)
 		if currentIdx >= int32(oldCap) {
 			if atomic.LoadInt32(&m.resizingNum) == 0 {
@@ -204,6 +269,7 @@ func (m *HashMap2[U, T]) rehash() {
 		oldBucket.mutex.Lock()
 		if oldBucket == nil || oldBucket.head == nil {
 			oldBucket.mutex.Unlock()
+			atomic.AddInt32(&m.resizingNum, -1)
 			migrated++
 			continue
 		}
@@ -242,17 +308,28 @@ func (m *HashMap2[U, T]) rehash() {
 	}
 }
 
-// 尝试扩容检查
+// 尝试扩缩容检查
 func (m *HashMap2[U, T]) tryResize() {
 	currentCap := atomic.LoadInt32(&m.capacity)
 	currentSize := atomic.LoadInt32(&m.size)
 
-	if float64(currentSize) <= float64(currentCap)*HASHMAP_LOAD_FACTOR ||
-		m.isResizing.Load() {
+	if m.isResizing.Load() {
+		return
+	}
+	var newCap int32
+	switch {
+	case float64(currentSize) > float64(currentCap)*m.loadFactor:
+		// 计算新容量（确保不溢出）
+		newCap = int32(pow2(int(currentCap * 2)))
+	case float64(currentSize) < float64(currentCap)*m.shrinkFactor:
+		// 缩容到负载为 loadFactor/2 的容量
+		newCap = max(int32(pow2(int(float64(currentSize)*2/m.loadFactor)+1)), m.minCapacity)
+		if newCap >= currentCap {
+			return
+		}
+	default:
 		return
 	}
-	// 计算新容量（确保不溢出）
-	newCap := int32(pow2(int(currentCap * 2)))
 	// 初始化新桶
 	newBuckets := make([]*bucket[U, T], newCap)
 	for i := int32(0); i < newCap; i++ {
@@ -272,12 +349,9 @@ func (m *HashMap2[U, T]) tryResize() {
 func (m *HashMap2[U, T]) Get(key U) (T, bool) {
 	var zero T
 
+	// 只加读锁，不迁移（见 rehash），没有写操作时由后台迁移协程完成迁移
 	m.globalLock.RLock()
 	defer m.globalLock.RUnlock()
-	// 触发扩容迁移
-	if m.isResizing.Load() {
-		m.rehash()
-	}
 
 	// 1. 访问新桶
 	newBuckets := m.buckets
@@ -409,6 +483,7 @@ func (m *HashMap2[U, T]) Remove(key U) bool {
 	if m.isResizing.Load() {
 		m.rehash()
 	}
+	m.tryResize()
 
 	// 1. 尝试从新桶删除
 	newBuckets := m.buckets
@@ -490,3 +565,236 @@ func (m *HashMap2[U, T]) IsEmpty() bool {
 func (m *HashMap2[U, T]) IsResizing() bool {
 	return m.isResizing.Load()
 }
+
+// Range 遍历所有 kv，fn 返回 false 时停止
+//
+// 遍历是弱一致的：遍历开始前存在且遍历期间没有被删除的 key 恰好访问一次，即使遍历期间发生扩缩容；
+// 遍历期间插入的 key 可能访问也可能不访问，删除后重新插入的 key 可能再次访问；
+// 每一步加读锁复制一组 key 后释放，再在锁外调用 fn，fn 中可以读写 map，得到的 value 可能已被修改。
+// 遍历不阻止扩容与迁移，没有遍历完的迭代器（如没有调用 stop 的 iter.Pull）不会影响 map
+func (m *HashMap2[U, T]) Range(fn func(key U, value T) bool) {
+	// 按遍历开始时的容量把 key 按哈希值的低位分组，每一步访问一组。
+	// key 的哈希值不变，无论之后桶数组如何变化，每个 key 都只属于一组
+	m.globalLock.RLock()
+	classes := len(m.buckets)
+	if m.isResizing.Load() {
+		classes = max(classes, len(m.oldBuckets))
+	}
+	m.globalLock.RUnlock()
+
+	var entries []hashMapNode[U, T]
+	for class := 0; class < classes; class++ {
+		entries = m.collect(class, classes, entries[:0])
+		for _, e := range entries {
+			if !fn(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// collect 复制哈希值低位（classes 为 2 的幂）等于 class 的所有 kv
+func (m *HashMap2[U, T]) collect(class, classes int, entries []hashMapNode[U, T]) []hashMapNode[U, T] {
+	// 持有读锁期间没有迁移，key 要么在旧桶要么在新桶
+	m.globalLock.RLock()
+	defer m.globalLock.RUnlock()
+	if m.isResizing.Load() && m.oldBuckets != nil {
+		entries = m.collectFrom(m.oldBuckets, class, classes, entries)
+	}
+	return m.collectFrom(m.buckets, class, classes, entries)
+}
+
+// collectFrom 从桶数组中复制哈希值低位等于 class 的 kv，调用方需持有 globalLock
+func (m *HashMap2[U, T]) collectFrom(buckets []*bucket[U, T], class, classes int, entries []hashMapNode[U, T]) []hashMapNode[U, T] {
+	copyBucket := func(b *bucket[U, T], filter bool) {
+		b.mutex.RLock()
+		defer b.mutex.RUnlock()
+		for node := b.head; node != nil; node = node.next {
+			if !filter || int(m.hasher(m.seed, node.key)&uint64(classes-1)) == class {
+				entries = append(entries, hashMapNode[U, T]{key: node.key, value: node.value})
+			}
+		}
+	}
+	if n := len(buckets); n < classes {
+		// 缩容后一个桶包含多组 key，需要按哈希值过滤
+		copyBucket(buckets[class&(n-1)], true)
+	} else {
+		// 这一组 key 分布在下标为 class + k*classes 的桶中，这些桶中只有这一组 key
+		for i := class; i < n; i += classes {
+			copyBucket(buckets[i], false)
+		}
+	}
+	return entries
+}
+
+// All 返回遍历所有 kv 的迭代器，可以用于 for range，一致性与 Range 相同
+func (m *HashMap2[U, T]) All() iter.Seq2[U, T] {
+	return m.Range
+}
+
+// Keys 所有 key，顺序不确定
+func (m *HashMap2[U, T]) Keys() []U {
+	keys := make([]U, 0, m.Len())
+	m.Range(func(key U, _ T) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Values 所有 value，顺序不确定
+func (m *HashMap2[U, T]) Values() []T {
+	values := make([]T, 0, m.Len())
+	m.Range(func(_ U, value T) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// find 在桶中查找 key，返回节点及其前驱，调用方需持有桶的锁
+func (b *bucket[U, T]) find(key U) (prev, node *hashMapNode[U, T]) {
+	for node = b.head; node != nil; prev, node = node, node.next {
+		if node.key == key {
+			return prev, node
+		}
+	}
+	return nil, nil
+}
+
+// compute 原子地读取 key 当前的值，由 fn 决定写入的新值，返回旧值
+//
+// 只持有 globalLock 读锁与 key 所属桶的锁，不同桶的 key 可以并发执行。扩容中先锁旧桶再锁新桶：
+// key 仍在旧桶时在旧桶中执行，否则在新桶中执行，新 key 插入新桶。所有调用都按 旧桶→新桶 的顺序加锁，不会死锁。
+// fn 返回 keep=false 时删除 key（key 不存在时什么也不做），否则写入 value。fn 中不能调用 map 的方法
+func (m *HashMap2[U, T]) compute(key U, fn func(old T, loaded bool) (value T, keep bool)) (old T, loaded bool) {
+	// 迁移与扩缩容需要 globalLock 写锁，只在需要且没有其他操作时顺带执行，不等待锁，
+	// 否则会与其他 compute 串行。没有执行的迁移由之后的 Put、Remove 或后台协程完成
+	if m.needsMaintenance() && m.globalLock.TryLock() {
+		m.rehash()
+		m.tryResize()
+		m.globalLock.Unlock()
+	}
+
+	m.globalLock.RLock()
+	defer m.globalLock.RUnlock()
+
+	// 持有读锁期间桶数组不会变化，也不会有迁移，key 要么在旧桶要么在新桶
+	if m.isResizing.Load() && m.oldBuckets != nil {
+		oldBucket := m.oldBuckets[m.oldHashIndex(key, len(m.oldBuckets))]
+		oldBucket.mutex.Lock()
+		defer oldBucket.mutex.Unlock()
+		if _, node := oldBucket.find(key); node != nil {
+			return m.computeIn(oldBucket, key, fn)
+		}
+	}
+
+	newBucket := m.buckets[m.hashIndex(key)]
+	newBucket.mutex.Lock()
+	defer newBucket.mutex.Unlock()
+	return m.computeIn(newBucket, key, fn)
+}
+
+// needsMaintenance 是否需要迁移或扩缩容
+func (m *HashMap2[U, T]) needsMaintenance() bool {
+	if m.isResizing.Load() {
+		return true
+	}
+	capacity, size := float64(atomic.LoadInt32(&m.capacity)), float64(atomic.LoadInt32(&m.size))
+	return size > capacity*m.loadFactor ||
+		size < capacity*m.shrinkFactor && atomic.LoadInt32(&m.capacity) > m.minCapacity
+}
+
+// computeIn 在桶 b 中执行 compute，调用方需持有 b 的锁
+func (m *HashMap2[U, T]) computeIn(b *bucket[U, T], key U, fn func(old T, loaded bool) (T, bool)) (old T, loaded bool) {
+	prev, node := b.find(key)
+	if node != nil {
+		old, loaded = node.value, true
+	}
+	value, keep := fn(old, loaded)
+	switch {
+	case keep && loaded:
+		node.value = value
+	case keep:
+		b.head = &hashMapNode[U, T]{key: key, value: value, next: b.head}
+		atomic.AddInt32(&m.size, 1)
+	case loaded:
+		if prev == nil {
+			b.head = node.next
+		} else {
+			prev.next = node.next
+		}
+		atomic.AddInt32(&m.size, -1)
+	}
+	return old, loaded
+}
+
+// Compute 原子地根据 key 当前的值计算新值，返回新值以及 key 是否存在
+//
+// fn 的参数为当前值以及 key 是否存在，返回 keep=false 时删除 key。
+// fn 在桶的锁内执行，不能调用 map 的方法
+func (m *HashMap2[U, T]) Compute(key U, fn func(old T, ok bool) (value T, keep bool)) (T, bool) {
+	var (
+		value T
+		keep  bool
+	)
+	m.compute(key, func(old T, loaded bool) (T, bool) {
+		value, keep = fn(old, loaded)
+		return value, keep
+	})
+	if !keep {
+		var zero T
+		return zero, false
+	}
+	return value, true
+}
+
+// LoadOrStore key 存在时返回当前值与 loaded=true，否则写入 value 并返回 value
+func (m *HashMap2[U, T]) LoadOrStore(key U, value T) (actual T, loaded bool) {
+	old, loaded := m.compute(key, func(old T, loaded bool) (T, bool) {
+		if loaded {
+			return old, true
+		}
+		return value, true
+	})
+	if loaded {
+		return old, true
+	}
+	return value, false
+}
+
+// LoadAndDelete 删除 key 并返回删除前的值
+func (m *HashMap2[U, T]) LoadAndDelete(key U) (value T, loaded bool) {
+	return m.compute(key, func(T, bool) (T, bool) {
+		var zero T
+		return zero, false
+	})
+}
+
+// Swap 写入 value 并返回之前的值
+func (m *HashMap2[U, T]) Swap(key U, value T) (previous T, loaded bool) {
+	return m.compute(key, func(T, bool) (T, bool) {
+		return value, true
+	})
+}
+
+// CompareAndSwap key 当前的值等于 old 时替换为 new，T 不可比较时 panic
+func (m *HashMap2[U, T]) CompareAndSwap(key U, old, new T) (swapped bool) {
+	m.compute(key, func(current T, loaded bool) (T, bool) {
+		swapped = loaded && any(current) == any(old)
+		if swapped {
+			return new, true
+		}
+		return current, loaded
+	})
+	return swapped
+}
+
+// CompareAndDelete key 当前的值等于 old 时删除，T 不可比较时 panic
+func (m *HashMap2[U, T]) CompareAndDelete(key U, old T) (deleted bool) {
+	m.compute(key, func(current T, loaded bool) (T, bool) {
+		deleted = loaded && any(current) == any(old)
+		return current, loaded && !deleted
+	})
+	return deleted
+}
@@ -1,10 +1,13 @@
 package datastruct
 
 import (
+	"iter"
+	"slices"
 	"strconv"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // 测试在并发环境下Get方法的边界条件，特别是索引越界问题
@@ -300,3 +303,667 @@ func TestHashMap2_ConcurrentSafety(t *testing.T) {
 			expectedMinLen, numWriters*numOps, finalLen)
 	}
 }
+
+// 测试扩容过程中遍历，每个 key 恰好访问一次
+func TestHashMap2_RangeDuringResize(t *testing.T) {
+	hm := NewHashMap2[int, int](WithInitialCapacity[int, int](4))
+	n := 0
+	for ; n < 10000 && (n < 100 || !hm.IsResizing()); n++ {
+		hm.Put(n, n*10)
+	}
+	if !hm.IsResizing() {
+		t.Fatal("未进入扩容状态")
+	}
+
+	seen := make(map[int]int)
+	for k, v := range hm.All() {
+		if v != k*10 {
+			t.Fatalf("key %d 的 value 为 %d", k, v)
+		}
+		seen[k]++
+	}
+	if len(seen) != n {
+		t.Fatalf("遍历到 %d 个 key, 期望 %d", len(seen), n)
+	}
+	for k, c := range seen {
+		if c != 1 {
+			t.Fatalf("key %d 访问了 %d 次", k, c)
+		}
+	}
+
+	keys, values := hm.Keys(), hm.Values()
+	slices.Sort(keys)
+	slices.Sort(values)
+	for i := 0; i < n; i++ {
+		if keys[i] != i || values[i] != i*10 {
+			t.Fatalf("Keys/Values 第 %d 个为 %d/%d", i, keys[i], values[i])
+		}
+	}
+
+	count := 0
+	hm.Range(func(int, int) bool {
+		count++
+		return count < 5
+	})
+	if count != 5 {
+		t.Fatalf("提前终止后访问了 %d 个", count)
+	}
+}
+
+// 测试遍历时修改 map：删除的 key 不再访问，原有的 key 恰好访问一次，遍历结束后可以继续扩容
+func TestHashMap2_RangeWithMutation(t *testing.T) {
+	const n = 1000
+	// 哈希值等于 key，相邻的 key 不在同一个桶，被删除的 key 所在的桶还没有被复制，不会被访问到；
+	// 随机种子下相邻的 key 可能在同一个桶，复制后被删除的 key 仍会访问（这是允许的弱一致行为）
+	hm := NewHashMap2[int, int](WithHashAlgorithm[int, int](func(key int) uint64 { return uint64(key) }))
+	for i := 0; i < n; i++ {
+		hm.Put(i, i)
+	}
+	removed := make(map[int]bool)
+	seen := make(map[int]int)
+	hm.Range(func(k, v int) bool {
+		if removed[k] {
+			t.Fatalf("访问到已删除的 key %d", k)
+		}
+		seen[k]++
+		if k >= n { // 遍历期间插入的 key
+			return true
+		}
+		// 删除下一个 key，插入新的 key
+		if next := (k + 1) % n; seen[next] == 0 && !removed[next] {
+			if hm.Remove(next) {
+				removed[next] = true
+			}
+		}
+		hm.Put(n+k, k)
+		return true
+	})
+	for i := 0; i < n; i++ {
+		if want := map[bool]int{false: 1, true: 0}[removed[i]]; seen[i] != want {
+			t.Fatalf("key %d 访问了 %d 次, 期望 %d", i, seen[i], want)
+		}
+	}
+	// 每个访问到的原有 key 插入了一个新的 key
+	if want := 2 * (n - len(removed)); hm.Len() != want {
+		t.Fatalf("Len=%d, 期望 %d", hm.Len(), want)
+	}
+	capacity := hm.Capacity()
+	for i := 0; i < 4*n; i++ {
+		hm.Put(-i-1, i)
+	}
+	if hm.Capacity() <= capacity {
+		t.Fatalf("遍历结束后没有继续扩容, Capacity=%d", hm.Capacity())
+	}
+}
+
+// 测试没有遍历完的迭代器不会阻止扩缩容，之后继续遍历仍然恰好访问每个原有的 key 一次
+func TestHashMap2_RangeSuspended(t *testing.T) {
+	const n = 1000
+	hm := NewHashMap2[int, int](WithRehashStep[int, int](1))
+	for i := 0; i < n; i++ {
+		hm.Put(i, i)
+	}
+	next, stop := iter.Pull2(hm.All())
+	defer stop()
+	seen := make(map[int]int)
+	k, _, ok := next()
+	if !ok {
+		t.Fatal("迭代器为空")
+	}
+	seen[k]++
+
+	// 迭代器挂起期间扩容
+	capacity := hm.Capacity()
+	for i := 0; i < 8*n; i++ {
+		hm.Put(-i-1, i)
+	}
+	for i := 0; hm.IsResizing() && i < n; i++ {
+		hm.Put(-1, 0)
+	}
+	if hm.Capacity() <= capacity {
+		t.Fatalf("迭代器挂起期间没有扩容, Capacity=%d", hm.Capacity())
+	}
+	// 删除新插入的 key，迭代器挂起期间缩容
+	capacity = hm.Capacity()
+	for i := 0; i < 8*n; i++ {
+		hm.Remove(-i - 1)
+	}
+	for i := 0; hm.IsResizing() && i < n; i++ {
+		hm.Remove(-1)
+	}
+	if hm.Capacity() >= capacity {
+		t.Fatalf("迭代器挂起期间没有缩容, Capacity=%d", hm.Capacity())
+	}
+
+	for k, _, ok := next(); ok; k, _, ok = next() {
+		seen[k]++
+	}
+	for i := 0; i < n; i++ {
+		if seen[i] != 1 {
+			t.Fatalf("key %d 访问了 %d 次, 期望 1", i, seen[i])
+		}
+	}
+}
+
+// 测试并发读写时遍历，一直存在的 key 每次都恰好访问一次
+func TestHashMap2_RangeConcurrent(t *testing.T) {
+	const stable = 500
+	hm := NewHashMap2[int, int](WithInitialCapacity[int, int](4))
+	for i := 0; i < stable; i++ {
+		hm.Put(i, i)
+	}
+	var (
+		wg   sync.WaitGroup
+		done atomic.Bool
+	)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; !done.Load(); i++ {
+				key := stable + w*100000 + i%5000
+				hm.Put(key, i)
+				if i%3 == 0 {
+					hm.Remove(key)
+				}
+				hm.Get(i % stable)
+			}
+		}(w)
+	}
+	for round := 0; round < 50; round++ {
+		seen := make([]int, stable)
+		hm.Range(func(k, v int) bool {
+			if k < stable {
+				seen[k]++
+			}
+			return true
+		})
+		for k, c := range seen {
+			if c != 1 {
+				done.Store(true)
+				wg.Wait()
+				t.Fatalf("第 %d 轮 key %d 访问了 %d 次", round, k, c)
+			}
+		}
+	}
+	done.Store(true)
+	wg.Wait()
+}
+
+// newResizingHashMap2 返回正处于扩容中的 map，key 为 [0, n)，value 等于 key
+func newResizingHashMap2(t *testing.T) (*HashMap2[int, int], int) {
+	t.Helper()
+	hm := NewHashMap2[int, int](WithInitialCapacity[int, int](4))
+	n := 0
+	for ; n < 10000 && (n < 100 || !hm.IsResizing()); n++ {
+		hm.Put(n, n)
+	}
+	if !hm.IsResizing() {
+		t.Fatal("未进入扩容状态")
+	}
+	return hm, n
+}
+
+// 测试原子操作的语义，操作的 key 可能在旧桶也可能在新桶
+func TestHashMap2_AtomicOperations(t *testing.T) {
+	hm, n := newResizingHashMap2(t)
+
+	for i := 0; i < n; i++ {
+		if v, loaded := hm.LoadOrStore(i, -1); !loaded || v != i {
+			t.Fatalf("LoadOrStore(%d)=%d,%v, 期望 %d,true", i, v, loaded, i)
+		}
+	}
+	if v, loaded := hm.LoadOrStore(n, -1); loaded || v != -1 {
+		t.Fatalf("LoadOrStore(%d)=%d,%v, 期望 -1,false", n, v, loaded)
+	}
+	if hm.Len() != n+1 {
+		t.Fatalf("Len=%d, 期望 %d", hm.Len(), n+1)
+	}
+
+	for i := 0; i < n; i += 2 {
+		if hm.CompareAndSwap(i, i+1, 0) {
+			t.Fatalf("CompareAndSwap(%d) 旧值不匹配时不应替换", i)
+		}
+		if !hm.CompareAndSwap(i, i, i*10) {
+			t.Fatalf("CompareAndSwap(%d) 失败", i)
+		}
+		if prev, loaded := hm.Swap(i, i*100); !loaded || prev != i*10 {
+			t.Fatalf("Swap(%d)=%d,%v, 期望 %d,true", i, prev, loaded, i*10)
+		}
+		if v, _ := hm.Get(i); v != i*100 {
+			t.Fatalf("Get(%d)=%d, 期望 %d", i, v, i*100)
+		}
+	}
+	if hm.CompareAndSwap(-5, 0, 1) || hm.CompareAndDelete(-5, 0) {
+		t.Fatal("不存在的 key 不应替换或删除")
+	}
+	if _, loaded := hm.Swap(-5, 5); loaded {
+		t.Fatal("Swap 不存在的 key 应返回 loaded=false")
+	}
+
+	for i := 1; i < n; i += 2 {
+		if hm.CompareAndDelete(i, i+1) {
+			t.Fatalf("CompareAndDelete(%d) 旧值不匹配时不应删除", i)
+		}
+		if !hm.CompareAndDelete(i, i) {
+			t.Fatalf("CompareAndDelete(%d) 失败", i)
+		}
+		if _, ok := hm.Get(i); ok {
+			t.Fatalf("CompareAndDelete(%d) 后仍能找到", i)
+		}
+	}
+	for i := 0; i < n; i += 2 {
+		if v, loaded := hm.LoadAndDelete(i); !loaded || v != i*100 {
+			t.Fatalf("LoadAndDelete(%d)=%d,%v", i, v, loaded)
+		}
+		if _, loaded := hm.LoadAndDelete(i); loaded {
+			t.Fatalf("LoadAndDelete(%d) 重复删除", i)
+		}
+	}
+	if hm.Len() != 2 || len(hm.Keys()) != 2 {
+		t.Fatalf("Len=%d, Keys=%v, 期望剩余 %d 与 -5", hm.Len(), hm.Keys(), n)
+	}
+
+	// Compute：不存在时插入，返回 keep=false 时删除
+	for i := 0; i < 3; i++ {
+		if v, ok := hm.Compute(100000, func(old int, ok bool) (int, bool) { return old + 1, true }); !ok || v != i+1 {
+			t.Fatalf("Compute 第 %d 次=%d,%v", i, v, ok)
+		}
+	}
+	if v, ok := hm.Compute(100000, func(old int, ok bool) (int, bool) { return 0, false }); ok || v != 0 {
+		t.Fatalf("Compute 删除=%d,%v", v, ok)
+	}
+	if _, ok := hm.Compute(100001, func(int, bool) (int, bool) { return 1, false }); ok || hm.Len() != 2 {
+		t.Fatal("Compute 对不存在的 key 返回 keep=false 时不应插入")
+	}
+}
+
+// 测试 value 不可比较时 CompareAndSwap panic，且不会持有锁
+func TestHashMap2_CompareAndSwapIncomparable(t *testing.T) {
+	hm := NewHashMap2[string, any]()
+	hm.Put("k", []int{1})
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("value 不可比较时应 panic")
+			}
+		}()
+		hm.CompareAndSwap("k", []int{1}, 2)
+	}()
+	hm.Put("k", 1)
+	if !hm.CompareAndSwap("k", 1, 2) {
+		t.Fatal("panic 后 CompareAndSwap 失败")
+	}
+}
+
+// 测试并发原子操作：计数器、LoadOrStore 竞争、CompareAndSwap 自旋累加，同时发生扩容
+func TestHashMap2_AtomicConcurrent(t *testing.T) {
+	const (
+		workers = 8
+		keys    = 2000
+		rounds  = 3
+	)
+	hm := NewHashMap2[int, int](WithInitialCapacity[int, int](4))
+	var wg sync.WaitGroup
+	winners := make([][]int, workers) // 每个协程 LoadOrStore 得到的值
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				for k := 0; k < keys; k++ {
+					// 计数器
+					hm.Compute(k, func(old int, ok bool) (int, bool) { return old + 1, true })
+					// 每个 key 只有一个协程能写入
+					actual, _ := hm.LoadOrStore(-k-1, w)
+					if r == 0 {
+						winners[w] = append(winners[w], actual)
+					}
+					// CompareAndSwap 自旋累加
+					for {
+						v, _ := hm.LoadOrStore(keys+k, 0)
+						if hm.CompareAndSwap(keys+k, v, v+1) {
+							break
+						}
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	for k := 0; k < keys; k++ {
+		if v, _ := hm.Get(k); v != workers*rounds {
+			t.Fatalf("Compute 计数 %d=%d, 期望 %d", k, v, workers*rounds)
+		}
+		if v, _ := hm.Get(keys + k); v != workers*rounds {
+			t.Fatalf("CompareAndSwap 计数 %d=%d, 期望 %d", keys+k, v, workers*rounds)
+		}
+		winner, _ := hm.Get(-k - 1)
+		for w := 0; w < workers; w++ {
+			if winners[w][k] != winner {
+				t.Fatalf("LoadOrStore(%d) 协程 %d 得到 %d, 实际写入 %d", -k-1, w, winners[w][k], winner)
+			}
+		}
+	}
+	if hm.Len() != 3*keys {
+		t.Fatalf("Len=%d, 期望 %d", hm.Len(), 3*keys)
+	}
+
+	// 并发 Swap 与 LoadAndDelete：每个值恰好被取出一次，key -1 原来的值是 k=0 时 LoadOrStore 写入的
+	initial, _ := hm.Get(-1)
+	var sum atomic.Int64
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 1; i <= 1000; i++ {
+				if prev, loaded := hm.Swap(-1, w*1000+i); loaded {
+					sum.Add(int64(prev))
+				}
+				if i%7 == 0 {
+					if prev, loaded := hm.LoadAndDelete(-1); loaded {
+						sum.Add(int64(prev))
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	last, _ := hm.LoadAndDelete(-1)
+	want := int64(initial)
+	for w := 0; w < workers; w++ {
+		for i := 1; i <= 1000; i++ {
+			want += int64(w*1000 + i)
+		}
+	}
+	if got := sum.Load() + int64(last); got != want {
+		t.Fatalf("取出的值之和为 %d, 期望 %d", got, want)
+	}
+}
+
+// computeRendezvous 两个协程分别对 a、b 执行 Compute，fn 中等待另一个协程也进入 fn，
+// 只有两个 Compute 同时持有各自桶的锁时才能汇合，串行执行时等待超时
+func computeRendezvous(t *testing.T, hm *HashMap2[int, int], a, b int) {
+	t.Helper()
+	arrived := [2]chan struct{}{make(chan struct{}), make(chan struct{})}
+	var (
+		wg  sync.WaitGroup
+		met [2]bool
+	)
+	for i, key := range []int{a, b} {
+		wg.Add(1)
+		go func(i, key int) {
+			defer wg.Done()
+			hm.Compute(key, func(old int, ok bool) (int, bool) {
+				close(arrived[i])
+				select {
+				case <-arrived[1-i]:
+					met[i] = true
+				case <-time.After(2 * time.Second):
+				}
+				return old + 1, true
+			})
+		}(i, key)
+	}
+	wg.Wait()
+	if !met[0] || !met[1] {
+		t.Fatalf("Compute(%d) 与 Compute(%d) 没有并发执行", a, b)
+	}
+}
+
+// 不同桶的 key 上的原子操作可以并发执行，扩容中也是如此
+func TestHashMap2_ComputeParallel(t *testing.T) {
+	identity := func(key int) uint64 { return uint64(key) }
+	t.Run("不同桶", func(t *testing.T) {
+		hm := NewHashMap2[int, int](WithHashAlgorithm[int, int](identity))
+		computeRendezvous(t, hm, 0, 1)
+	})
+	t.Run("扩容中旧桶与新桶", func(t *testing.T) {
+		hm := NewHashMap2[int, int](WithHashAlgorithm[int, int](identity), WithRehashStep[int, int](1))
+		n := 0
+		for ; !hm.IsResizing(); n++ {
+			hm.Put(n, n)
+		}
+		// 每次只迁移一个桶，最后插入的 key 还在未迁移的旧桶；100 是新 key，两者的旧桶与新桶都不同
+		last := n - 1
+		computeRendezvous(t, hm, last, 100)
+		if v, _ := hm.Get(last); v != last+1 {
+			t.Fatalf("Get(%d)=%d, 期望 %d", last, v, last+1)
+		}
+		if v, _ := hm.Get(100); v != 1 {
+			t.Fatalf("Get(100)=%d, 期望 1", v)
+		}
+	})
+}
+
+// 多个协程在各自的 key 上执行原子操作，同时有协程反复插入删除其他 key 触发扩缩容
+func TestHashMap2_ComputeDisjointStress(t *testing.T) {
+	const (
+		workers = 16
+		keys    = 300
+		rounds  = 20
+	)
+	hm := NewHashMap2[int, int]()
+	var (
+		wg   sync.WaitGroup
+		done atomic.Bool
+	)
+	wg.Add(1)
+	go func() { // 负数 key 用于触发扩缩容
+		defer wg.Done()
+		for !done.Load() {
+			for i := 1; i <= 2000; i++ {
+				hm.Put(-i, i)
+			}
+			for i := 1; i <= 2000; i++ {
+				hm.Remove(-i)
+			}
+		}
+	}()
+
+	var workerWg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		workerWg.Add(1)
+		go func(w int) {
+			defer workerWg.Done()
+			for r := 0; r < rounds; r++ {
+				for i := 0; i < keys; i++ {
+					counter, temp := w*2*keys+i, w*2*keys+keys+i
+					if v, _ := hm.Compute(counter, func(old int, ok bool) (int, bool) { return old + 1, true }); v != r+1 {
+						t.Errorf("Compute(%d)=%d, 期望 %d", counter, v, r+1)
+						return
+					}
+					if !hm.CompareAndSwap(counter, r+1, r+1) {
+						t.Errorf("CompareAndSwap(%d, %d) 失败", counter, r+1)
+						return
+					}
+					if prev, loaded := hm.Swap(counter, r+1); !loaded || prev != r+1 {
+						t.Errorf("Swap(%d)=%d,%v, 期望 %d,true", counter, prev, loaded, r+1)
+						return
+					}
+					if actual, loaded := hm.LoadOrStore(temp, r); loaded || actual != r {
+						t.Errorf("LoadOrStore(%d)=%d,%v, 期望 %d,false", temp, actual, loaded, r)
+						return
+					}
+					if v, loaded := hm.LoadAndDelete(temp); !loaded || v != r {
+						t.Errorf("LoadAndDelete(%d)=%d,%v, 期望 %d,true", temp, v, loaded, r)
+						return
+					}
+				}
+			}
+		}(w)
+	}
+	workerWg.Wait()
+	done.Store(true)
+	wg.Wait()
+
+	for i := 1; i <= 2000; i++ {
+		hm.Remove(-i)
+	}
+	for w := 0; w < workers; w++ {
+		for i := 0; i < keys; i++ {
+			if v, ok := hm.Get(w*2*keys + i); !ok || v != rounds {
+				t.Fatalf("Get(%d)=%d,%v, 期望 %d", w*2*keys+i, v, ok, rounds)
+			}
+		}
+	}
+	if hm.Len() != workers*keys {
+		t.Fatalf("Len=%d, 期望 %d", hm.Len(), workers*keys)
+	}
+}
+
+// waitResized 反复执行 op 直到迁移完成
+func waitResized[U comparable, T any](t *testing.T, hm *HashMap2[U, T], op func()) {
+	t.Helper()
+	for i := 0; hm.IsResizing(); i++ {
+		if i > 1e6 {
+			t.Fatal("迁移没有完成")
+		}
+		op()
+	}
+}
+
+// 测试删除大部分元素后缩容，缩容过程中数据不丢失
+func TestHashMap2_Shrink(t *testing.T) {
+	const n = 10000
+	hm := NewHashMap2[int, int]()
+	for i := 0; i < n; i++ {
+		hm.Put(i, i)
+	}
+	waitResized(t, hm, func() { hm.Remove(-1) })
+	grown := hm.Capacity()
+	for i := 10; i < n; i++ {
+		hm.Remove(i)
+	}
+	waitResized(t, hm, func() { hm.Remove(-1) })
+	if hm.Capacity() >= grown/16 || hm.Capacity() < HASHMAP_DEFAULT_SIZE {
+		t.Fatalf("删除后容量为 %d, 扩容后为 %d", hm.Capacity(), grown)
+	}
+	for i := 0; i < 10; i++ {
+		if v, ok := hm.Get(i); !ok || v != i {
+			t.Fatalf("缩容后 Get(%d)=%d,%v", i, v, ok)
+		}
+	}
+	if hm.Len() != 10 || len(hm.Keys()) != 10 {
+		t.Fatalf("缩容后 Len=%d", hm.Len())
+	}
+
+	// 不低于初始容量
+	hm = NewHashMap2[int, int](WithInitialCapacity[int, int](1024))
+	for i := 0; i < n; i++ {
+		hm.Put(i, i)
+	}
+	for i := 0; i < n; i++ {
+		hm.Remove(i)
+	}
+	waitResized(t, hm, func() { hm.Remove(-1) })
+	if hm.Capacity() != 1024 {
+		t.Fatalf("缩容后容量为 %d, 期望不低于初始容量 1024", hm.Capacity())
+	}
+
+	// 阈值为 0 时不缩容
+	hm = NewHashMap2[int, int](WithShrinkThreshold[int, int](0))
+	for i := 0; i < n; i++ {
+		hm.Put(i, i)
+	}
+	waitResized(t, hm, func() { hm.Remove(-1) })
+	grown = hm.Capacity()
+	for i := 0; i < n; i++ {
+		hm.Remove(i)
+	}
+	if hm.Capacity() != grown || hm.IsResizing() {
+		t.Fatalf("不缩容时容量从 %d 变为 %d", grown, hm.Capacity())
+	}
+}
+
+// 测试负载因子、缩容阈值与迁移步长的配置
+func TestHashMap2_ResizeOptions(t *testing.T) {
+	hm := NewHashMap2[int, int](WithLoadFactor[int, int](4))
+	for i := 0; i < 64; i++ {
+		hm.Put(i, i)
+	}
+	if hm.Capacity() != HASHMAP_DEFAULT_SIZE {
+		t.Fatalf("负载因子为 4 时插入 64 个后容量为 %d", hm.Capacity())
+	}
+	hm.Put(64, 64)
+	hm.Put(65, 65)
+	if hm.Capacity() != 2*HASHMAP_DEFAULT_SIZE {
+		t.Fatalf("超过负载因子后容量为 %d", hm.Capacity())
+	}
+
+	// 缩容阈值过大时使用 loadFactor/4
+	hm = NewHashMap2[int, int](WithShrinkThreshold[int, int](0.6), WithLoadFactor[int, int](0.8))
+	if hm.shrinkFactor != 0.2 {
+		t.Fatalf("shrinkFactor=%v, 期望 0.2", hm.shrinkFactor)
+	}
+	// 非法配置被忽略
+	hm = NewHashMap2[int, int](WithLoadFactor[int, int](-1), WithShrinkThreshold[int, int](-1), WithRehashStep[int, int](0), WithBackgroundRehash[int, int](0))
+	if hm.loadFactor != HASHMAP_LOAD_FACTOR || hm.shrinkFactor != HASHMAP_SHRINK_FACTOR || hm.rehashStep != REHASH_STEP || hm.stop != nil {
+		t.Fatal("非法配置未被忽略")
+	}
+
+	// 步长为 1 时每次写操作只迁移一个桶
+	hm = NewHashMap2[int, int](WithRehashStep[int, int](1))
+	n := 0
+	for ; !hm.IsResizing(); n++ {
+		hm.Put(n, n)
+	}
+	ops := 0
+	waitResized(t, hm, func() {
+		hm.Remove(-1)
+		ops++
+	})
+	if ops < HASHMAP_DEFAULT_SIZE {
+		t.Fatalf("迁移 %d 个桶只用了 %d 次操作", HASHMAP_DEFAULT_SIZE, ops)
+	}
+	for i := 0; i < n; i++ {
+		if v, ok := hm.Get(i); !ok || v != i {
+			t.Fatalf("Get(%d)=%d,%v", i, v, ok)
+		}
+	}
+}
+
+// 测试后台迁移：没有写操作时也能完成迁移，同时有并发读
+func TestHashMap2_BackgroundRehash(t *testing.T) {
+	hm := NewHashMap2[int, int](WithBackgroundRehash[int, int](time.Millisecond), WithRehashStep[int, int](1))
+	defer hm.Close()
+	n := 0
+	for ; !hm.IsResizing(); n++ {
+		hm.Put(n, n)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		done atomic.Bool
+	)
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; !done.Load(); i++ {
+				if v, ok := hm.Get(i % n); !ok || v != i%n {
+					t.Errorf("迁移中 Get(%d)=%d,%v", i%n, v, ok)
+					return
+				}
+			}
+		}()
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for hm.IsResizing() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	done.Store(true)
+	wg.Wait()
+	if hm.IsResizing() {
+		t.Fatal("后台迁移没有完成")
+	}
+
+	hm.Close()
+	hm.Close()
+	hm.Put(-1, -1)
+	if v, ok := hm.Get(-1); !ok || v != -1 {
+		t.Fatal("Close 后无法读写")
+	}
+	NewHashMap2[int, int]().Close() // 没有后台迁移时 Close 什么也不做
+}
@@ -0,0 +1,43 @@
+package datastruct
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvariant = errors.New("invariant violated") // 数据结构的不变式被破坏
+
+// InvariantError Verify 发现的第一处不变式错误
+//
+// path 为出错结点自根结点开始的路径，二叉树形如 "root.L.R"，多叉树形如 "root/2/0"
+type InvariantError struct {
+	path   string
+	reason string
+}
+
+var _ error = (*InvariantError)(nil)
+
+func NewInvariantError(path string, format string, args ...any) *InvariantError {
+	return &InvariantError{
+		path:   path,
+		reason: fmt.Sprintf(format, args...),
+	}
+}
+
+// Path 出错结点的路径
+func (e *InvariantError) Path() string {
+	return e.path
+}
+
+// Reason 出错原因
+func (e *InvariantError) Reason() string {
+	return e.reason
+}
+
+func (e *InvariantError) Error() string {
+	return fmt.Sprintf("%v at %s: %s", ErrInvariant, e.path, e.reason)
+}
+
+func (e *InvariantError) Unwrap() error {
+	return ErrInvariant
+}
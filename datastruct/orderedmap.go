@@ -0,0 +1,24 @@
+package datastruct
+
+// OrderedMap 按 key 有序的 map
+//
+// 对任意 K、V 实现了该接口的有 AvlTree、rbtree.RBTree、bptree.BPTree、SkipList、ConcurrentSkipList。
+// 以下结构只在固定的类型上实现：
+//   - btree.BTree 不是泛型的，只实现了 OrderedMap[int64, interface{}]
+//   - SkipLinks 本身没有实现，SkipLinks.OrderedMap() 返回的适配器实现了 OrderedMap[ScoreKey, T]，key 为 (分数, key)
+type OrderedMap[K, V any] interface {
+	// Get 查找 key 对应的 value
+	Get(key K) (V, bool)
+	// Put 插入或更新 key，key 已存在时返回旧的 value 与 replaced=true
+	Put(key K, value V) (old V, replaced bool)
+	// Delete 删除 key，返回被删除的 value
+	Delete(key K) (V, bool)
+	// Len kv 的数量
+	Len() int
+	// Ascend 按 key 升序遍历，fn 返回 false 时停止
+	Ascend(fn func(key K, value V) bool)
+	// Range 按 key 升序遍历 [lo, hi) 区间，fn 返回 false 时停止
+	Range(lo, hi K, fn func(key K, value V) bool)
+}
+
+var _ OrderedMap[int, int] = (*AvlTree[int, int])(nil)
@@ -0,0 +1,168 @@
+package datastruct_test
+
+import (
+	"DataStruct/datastruct"
+	"DataStruct/datastruct/bptree"
+	"DataStruct/datastruct/btree"
+	"DataStruct/datastruct/rbtree"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const (
+	conformanceKeys = 400  // key 的取值个数
+	conformanceOps  = 6000 // 随机操作次数
+)
+
+// testOrderedMap 对 OrderedMap 执行随机的 Put、Delete、Get、Ascend、Range，与参考实现对比
+//
+//	@m 空的 OrderedMap
+//	@key 第 i 个 key，必须随 i 严格递增
+//	@value 第 i 次操作写入的 value
+func testOrderedMap[K any, V comparable](t *testing.T, m datastruct.OrderedMap[K, V], key func(i int) K, value func(i int) V) {
+	t.Helper()
+	ref := make(map[int]V) // key 的下标 -> value
+	r := rand.New(rand.NewSource(1))
+
+	// sorted 参考实现中 [lo, hi) 区间内的 key 下标
+	sorted := func(lo, hi int) []int {
+		var idx []int
+		for i := range ref {
+			if i >= lo && i < hi {
+				idx = append(idx, i)
+			}
+		}
+		slices.Sort(idx)
+		return idx
+	}
+	// collect 收集遍历结果，limit > 0 时遍历 limit 个后停止
+	collect := func(iter func(fn func(key K, value V) bool), limit int) (keys []K, values []V) {
+		iter(func(k K, v V) bool {
+			keys, values = append(keys, k), append(values, v)
+			return limit <= 0 || len(keys) < limit
+		})
+		return
+	}
+	check := func(op string, idx []int, keys []K, values []V) {
+		t.Helper()
+		if len(keys) != len(idx) {
+			t.Fatalf("%s 返回 %d 个 kv, 期望 %d", op, len(keys), len(idx))
+		}
+		for j, i := range idx {
+			if fmt.Sprint(keys[j]) != fmt.Sprint(key(i)) || values[j] != ref[i] {
+				t.Fatalf("%s 第 %d 个 kv 为 %v=%v, 期望 %v=%v", op, j, keys[j], values[j], key(i), ref[i])
+			}
+		}
+	}
+
+	for op := 0; op < conformanceOps; op++ {
+		i := r.Intn(conformanceKeys)
+		switch r.Intn(10) {
+		case 0, 1, 2: // Delete
+			want, exist := ref[i]
+			if v, ok := m.Delete(key(i)); ok != exist || v != want {
+				t.Fatalf("Delete(%v)=%v,%v, 期望 %v,%v", key(i), v, ok, want, exist)
+			}
+			delete(ref, i)
+		case 3, 4, 5, 6: // Put
+			want, exist := ref[i]
+			if old, replaced := m.Put(key(i), value(op)); replaced != exist || old != want {
+				t.Fatalf("Put(%v)=%v,%v, 期望 %v,%v", key(i), old, replaced, want, exist)
+			}
+			ref[i] = value(op)
+		case 7, 8: // Get
+			want, exist := ref[i]
+			if v, ok := m.Get(key(i)); ok != exist || v != want {
+				t.Fatalf("Get(%v)=%v,%v, 期望 %v,%v", key(i), v, ok, want, exist)
+			}
+		case 9: // Range
+			lo, hi := i, i+r.Intn(conformanceKeys/4)
+			keys, values := collect(func(fn func(key K, value V) bool) { m.Range(key(lo), key(hi), fn) }, 0)
+			check(fmt.Sprintf("Range(%v, %v)", key(lo), key(hi)), sorted(lo, hi), keys, values)
+		}
+		if m.Len() != len(ref) {
+			t.Fatalf("第 %d 次操作后 Len=%d, 期望 %d", op, m.Len(), len(ref))
+		}
+		if op%500 == 0 {
+			keys, values := collect(m.Ascend, 0)
+			check("Ascend", sorted(0, conformanceKeys), keys, values)
+		}
+	}
+
+	// 提前终止
+	idx := sorted(0, conformanceKeys)
+	keys, values := collect(m.Ascend, 5)
+	check("Ascend 提前终止", idx[:min(5, len(idx))], keys, values)
+	keys, values = collect(func(fn func(key K, value V) bool) { m.Range(key(0), key(conformanceKeys), fn) }, 5)
+	check("Range 提前终止", idx[:min(5, len(idx))], keys, values)
+
+	// 全部删除
+	for _, i := range idx {
+		if _, ok := m.Delete(key(i)); !ok {
+			t.Fatalf("Delete(%v) 失败", key(i))
+		}
+	}
+	if m.Len() != 0 {
+		t.Fatalf("全部删除后 Len=%d", m.Len())
+	}
+	m.Ascend(func(k K, v V) bool {
+		t.Fatalf("全部删除后遍历到 %v", k)
+		return false
+	})
+}
+
+func TestOrderedMapConformance(t *testing.T) {
+	intKey := func(i int) int { return i }
+	intValue := func(i int) int { return i }
+	cmp := datastruct.OrderedComparator[int]()
+
+	t.Run("AvlTree", func(t *testing.T) {
+		testOrderedMap(t, datastruct.NewAvlTree[int, int](cmp), intKey, intValue)
+	})
+	t.Run("RBTree", func(t *testing.T) {
+		testOrderedMap(t, rbtree.NewRBTree[int, int](cmp), intKey, intValue)
+	})
+	// btree.BTree 不是泛型的，只能以 OrderedMap[int64, interface{}] 测试
+	for _, order := range []int{3, 4, 7} {
+		t.Run(fmt.Sprintf("BTree/order=%d", order), func(t *testing.T) {
+			testOrderedMap[int64, interface{}](t, btree.NewBTree(order),
+				func(i int) int64 { return int64(i) },
+				func(i int) interface{} { return i })
+		})
+	}
+	for _, order := range []int{3, 4, 32} {
+		t.Run(fmt.Sprintf("BPTree/order=%d", order), func(t *testing.T) {
+			testOrderedMap(t, bptree.NewBPTree[int, int](order, cmp), intKey, intValue)
+		})
+	}
+	t.Run("BPTree/disk", func(t *testing.T) {
+		tree, err := bptree.Open[int, int](filepath.Join(t.TempDir(), "tree.db"), cmp,
+			datastruct.IntegerCodec[int]{}, datastruct.IntegerCodec[int]{},
+			bptree.WithOrder(8), bptree.WithCacheSize(16), bptree.WithSyncWrites(false))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tree.Close()
+		testOrderedMap(t, tree, intKey, intValue)
+		if err := tree.Err(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("SkipList", func(t *testing.T) {
+		testOrderedMap(t, datastruct.NewSkipList[int, int](cmp), intKey, intValue)
+	})
+	t.Run("ConcurrentSkipList", func(t *testing.T) {
+		testOrderedMap(t, datastruct.NewConcurrentSkipList[int, int](cmp), intKey, intValue)
+	})
+	// SkipLinks 只能通过 OrderedMap() 适配器以 OrderedMap[ScoreKey, T] 测试
+	t.Run("SkipLinks.OrderedMap", func(t *testing.T) {
+		// 每 10 个 key 分数相同，相同分数按 Key 排序
+		testOrderedMap(t, datastruct.NewSkipLinked[int](16, 0).OrderedMap(),
+			func(i int) datastruct.ScoreKey {
+				return datastruct.ScoreKey{Score: float64(i / 10), Key: fmt.Sprintf("%04d", i)}
+			}, intValue)
+	})
+}
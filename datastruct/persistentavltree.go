@@ -0,0 +1,186 @@
+package datastruct
+
+/***************Persistent AVL Tree****************/
+
+// PersistentAvlTree 持久化（不可变）AVL 树
+//
+// 每个 PersistentAvlTree 是一个只读的版本，Insert、Delete 不修改当前版本，
+// 而是复制从根到被修改节点路径上的 O(log n) 个节点并返回新的版本，其余节点在新旧版本之间共享。
+// 任意版本都可以被多个 goroutine 并发读取
+type PersistentAvlTree[K, V any] struct {
+	tree AvlTree[K, V] // 当前版本的只读视图
+}
+
+// NewPersistentAvlTree 新建空的持久化 AVL 树
+//
+//	@cmp key 的比较函数
+func NewPersistentAvlTree[K, V any](cmp Comparator[K]) *PersistentAvlTree[K, V] {
+	if cmp == nil {
+		panic("PersistentAvlTree comparator is nil")
+	}
+	return &PersistentAvlTree[K, V]{tree: AvlTree[K, V]{cmp: cmp}}
+}
+
+// version 以 root 为根的新版本
+func (p *PersistentAvlTree[K, V]) version(root *TreeNode[K, V]) *PersistentAvlTree[K, V] {
+	return &PersistentAvlTree[K, V]{tree: AvlTree[K, V]{treeRoot: root, cmp: p.tree.cmp}}
+}
+
+// cloneNode 复制节点，新节点与原节点共享子树
+func cloneNode[K, V any](node *TreeNode[K, V]) *TreeNode[K, V] {
+	c := *node
+	return &c
+}
+
+// Insert 插入或更新 key，返回新的版本，当前版本不变
+func (p *PersistentAvlTree[K, V]) Insert(key K, value V) *PersistentAvlTree[K, V] {
+	return p.version(p.insert(p.tree.treeRoot, key, value))
+}
+
+// insert 复制路径并插入节点，返回子树新的根节点
+func (p *PersistentAvlTree[K, V]) insert(node *TreeNode[K, V], key K, value V) *TreeNode[K, V] {
+	if node == nil {
+		return &TreeNode[K, V]{size: 1, key: key, value: value}
+	}
+	node = cloneNode(node)
+	switch c := p.tree.cmp(key, node.key); {
+	case c < 0:
+		node.left = p.insert(node.left, key, value)
+	case c > 0:
+		node.right = p.insert(node.right, key, value)
+	default:
+		node.value = value
+		return node
+	}
+	return p.balance(node)
+}
+
+// Delete 删除 key，返回新的版本与被删除的 value，当前版本不变；key 不存在时返回当前版本
+func (p *PersistentAvlTree[K, V]) Delete(key K) (*PersistentAvlTree[K, V], V, bool) {
+	root, deleted := p.delete(p.tree.treeRoot, key)
+	if deleted == nil {
+		var zero V
+		return p, zero, false
+	}
+	return p.version(root), deleted.value, true
+}
+
+// delete 复制路径并删除节点，返回子树新的根节点与被删除的节点；未找到时不复制任何节点
+func (p *PersistentAvlTree[K, V]) delete(node *TreeNode[K, V], key K) (*TreeNode[K, V], *TreeNode[K, V]) {
+	if node == nil {
+		return nil, nil
+	}
+	var (
+		child   *TreeNode[K, V]
+		deleted *TreeNode[K, V]
+	)
+	switch c := p.tree.cmp(key, node.key); {
+	case c < 0:
+		if child, deleted = p.delete(node.left, key); deleted == nil {
+			return node, nil
+		}
+		node = cloneNode(node)
+		node.left = child
+	case c > 0:
+		if child, deleted = p.delete(node.right, key); deleted == nil {
+			return node, nil
+		}
+		node = cloneNode(node)
+		node.right = child
+	default:
+		if node.left == nil {
+			return node.right, node
+		} else if node.right == nil {
+			return node.left, node
+		}
+		// 用后继节点(右子树最小的节点)的副本替换
+		right, succ := p.deleteMin(node.right)
+		deleted, node = node, cloneNode(succ)
+		node.left, node.right = deleted.left, right
+	}
+	return p.balance(node), deleted
+}
+
+// deleteMin 复制路径并删除子树中最小的节点，返回子树新的根节点与被删除的节点
+func (p *PersistentAvlTree[K, V]) deleteMin(node *TreeNode[K, V]) (*TreeNode[K, V], *TreeNode[K, V]) {
+	if node.left == nil {
+		return node.right, node
+	}
+	var least *TreeNode[K, V]
+	node = cloneNode(node)
+	node.left, least = p.deleteMin(node.left)
+	return p.balance(node), least
+}
+
+// balance 与 AvlTree.balance 相同，但旋转前先复制会被修改的子节点。node 必须是新复制的节点
+func (p *PersistentAvlTree[K, V]) balance(node *TreeNode[K, V]) *TreeNode[K, V] {
+	t := &p.tree
+	t.update(node)
+	switch bf := t.nodeBf(node); {
+	case bf == 2:
+		node.left = cloneNode(node.left)
+		if t.nodeBf(node.left) < 0 { // 左右
+			node.left.right = cloneNode(node.left.right)
+			node.left = t.leftRotate(node.left)
+		}
+		return t.rightRotate(node)
+	case bf == -2:
+		node.right = cloneNode(node.right)
+		if t.nodeBf(node.right) > 0 { // 右左
+			node.right.left = cloneNode(node.right.left)
+			node.right = t.rightRotate(node.right)
+		}
+		return t.leftRotate(node)
+	}
+	return node
+}
+
+// Len 节点数量
+func (p *PersistentAvlTree[K, V]) Len() int {
+	return p.tree.Len()
+}
+
+// Get 查找 key 对应的 value
+func (p *PersistentAvlTree[K, V]) Get(key K) (V, bool) {
+	return p.tree.Get(key)
+}
+
+// Min 最小的 key 及其 value，空树返回 ok=false
+func (p *PersistentAvlTree[K, V]) Min() (K, V, bool) {
+	return p.tree.Min()
+}
+
+// Max 最大的 key 及其 value，空树返回 ok=false
+func (p *PersistentAvlTree[K, V]) Max() (K, V, bool) {
+	return p.tree.Max()
+}
+
+// Floor 小于等于 key 的最大 key 及其 value，不存在时返回 ok=false
+func (p *PersistentAvlTree[K, V]) Floor(key K) (K, V, bool) {
+	return p.tree.Floor(key)
+}
+
+// Ceiling 大于等于 key 的最小 key 及其 value，不存在时返回 ok=false
+func (p *PersistentAvlTree[K, V]) Ceiling(key K) (K, V, bool) {
+	return p.tree.Ceiling(key)
+}
+
+// Ascend 按 key 升序遍历，fn 返回 false 时停止
+func (p *PersistentAvlTree[K, V]) Ascend(fn func(key K, value V) bool) {
+	p.tree.Ascend(fn)
+}
+
+// Descend 按 key 降序遍历，fn 返回 false 时停止
+func (p *PersistentAvlTree[K, V]) Descend(fn func(key K, value V) bool) {
+	p.tree.Descend(fn)
+}
+
+// Range 按 key 升序遍历 [lo, hi) 区间，fn 返回 false 时停止
+func (p *PersistentAvlTree[K, V]) Range(lo, hi K, fn func(key K, value V) bool) {
+	p.tree.Range(lo, hi, fn)
+}
+
+// Verify 校验当前版本的 AVL 树不变式，返回发现的第一处错误（*InvariantError）
+func (p *PersistentAvlTree[K, V]) Verify() error {
+	return p.tree.Verify()
+}
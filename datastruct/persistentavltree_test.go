@@ -0,0 +1,158 @@
+package datastruct
+
+import (
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+// checkVersion 校验版本的内容与 m 一致
+func checkVersion(t *testing.T, p *PersistentAvlTree[int, int], m map[int]int) {
+	t.Helper()
+	if err := p.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if p.Len() != len(m) {
+		t.Fatalf("Len=%d, 期望 %d", p.Len(), len(m))
+	}
+	for k, v := range m {
+		if got, ok := p.Get(k); !ok || got != v {
+			t.Fatalf("Get(%d)=%d,%v, 期望 %d", k, got, ok, v)
+		}
+	}
+}
+
+// avlNodes 收集子树中的所有节点
+func avlNodes[K, V any](node *TreeNode[K, V], set map[*TreeNode[K, V]]bool) {
+	if node != nil {
+		set[node] = true
+		avlNodes(node.left, set)
+		avlNodes(node.right, set)
+	}
+}
+
+func TestPersistentAvlTree(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	p := NewPersistentAvlTree[int, int](OrderedComparator[int]())
+	versions := []*PersistentAvlTree[int, int]{p}
+	maps := []map[int]int{{}}
+	for i := 0; i < 2000; i++ {
+		m := copyIntMap(maps[len(maps)-1])
+		k := r.Intn(300)
+		if r.Intn(3) == 0 {
+			next, v, ok := p.Delete(k)
+			mv, mok := m[k]
+			if ok != mok || v != mv {
+				t.Fatalf("Delete(%d)=%d,%v, 期望 %d,%v", k, v, ok, mv, mok)
+			}
+			if !ok && next != p {
+				t.Fatalf("删除不存在的 key 不应产生新版本")
+			}
+			delete(m, k)
+			p = next
+		} else {
+			p = p.Insert(k, i)
+			m[k] = i
+		}
+		versions = append(versions, p)
+		maps = append(maps, m)
+	}
+	// 所有旧版本保持不变
+	for i := range versions {
+		checkVersion(t, versions[i], maps[i])
+	}
+}
+
+func copyIntMap(m map[int]int) map[int]int {
+	c := make(map[int]int, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func TestPersistentAvlTreeSharing(t *testing.T) {
+	p := NewPersistentAvlTree[int, int](OrderedComparator[int]())
+	const n = 1 << 12
+	for i := 0; i < n; i++ {
+		p = p.Insert(i, i)
+	}
+	old := make(map[*TreeNode[int, int]]bool)
+	avlNodes(p.tree.treeRoot, old)
+
+	// 每次更新新增的节点数为 O(log n)
+	limit := 3 * (bits.Len(n) + 1)
+	for _, next := range []*PersistentAvlTree[int, int]{p.Insert(n/3, -1), p.Insert(n+1, 0), first(p.Delete(n / 2)), first(p.Delete(0))} {
+		cur := make(map[*TreeNode[int, int]]bool)
+		avlNodes(next.tree.treeRoot, cur)
+		fresh := 0
+		for node := range cur {
+			if !old[node] {
+				fresh++
+			}
+		}
+		if fresh > limit {
+			t.Fatalf("一次更新复制了 %d 个节点, 超过 %d", fresh, limit)
+		}
+		if err := next.Verify(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func first[T, A, B any](t T, _ A, _ B) T {
+	return t
+}
+
+func BenchmarkAvlTreePut(b *testing.B) {
+	tree := NewAvlTree[int, int](OrderedComparator[int]())
+	r := rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Put(r.Intn(1<<20), i)
+	}
+}
+
+func BenchmarkPersistentAvlTreeInsert(b *testing.B) {
+	p := NewPersistentAvlTree[int, int](OrderedComparator[int]())
+	r := rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p = p.Insert(r.Intn(1<<20), i)
+	}
+}
+
+func BenchmarkAvlTreeDelete(b *testing.B) {
+	tree := NewAvlTree[int, int](OrderedComparator[int]())
+	for i := 0; i < 1<<16; i++ {
+		tree.Put(i, i)
+	}
+	r := rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := r.Intn(1 << 16)
+		tree.Delete(k)
+		tree.Put(k, i)
+	}
+}
+
+func BenchmarkPersistentAvlTreeDelete(b *testing.B) {
+	p := NewPersistentAvlTree[int, int](OrderedComparator[int]())
+	for i := 0; i < 1<<16; i++ {
+		p = p.Insert(i, i)
+	}
+	r := rand.New(rand.NewSource(1))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		k := r.Intn(1 << 16)
+		next, _, _ := p.Delete(k)
+		p = next.Insert(k, i)
+	}
+}
@@ -0,0 +1,157 @@
+package rbtree
+
+import (
+	"DataStruct/datastruct"
+	"fmt"
+)
+
+// Interval 闭区间 [Lo, Hi]
+type Interval[T any] struct {
+	Lo, Hi T
+}
+
+// intervalEntry 区间树节点的 value，max 为子树中所有区间的最大右端点
+type intervalEntry[T, V any] struct {
+	value V
+	max   T
+}
+
+// IntervalTree 区间树
+//
+// 基于红黑树实现，区间按 (Lo, Hi) 排序，每个节点额外记录子树中的最大右端点，
+// 查询时跳过最大右端点小于查询区间左端点的子树。相同的区间只保存一个 value
+type IntervalTree[T, V any] struct {
+	tree *RBTree[Interval[T], *intervalEntry[T, V]]
+	cmp  datastruct.Comparator[T]
+}
+
+// NewIntervalTree 新建区间树
+//
+//	@cmp 端点的比较函数
+func NewIntervalTree[T, V any](cmp datastruct.Comparator[T]) *IntervalTree[T, V] {
+	if cmp == nil {
+		panic("IntervalTree comparator is nil")
+	}
+	it := &IntervalTree[T, V]{cmp: cmp}
+	it.tree = NewRBTree[Interval[T], *intervalEntry[T, V]](func(a, b Interval[T]) int {
+		if c := cmp(a.Lo, b.Lo); c != 0 {
+			return c
+		}
+		return cmp(a.Hi, b.Hi)
+	})
+	it.tree.augment = it.augment
+	return it
+}
+
+// augment 重新计算节点的最大右端点
+func (it *IntervalTree[T, V]) augment(node *RBNode[Interval[T], *intervalEntry[T, V]]) {
+	max := node.key.Hi
+	for _, child := range []*RBNode[Interval[T], *intervalEntry[T, V]]{node.left, node.right} {
+		if child != nil && it.cmp(child.value.max, max) > 0 {
+			max = child.value.max
+		}
+	}
+	node.value.max = max
+}
+
+// Len 区间的数量
+func (it *IntervalTree[T, V]) Len() int {
+	return it.tree.Len()
+}
+
+// Insert 插入区间 [lo, hi]，区间已存在时替换 value 并返回旧的 value；lo > hi 时 panic
+func (it *IntervalTree[T, V]) Insert(lo, hi T, value V) (old V, replaced bool) {
+	if it.cmp(lo, hi) > 0 {
+		panic(fmt.Sprintf("IntervalTree: invalid interval [%v, %v]", lo, hi))
+	}
+	iv := Interval[T]{Lo: lo, Hi: hi}
+	if entry, ok := it.tree.Get(iv); ok {
+		old, entry.value = entry.value, value
+		return old, true
+	}
+	it.tree.Put(iv, &intervalEntry[T, V]{value: value, max: hi})
+	return
+}
+
+// Get 查找区间 [lo, hi] 对应的 value
+func (it *IntervalTree[T, V]) Get(lo, hi T) (V, bool) {
+	if entry, ok := it.tree.Get(Interval[T]{Lo: lo, Hi: hi}); ok {
+		return entry.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Delete 删除区间 [lo, hi]，返回被删除的 value
+func (it *IntervalTree[T, V]) Delete(lo, hi T) (V, bool) {
+	entry, ok := it.tree.Delete(Interval[T]{Lo: lo, Hi: hi})
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// Overlapping 按区间顺序遍历所有与 [lo, hi] 相交的区间，fn 返回 false 时停止
+func (it *IntervalTree[T, V]) Overlapping(lo, hi T, fn func(iv Interval[T], value V) bool) {
+	it.overlapping(it.tree.root, lo, hi, fn)
+}
+
+// Stabbing 按区间顺序遍历所有包含 point 的区间，fn 返回 false 时停止
+func (it *IntervalTree[T, V]) Stabbing(point T, fn func(iv Interval[T], value V) bool) {
+	it.overlapping(it.tree.root, point, point, fn)
+}
+
+// overlapping 中序遍历子树中与 [lo, hi] 相交的区间，返回是否继续
+func (it *IntervalTree[T, V]) overlapping(node *RBNode[Interval[T], *intervalEntry[T, V]], lo, hi T, fn func(iv Interval[T], value V) bool) bool {
+	// 子树中所有区间的右端点都小于 lo
+	if node == nil || it.cmp(node.value.max, lo) < 0 {
+		return true
+	}
+	if !it.overlapping(node.left, lo, hi, fn) {
+		return false
+	}
+	// 当前及右子树中所有区间的左端点都大于 hi
+	if it.cmp(node.key.Lo, hi) > 0 {
+		return true
+	}
+	if it.cmp(node.key.Hi, lo) >= 0 && !fn(node.key, node.value.value) {
+		return false
+	}
+	return it.overlapping(node.right, lo, hi, fn)
+}
+
+// Verify 校验区间树的不变式：红黑树的不变式以及每个节点记录的最大右端点，
+// 返回发现的第一处错误（*datastruct.InvariantError）
+func (it *IntervalTree[T, V]) Verify() error {
+	if err := it.tree.Verify(); err != nil {
+		return err
+	}
+	return it.verify(it.tree.root, "root")
+}
+
+// verify 校验子树中的区间与最大右端点
+func (it *IntervalTree[T, V]) verify(node *RBNode[Interval[T], *intervalEntry[T, V]], path string) error {
+	if node == nil {
+		return nil
+	}
+	if it.cmp(node.key.Lo, node.key.Hi) > 0 {
+		return datastruct.NewInvariantError(path, "invalid interval [%v, %v]", node.key.Lo, node.key.Hi)
+	}
+	if err := it.verify(node.left, path+".L"); err != nil {
+		return err
+	}
+	if err := it.verify(node.right, path+".R"); err != nil {
+		return err
+	}
+	max := node.key.Hi
+	for _, child := range []*RBNode[Interval[T], *intervalEntry[T, V]]{node.left, node.right} {
+		if child != nil && it.cmp(child.value.max, max) > 0 {
+			max = child.value.max
+		}
+	}
+	if it.cmp(node.value.max, max) != 0 {
+		return datastruct.NewInvariantError(path, "max endpoint %v, expected %v", node.value.max, max)
+	}
+	return nil
+}
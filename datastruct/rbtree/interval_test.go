@@ -0,0 +1,107 @@
+package rbtree
+
+import (
+	"DataStruct/datastruct"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// 随机插入删除区间，与暴力查找对比
+func TestIntervalTree(t *testing.T) {
+	it := NewIntervalTree[int, int](datastruct.OrderedComparator[int]())
+	ref := make(map[Interval[int]]int)
+	r := rand.New(rand.NewSource(1))
+	query := func(lo, hi int) (got, want []Interval[int]) {
+		it.Overlapping(lo, hi, func(iv Interval[int], value int) bool {
+			if value != ref[iv] {
+				t.Fatalf("区间 %v 的 value 为 %d, 期望 %d", iv, value, ref[iv])
+			}
+			got = append(got, iv)
+			return true
+		})
+		for iv := range ref {
+			if iv.Lo <= hi && lo <= iv.Hi {
+				want = append(want, iv)
+			}
+		}
+		slices.SortFunc(want, func(a, b Interval[int]) int {
+			if a.Lo != b.Lo {
+				return a.Lo - b.Lo
+			}
+			return a.Hi - b.Hi
+		})
+		return
+	}
+
+	for round := 0; round < 30; round++ {
+		for i := 0; i < 100; i++ {
+			lo := r.Intn(1000)
+			iv := Interval[int]{Lo: lo, Hi: lo + r.Intn(50)}
+			if r.Intn(3) == 0 {
+				if r.Intn(2) == 0 { // 删除已存在的区间
+					for exist := range ref {
+						iv = exist
+						break
+					}
+				}
+				want, exist := ref[iv]
+				if v, ok := it.Delete(iv.Lo, iv.Hi); ok != exist || v != want {
+					t.Fatalf("Delete(%v)=%d,%v, 期望 %d,%v", iv, v, ok, want, exist)
+				}
+				delete(ref, iv)
+			} else {
+				it.Insert(iv.Lo, iv.Hi, i)
+				ref[iv] = i
+			}
+		}
+		if err := it.Verify(); err != nil {
+			t.Fatal(err)
+		}
+		if it.Len() != len(ref) {
+			t.Fatalf("Len=%d, 期望 %d", it.Len(), len(ref))
+		}
+		for i := 0; i < 50; i++ {
+			lo := r.Intn(1100) - 50
+			got, want := query(lo, lo+r.Intn(30))
+			if !slices.Equal(got, want) {
+				t.Fatalf("Overlapping 结果 %v, 期望 %v", got, want)
+			}
+		}
+	}
+
+	// Stabbing 与提前终止
+	point := 500
+	var got []Interval[int]
+	it.Stabbing(point, func(iv Interval[int], value int) bool {
+		if iv.Lo > point || iv.Hi < point {
+			t.Fatalf("区间 %v 不包含 %d", iv, point)
+		}
+		got = append(got, iv)
+		return len(got) < 2
+	})
+	if _, want := query(point, point); len(got) != min(2, len(want)) {
+		t.Fatalf("Stabbing 提前终止返回 %d 个区间", len(got))
+	}
+}
+
+func TestIntervalTreeInsert(t *testing.T) {
+	it := NewIntervalTree[int, string](datastruct.OrderedComparator[int]())
+	it.Insert(1, 5, "a")
+	it.Insert(3, 3, "b")
+	if old, ok := it.Insert(1, 5, "c"); !ok || old != "a" {
+		t.Fatalf("替换返回 %q,%v", old, ok)
+	}
+	if v, ok := it.Get(1, 5); !ok || v != "c" {
+		t.Fatalf("Get(1, 5)=%q,%v", v, ok)
+	}
+	if it.Len() != 2 {
+		t.Fatalf("Len=%d, 期望 2", it.Len())
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("lo > hi 应 panic")
+		}
+	}()
+	it.Insert(5, 1, "x")
+}
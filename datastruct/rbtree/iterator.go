@@ -0,0 +1,185 @@
+package rbtree
+
+// Iterator 红黑树的有序迭代器，树被修改后迭代器失效
+type Iterator[K, V any] struct {
+	node *RBNode[K, V]
+}
+
+// Valid 迭代器是否指向一个节点
+func (it *Iterator[K, V]) Valid() bool {
+	return it.node != nil
+}
+
+// Key 当前节点的 key
+func (it *Iterator[K, V]) Key() K {
+	return it.node.key
+}
+
+// Value 当前节点的 value
+func (it *Iterator[K, V]) Value() V {
+	return it.node.value
+}
+
+// Next 移动到后继节点
+func (it *Iterator[K, V]) Next() {
+	it.node = it.node.successor()
+}
+
+// Prev 移动到前驱节点
+func (it *Iterator[K, V]) Prev() {
+	it.node = it.node.predecessor()
+}
+
+// successor 中序遍历的后继节点
+func (rbnode *RBNode[K, V]) successor() *RBNode[K, V] {
+	if rbnode.right != nil {
+		return rbnode.right.min()
+	}
+	node := rbnode
+	for node.parent != nil && node == node.parent.right {
+		node = node.parent
+	}
+	return node.parent
+}
+
+// predecessor 中序遍历的前驱节点
+func (rbnode *RBNode[K, V]) predecessor() *RBNode[K, V] {
+	if rbnode.left != nil {
+		return rbnode.left.max()
+	}
+	node := rbnode
+	for node.parent != nil && node == node.parent.left {
+		node = node.parent
+	}
+	return node.parent
+}
+
+// min 子树中最小的节点
+func (rbnode *RBNode[K, V]) min() *RBNode[K, V] {
+	node := rbnode
+	for node.left != nil {
+		node = node.left
+	}
+	return node
+}
+
+// max 子树中最大的节点
+func (rbnode *RBNode[K, V]) max() *RBNode[K, V] {
+	node := rbnode
+	for node.right != nil {
+		node = node.right
+	}
+	return node
+}
+
+// First 指向最小节点的迭代器
+func (rbtree *RBTree[K, V]) First() *Iterator[K, V] {
+	if rbtree.root == nil {
+		return &Iterator[K, V]{}
+	}
+	return &Iterator[K, V]{node: rbtree.root.min()}
+}
+
+// Last 指向最大节点的迭代器
+func (rbtree *RBTree[K, V]) Last() *Iterator[K, V] {
+	if rbtree.root == nil {
+		return &Iterator[K, V]{}
+	}
+	return &Iterator[K, V]{node: rbtree.root.max()}
+}
+
+// Seek 指向第一个 >= key 的节点的迭代器
+func (rbtree *RBTree[K, V]) Seek(key K) *Iterator[K, V] {
+	return &Iterator[K, V]{node: rbtree.ceiling(key)}
+}
+
+// ceiling 第一个 >= key 的节点
+func (rbtree *RBTree[K, V]) ceiling(key K) *RBNode[K, V] {
+	var res *RBNode[K, V]
+	for node := rbtree.root; node != nil; {
+		c := rbtree.cmp(key, node.key)
+		if c == 0 {
+			return node
+		}
+		if c < 0 {
+			res = node
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return res
+}
+
+// floor 最后一个 <= key 的节点
+func (rbtree *RBTree[K, V]) floor(key K) *RBNode[K, V] {
+	var res *RBNode[K, V]
+	for node := rbtree.root; node != nil; {
+		c := rbtree.cmp(key, node.key)
+		if c == 0 {
+			return node
+		}
+		if c > 0 {
+			res = node
+			node = node.right
+		} else {
+			node = node.left
+		}
+	}
+	return res
+}
+
+// kv 返回节点的 key 与 value，节点为 nil 时返回 ok=false
+func kv[K, V any](node *RBNode[K, V]) (key K, value V, ok bool) {
+	if node == nil {
+		return
+	}
+	return node.key, node.value, true
+}
+
+// Min 最小的 key 及其 value，空树返回 ok=false
+func (rbtree *RBTree[K, V]) Min() (K, V, bool) {
+	return kv(rbtree.First().node)
+}
+
+// Max 最大的 key 及其 value，空树返回 ok=false
+func (rbtree *RBTree[K, V]) Max() (K, V, bool) {
+	return kv(rbtree.Last().node)
+}
+
+// Floor 小于等于 key 的最大 key 及其 value，不存在时返回 ok=false
+func (rbtree *RBTree[K, V]) Floor(key K) (K, V, bool) {
+	return kv(rbtree.floor(key))
+}
+
+// Ceiling 大于等于 key 的最小 key 及其 value，不存在时返回 ok=false
+func (rbtree *RBTree[K, V]) Ceiling(key K) (K, V, bool) {
+	return kv(rbtree.ceiling(key))
+}
+
+// Ascend 按 key 升序遍历，fn 返回 false 时停止
+func (rbtree *RBTree[K, V]) Ascend(fn func(key K, value V) bool) {
+	for it := rbtree.First(); it.Valid(); it.Next() {
+		if !fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
+
+// Descend 按 key 降序遍历，fn 返回 false 时停止
+func (rbtree *RBTree[K, V]) Descend(fn func(key K, value V) bool) {
+	for it := rbtree.Last(); it.Valid(); it.Prev() {
+		if !fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
+
+// Range 按 key 升序遍历 [lo, hi) 区间，fn 返回 false 时停止
+func (rbtree *RBTree[K, V]) Range(lo, hi K, fn func(key K, value V) bool) {
+	for it := rbtree.Seek(lo); it.Valid() && rbtree.cmp(it.Key(), hi) < 0; it.Next() {
+		if !fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
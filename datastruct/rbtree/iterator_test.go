@@ -0,0 +1,61 @@
+package rbtree
+
+import (
+	"DataStruct/datastruct"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestRBTreeRangeAndSeek(t *testing.T) {
+	rbtree := NewRBTree[int, string](datastruct.OrderedComparator[int]())
+	for i := 0; i < 100; i += 2 {
+		rbtree.Put(i, fmt.Sprint(i))
+	}
+
+	var got []int
+	rbtree.Range(11, 21, func(k int, v string) bool {
+		got = append(got, k)
+		return true
+	})
+	if !slices.Equal(got, []int{12, 14, 16, 18, 20}) {
+		t.Errorf("Range(11, 21) = %v", got)
+	}
+
+	// 提前终止
+	got = got[:0]
+	rbtree.Descend(func(k int, v string) bool {
+		got = append(got, k)
+		return len(got) < 3
+	})
+	if !slices.Equal(got, []int{98, 96, 94}) {
+		t.Errorf("Descend 提前终止 = %v", got)
+	}
+
+	it := rbtree.Seek(51)
+	if !it.Valid() || it.Key() != 52 || it.Value() != "52" {
+		t.Fatalf("Seek(51) 应指向 52")
+	}
+	it.Prev()
+	it.Prev()
+	if !it.Valid() || it.Key() != 48 {
+		t.Fatalf("Prev 两次应指向 48")
+	}
+	if it = rbtree.Seek(99); it.Valid() {
+		t.Errorf("Seek(99) 应无效，实际指向 %d", it.Key())
+	}
+	if it = rbtree.Last(); !it.Valid() || it.Key() != 98 {
+		t.Errorf("Last 应指向 98")
+	}
+	if it.Next(); it.Valid() {
+		t.Errorf("Last 之后应无效")
+	}
+
+	empty := NewRBTree[int, int](datastruct.OrderedComparator[int]())
+	if empty.First().Valid() || empty.Last().Valid() || empty.Seek(0).Valid() {
+		t.Error("空树迭代器应无效")
+	}
+	if _, _, ok := empty.Min(); ok {
+		t.Error("空树 Min 应返回 ok=false")
+	}
+}
@@ -0,0 +1,264 @@
+package rbtree
+
+import "fmt"
+
+// 本文件中的子树以 (根节点, 黑高) 表示，黑高为从根到叶子路径上黑色节点的数量（包含根，不含 nil），
+// 子树的根节点 parent 为 nil，但可以是红色
+
+// blackHeight 子树的黑高
+func (rbnode *RBNode[K, V]) blackHeight() int {
+	h := 0
+	for node := rbnode; node != nil; node = node.left {
+		if node.color == BLACK {
+			h++
+		}
+	}
+	return h
+}
+
+// childHeight 孩子子树的黑高，h 为 node 的黑高
+func (rbnode *RBNode[K, V]) childHeight(h int) int {
+	if rbnode.color == BLACK {
+		return h - 1
+	}
+	return h
+}
+
+// setParent 设置节点的父节点，节点可以为 nil
+func (rbnode *RBNode[K, V]) setParent(parent *RBNode[K, V]) {
+	if rbnode != nil {
+		rbnode.parent = parent
+	}
+}
+
+// detach 摘下节点的左右子树作为独立的子树返回，node 变为孤立的红色节点
+func (rbtree *RBTree[K, V]) detach(node *RBNode[K, V]) (left, right *RBNode[K, V]) {
+	left, right = node.left, node.right
+	left.setParent(nil)
+	right.setParent(nil)
+	node.left, node.right, node.parent = nil, nil, nil
+	node.color, node.size = RED, 1
+	rbtree.augmentNode(node)
+	return
+}
+
+// join 以孤立节点 node 合并 left 与 right，要求 left 中的 key < node.key < right 中的 key，返回新的子树。
+// 沿较高子树的侧边下降到黑高与较矮子树相同的黑色节点处挂上红色的 node，再按插入的规则向上调整，
+// 时间复杂度 O(|lh - rh| + 1)
+func (rbtree *RBTree[K, V]) join(left *RBNode[K, V], lh int, node, right *RBNode[K, V], rh int) (*RBNode[K, V], int) {
+	// 子树的根节点染为黑色
+	if !left.isBlack() {
+		left.color = BLACK
+		lh++
+	}
+	if !right.isBlack() {
+		right.color = BLACK
+		rh++
+	}
+	if lh == rh {
+		node.color = BLACK
+		node.left, node.right = left, right
+		left.setParent(node)
+		right.setParent(node)
+		node.updateSize()
+		rbtree.augmentNode(node)
+		return node, lh + 1
+	}
+
+	var (
+		sub    = &RBTree[K, V]{cmp: rbtree.cmp, augment: rbtree.augment}
+		parent *RBNode[K, V]
+		child  *RBNode[K, V]
+	)
+	if lh > rh {
+		// 沿 left 的右侧路径找到黑高为 rh 的黑色节点
+		sub.root, child = left, left
+		for h := lh; !child.isBlack() || h != rh; parent, child = child, child.right {
+			if child.isBlack() {
+				h--
+			}
+		}
+		node.left, node.right = child, right
+		parent.right = node
+	} else {
+		// 沿 right 的左侧路径找到黑高为 lh 的黑色节点
+		sub.root, child = right, right
+		for h := rh; !child.isBlack() || h != lh; parent, child = child, child.left {
+			if child.isBlack() {
+				h--
+			}
+		}
+		node.left, node.right = left, child
+		parent.left = node
+	}
+	node.parent = parent
+	node.left.setParent(node)
+	node.right.setParent(node)
+	node.color = RED
+	node.updateSize()
+	rbtree.augmentNode(node)
+	for p := parent; p != nil; p = p.parent {
+		p.updateSize()
+		rbtree.augmentNode(p)
+	}
+	h := max(lh, rh)
+	if sub.insertCheck(node) {
+		h++
+	}
+	return sub.root, h
+}
+
+// join2 合并 left 与 right，要求 left 中的 key < right 中的 key，返回新的子树
+func (rbtree *RBTree[K, V]) join2(left *RBNode[K, V], lh int, right *RBNode[K, V], rh int) (*RBNode[K, V], int) {
+	if left == nil {
+		return right, rh
+	}
+	rest, resth, last := rbtree.splitLast(left, lh)
+	return rbtree.join(rest, resth, last, right, rh)
+}
+
+// splitLast 摘下子树中最大的节点，返回剩余的子树与被摘下的孤立节点
+func (rbtree *RBTree[K, V]) splitLast(node *RBNode[K, V], h int) (rest *RBNode[K, V], resth int, last *RBNode[K, V]) {
+	ch := node.childHeight(h)
+	left, right := rbtree.detach(node)
+	if right == nil {
+		return left, ch, node
+	}
+	rest, resth, last = rbtree.splitLast(right, ch)
+	rest, resth = rbtree.join(left, ch, node, rest, resth)
+	return
+}
+
+// split 按 key 拆分子树，返回小于 key 的子树、key 所在的孤立节点（不存在时为 nil）与大于 key 的子树
+func (rbtree *RBTree[K, V]) split(node *RBNode[K, V], h int, key K) (left *RBNode[K, V], lh int, found, right *RBNode[K, V], rh int) {
+	if node == nil {
+		return
+	}
+	ch := node.childHeight(h)
+	l, r := rbtree.detach(node)
+	switch c := rbtree.cmp(key, node.key); {
+	case c < 0:
+		left, lh, found, right, rh = rbtree.split(l, ch, key)
+		right, rh = rbtree.join(right, rh, node, r, ch)
+	case c > 0:
+		left, lh, found, right, rh = rbtree.split(r, ch, key)
+		left, lh = rbtree.join(l, ch, node, left, lh)
+	default:
+		return l, ch, node, r, ch
+	}
+	return
+}
+
+// union 合并两棵子树，key 相同时使用 other 中的 value
+func (rbtree *RBTree[K, V]) union(node *RBNode[K, V], h int, other *RBNode[K, V], oh int) (*RBNode[K, V], int) {
+	if node == nil {
+		return other, oh
+	}
+	if other == nil {
+		return node, h
+	}
+	ch := node.childHeight(h)
+	l, r := rbtree.detach(node)
+	left, lh, found, right, rh := rbtree.split(other, oh, node.key)
+	if found != nil {
+		node.value = found.value
+	}
+	left, lh = rbtree.union(l, ch, left, lh)
+	right, rh = rbtree.union(r, ch, right, rh)
+	return rbtree.join(left, lh, node, right, rh)
+}
+
+// intersection 保留 node 中同时存在于 other 的节点，other 不会被修改
+func (rbtree *RBTree[K, V]) intersection(node *RBNode[K, V], h int, other *RBNode[K, V]) (*RBNode[K, V], int) {
+	if node == nil || other == nil {
+		return nil, 0
+	}
+	left, lh, found, right, rh := rbtree.split(node, h, other.key)
+	left, lh = rbtree.intersection(left, lh, other.left)
+	right, rh = rbtree.intersection(right, rh, other.right)
+	if found != nil {
+		return rbtree.join(left, lh, found, right, rh)
+	}
+	return rbtree.join2(left, lh, right, rh)
+}
+
+// difference 删除 node 中存在于 other 的节点，other 不会被修改
+func (rbtree *RBTree[K, V]) difference(node *RBNode[K, V], h int, other *RBNode[K, V]) (*RBNode[K, V], int) {
+	if node == nil || other == nil {
+		return node, h
+	}
+	left, lh, _, right, rh := rbtree.split(node, h, other.key)
+	left, lh = rbtree.difference(left, lh, other.left)
+	right, rh = rbtree.difference(right, rh, other.right)
+	return rbtree.join2(left, lh, right, rh)
+}
+
+// setRoot 以子树 root 作为整棵树
+func (rbtree *RBTree[K, V]) setRoot(root *RBNode[K, V]) {
+	if root != nil {
+		root.color = BLACK
+	}
+	rbtree.root = root
+	rbtree.length = root.sizeOf()
+}
+
+// Join 将 key、value 以及 right 中的所有节点合并到 rbtree 中，
+// 要求 rbtree 中所有 key 小于 key，right 中所有 key 大于 key，否则 panic。
+// right 的节点被移入 rbtree，调用后 right 为空。时间复杂度 O(log n)
+func (rbtree *RBTree[K, V]) Join(key K, value V, right *RBTree[K, V]) {
+	if k, _, ok := rbtree.Max(); ok && rbtree.cmp(k, key) >= 0 {
+		panic(fmt.Sprintf("RBTree.Join: key %v is not greater than %v", key, k))
+	}
+	if k, _, ok := right.Min(); ok && rbtree.cmp(key, k) >= 0 {
+		panic(fmt.Sprintf("RBTree.Join: key %v is not less than %v", key, k))
+	}
+	root, _ := rbtree.join(rbtree.root, rbtree.root.blackHeight(), NewRBNode(key, value), right.root, right.root.blackHeight())
+	rbtree.setRoot(root)
+	right.setRoot(nil)
+}
+
+// Split 按 key 将 rbtree 拆分为 key 小于 key 与大于 key 的两棵树，key 存在时同时返回它的 value。
+// rbtree 的节点被移入返回的两棵树，调用后 rbtree 为空。时间复杂度 O(log n)
+func (rbtree *RBTree[K, V]) Split(key K) (left, right *RBTree[K, V], value V, found bool) {
+	l, _, node, r, _ := rbtree.split(rbtree.root, rbtree.root.blackHeight(), key)
+	rbtree.setRoot(nil)
+	left = &RBTree[K, V]{cmp: rbtree.cmp, augment: rbtree.augment}
+	left.setRoot(l)
+	right = &RBTree[K, V]{cmp: rbtree.cmp, augment: rbtree.augment}
+	right.setRoot(r)
+	if node != nil {
+		value, found = node.value, true
+	}
+	return
+}
+
+// Union 将 other 合并到 rbtree 中，key 相同时使用 other 中的 value。
+// other 的节点被移入 rbtree，调用后 other 为空。两棵树必须使用相同的 key 顺序，
+// 大小分别为 m <= n 时时间复杂度 O(m log(n/m + 1))
+func (rbtree *RBTree[K, V]) Union(other *RBTree[K, V]) {
+	if other == rbtree {
+		return
+	}
+	root, _ := rbtree.union(rbtree.root, rbtree.root.blackHeight(), other.root, other.root.blackHeight())
+	rbtree.setRoot(root)
+	other.setRoot(nil)
+}
+
+// Intersection 只保留 rbtree 中同时存在于 other 的 key，value 不变，other 不会被修改。时间复杂度同 Union
+func (rbtree *RBTree[K, V]) Intersection(other *RBTree[K, V]) {
+	if other == rbtree {
+		return
+	}
+	root, _ := rbtree.intersection(rbtree.root, rbtree.root.blackHeight(), other.root)
+	rbtree.setRoot(root)
+}
+
+// Difference 删除 rbtree 中存在于 other 的 key，other 不会被修改。时间复杂度同 Union
+func (rbtree *RBTree[K, V]) Difference(other *RBTree[K, V]) {
+	if other == rbtree {
+		rbtree.setRoot(nil)
+		return
+	}
+	root, _ := rbtree.difference(rbtree.root, rbtree.root.blackHeight(), other.root)
+	rbtree.setRoot(root)
+}
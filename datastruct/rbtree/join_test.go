@@ -0,0 +1,149 @@
+package rbtree
+
+import (
+	"DataStruct/datastruct"
+	"math/rand"
+	"testing"
+)
+
+// randomRBTree 随机生成 n 个 [0, limit) 中的 key，value 为 key*10+tag
+func randomRBTree(r *rand.Rand, n, limit, tag int) (*RBTree[int, int], map[int]int) {
+	tree := NewRBTree[int, int](datastruct.OrderedComparator[int]())
+	m := make(map[int]int)
+	for i := 0; i < n; i++ {
+		k := r.Intn(limit)
+		tree.Put(k, k*10+tag)
+		m[k] = k*10 + tag
+	}
+	return tree, m
+}
+
+// checkContent 校验树的不变式以及内容与 m 一致
+func checkContent(t *testing.T, tree *RBTree[int, int], m map[int]int) {
+	t.Helper()
+	if err := tree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if tree.Len() != len(m) {
+		t.Fatalf("Len=%d, 期望 %d", tree.Len(), len(m))
+	}
+	tree.Ascend(func(k, v int) bool {
+		if mv, ok := m[k]; !ok || mv != v {
+			t.Fatalf("key %d 的 value 为 %d, 期望 %d,%v", k, v, mv, ok)
+		}
+		return true
+	})
+}
+
+// clone 复制一棵树，用于测试会清空参数的集合操作
+func (rbtree *RBTree[K, V]) clone() *RBTree[K, V] {
+	c := NewRBTree[K, V](rbtree.cmp)
+	rbtree.Ascend(func(key K, value V) bool {
+		c.Put(key, value)
+		return true
+	})
+	return c
+}
+
+func TestRBTreeJoinSplit(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{0, 1, 2, 10, 100, 1000} {
+		for i := 0; i < 20; i++ {
+			tree, m := randomRBTree(r, n, 2*n+1, 0)
+			key := r.Intn(2*n + 1)
+			left, right, value, found := tree.Split(key)
+			if mv, ok := m[key]; ok != found || mv != value {
+				t.Fatalf("Split(%d) 返回 %d,%v, 期望 %d,%v", key, value, found, mv, ok)
+			}
+			if tree.Len() != 0 {
+				t.Fatalf("Split 后原树不为空")
+			}
+			lm, rm := make(map[int]int), make(map[int]int)
+			for k, v := range m {
+				if k < key {
+					lm[k] = v
+				} else if k > key {
+					rm[k] = v
+				}
+			}
+			checkContent(t, left, lm)
+			checkContent(t, right, rm)
+
+			left.Join(key, -1, right)
+			m[key] = -1
+			checkContent(t, left, m)
+			checkContent(t, right, map[int]int{})
+		}
+	}
+
+	// 黑高相差很大的两棵树，分别以较矮的树作为左右两边
+	rangeTree := func(lo, hi int, m map[int]int) *RBTree[int, int] {
+		tree := NewRBTree[int, int](datastruct.OrderedComparator[int]())
+		for k := lo; k < hi; k++ {
+			tree.Put(k, k)
+			m[k] = k
+		}
+		return tree
+	}
+	for _, c := range [][5]int{{0, 3, 50, 100, 5000}, {0, 4900, 4950, 5000, 5003}} {
+		m := map[int]int{c[2]: c[2]}
+		left, right := rangeTree(c[0], c[1], m), rangeTree(c[3], c[4], m)
+		left.Join(c[2], c[2], right)
+		checkContent(t, left, m)
+	}
+}
+
+func TestRBTreeJoinPanic(t *testing.T) {
+	for _, key := range []int{5, 10} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Join(%d) 未 panic", key)
+				}
+			}()
+			left := NewRBTree[int, int](datastruct.OrderedComparator[int]())
+			right := NewRBTree[int, int](datastruct.OrderedComparator[int]())
+			left.Put(5, 5)
+			right.Put(10, 10)
+			left.Join(key, 0, right)
+		}()
+	}
+}
+
+func TestRBTreeSetOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	sizes := [][2]int{{0, 0}, {0, 50}, {50, 0}, {1, 1000}, {1000, 1}, {100, 100}, {20, 2000}, {2000, 20}}
+	for _, sz := range sizes {
+		limit := sz[0] + sz[1] + 1
+		a, am := randomRBTree(r, sz[0], limit, 1)
+		b, bm := randomRBTree(r, sz[1], limit, 2)
+		union, inter, diff := make(map[int]int), make(map[int]int), make(map[int]int)
+		for k, v := range am {
+			union[k] = v
+			if _, ok := bm[k]; ok {
+				inter[k] = v
+			} else {
+				diff[k] = v
+			}
+		}
+		for k, v := range bm {
+			union[k] = v
+		}
+
+		u := NewRBTree[int, int](datastruct.OrderedComparator[int]())
+		u.Union(a.clone())
+		u.Union(b.clone())
+		checkContent(t, u, union)
+
+		// Intersection 与 Difference 不修改参数树
+		i := a.clone()
+		i.Intersection(b)
+		checkContent(t, i, inter)
+		checkContent(t, b, bm)
+
+		d := a.clone()
+		d.Difference(b)
+		checkContent(t, d, diff)
+		checkContent(t, b, bm)
+	}
+}
@@ -0,0 +1,39 @@
+package rbtree
+
+// Select 第 k 小（从 0 开始）的 key 及其 value，k 超出 [0, Len) 时返回 ok=false
+func (rbtree *RBTree[K, V]) Select(k int) (key K, value V, ok bool) {
+	if k < 0 || k >= rbtree.length {
+		return
+	}
+	node := rbtree.root
+	for {
+		ls := node.left.sizeOf()
+		if k == ls {
+			return node.key, node.value, true
+		}
+		if k < ls {
+			node = node.left
+		} else {
+			k -= ls + 1
+			node = node.right
+		}
+	}
+}
+
+// Rank 小于 key 的 key 的数量，key 存在时即为它在升序中的下标
+func (rbtree *RBTree[K, V]) Rank(key K) int {
+	rank := 0
+	for node := rbtree.root; node != nil; {
+		c := rbtree.cmp(key, node.key)
+		if c <= 0 {
+			if c == 0 {
+				return rank + node.left.sizeOf()
+			}
+			node = node.left
+		} else {
+			rank += node.left.sizeOf() + 1
+			node = node.right
+		}
+	}
+	return rank
+}
@@ -0,0 +1,52 @@
+package rbtree
+
+import (
+	"DataStruct/datastruct"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// 随机插入删除，与有序切片对比 Select 与 Rank
+func TestRBTreeRank(t *testing.T) {
+	rbtree := NewRBTree[int, int](datastruct.OrderedComparator[int]())
+	var sorted []int
+	r := rand.New(rand.NewSource(1))
+	for round := 0; round < 50; round++ {
+		for i := 0; i < 200; i++ {
+			key := r.Intn(2000)
+			idx, found := slices.BinarySearch(sorted, key)
+			if r.Intn(3) == 0 {
+				rbtree.Delete(key)
+				if found {
+					sorted = slices.Delete(sorted, idx, idx+1)
+				}
+			} else {
+				rbtree.Put(key, -key)
+				if !found {
+					sorted = slices.Insert(sorted, idx, key)
+				}
+			}
+		}
+		if err := rbtree.Verify(); err != nil {
+			t.Fatal(err)
+		}
+
+		for k, want := range sorted {
+			if key, value, ok := rbtree.Select(k); !ok || key != want || value != -want {
+				t.Fatalf("Select(%d)=%d,%v, 期望 %d", k, key, ok, want)
+			}
+		}
+		for _, k := range []int{-1, len(sorted)} {
+			if _, _, ok := rbtree.Select(k); ok {
+				t.Fatalf("Select(%d) 应返回 ok=false", k)
+			}
+		}
+		for key := -1; key <= 2000; key += 7 {
+			want, _ := slices.BinarySearch(sorted, key)
+			if got := rbtree.Rank(key); got != want {
+				t.Fatalf("Rank(%d)=%d, 期望 %d", key, got, want)
+			}
+		}
+	}
+}
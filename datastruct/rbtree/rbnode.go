@@ -10,22 +10,44 @@ const (
 )
 
 // RBNode 红黑树节点
-type RBNode struct {
-	value               int64
+type RBNode[K, V any] struct {
+	key                 K
+	value               V
 	color               int
-	left, right, parent *RBNode
+	size                int // 以该节点为根的子树的节点数量
+	left, right, parent *RBNode[K, V]
 }
 
 // NewRBNode 新建节点
-func NewRBNode(value int64) *RBNode {
-	return &RBNode{
+func NewRBNode[K, V any](key K, value V) *RBNode[K, V] {
+	return &RBNode[K, V]{
+		key:   key,
 		value: value,
 		color: RED,
+		size:  1,
 	}
 }
 
+// sizeOf 子树的节点数量，nil 节点为 0
+func (rbnode *RBNode[K, V]) sizeOf() int {
+	if rbnode == nil {
+		return 0
+	}
+	return rbnode.size
+}
+
+// updateSize 根据左右子树重新计算 size
+func (rbnode *RBNode[K, V]) updateSize() {
+	rbnode.size = rbnode.left.sizeOf() + rbnode.right.sizeOf() + 1
+}
+
+// isBlack 是否为黑色，nil 节点为黑色
+func (rbnode *RBNode[K, V]) isBlack() bool {
+	return rbnode == nil || rbnode.color == BLACK
+}
+
 // getGrandParent 获取父节点的父节点
-func (rbnode *RBNode) getGrandParent() *RBNode {
+func (rbnode *RBNode[K, V]) getGrandParent() *RBNode[K, V] {
 	if rbnode == nil || rbnode.parent == nil {
 		return nil
 	}
@@ -33,7 +55,7 @@ func (rbnode *RBNode) getGrandParent() *RBNode {
 }
 
 // getSibling 获取兄弟节点
-func (rbnode *RBNode) getSibling() *RBNode {
+func (rbnode *RBNode[K, V]) getSibling() *RBNode[K, V] {
 	if rbnode == nil || rbnode.parent == nil {
 		return nil
 	}
@@ -45,7 +67,7 @@ func (rbnode *RBNode) getSibling() *RBNode {
 }
 
 // getUncle 获取父节点的兄弟节点
-func (rbnode *RBNode) getUncle() *RBNode {
+func (rbnode *RBNode[K, V]) getUncle() *RBNode[K, V] {
 	if rbnode.getGrandParent() == nil {
 		return nil
 	}
@@ -59,8 +81,8 @@ func (rbnode *RBNode) getUncle() *RBNode {
 
 // rotate 左旋/右旋(true/false)
 // 若根节点发生了改变则返回根节点
-func (rbnode *RBNode) rotate(isRotateLeft bool) (*RBNode, error) {
-	var root *RBNode
+func (rbnode *RBNode[K, V]) rotate(isRotateLeft bool) (*RBNode[K, V], error) {
+	var root *RBNode[K, V]
 	if rbnode == nil {
 		return root, nil
 	}
@@ -94,6 +116,10 @@ func (rbnode *RBNode) rotate(isRotateLeft bool) (*RBNode, error) {
 		}
 	}
 
+	// 旋转后子树的节点数量不变，只需要重新计算下移的节点
+	rbnode.parent.size = rbnode.size
+	rbnode.updateSize()
+
 	if parent == nil { // 旋转完毕找到根节点
 		rbnode.parent.parent = nil
 		root = rbnode.parent
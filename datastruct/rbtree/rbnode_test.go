@@ -5,8 +5,8 @@ import (
 	"testing"
 )
 
-func addSon(value int64, parent *RBNode, isleft bool) *RBNode {
-	son := NewRBNode(value)
+func addSon(value int64, parent *RBNode[int64, int64], isleft bool) *RBNode[int64, int64] {
+	son := NewRBNode(value, value)
 	son.parent = parent
 	if isleft {
 		parent.left = son
@@ -17,7 +17,7 @@ func addSon(value int64, parent *RBNode, isleft bool) *RBNode {
 }
 
 func Test_rbnode_rotate(t *testing.T) {
-	root := NewRBNode(1)
+	root := NewRBNode[int64, int64](1, 1)
 	l := addSon(2, root, LEFTROTATE)
 	r := addSon(3, root, RIGHTROTATE)
 	addSon(4, l, LEFTROTATE)
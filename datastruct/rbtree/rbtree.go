@@ -1,81 +1,131 @@
 package rbtree
 
 import (
+	"DataStruct/datastruct"
 	"log"
 )
 
-type RBTree struct {
-	root *RBNode
+type RBTree[K, V any] struct {
+	root    *RBNode[K, V]
+	cmp     datastruct.Comparator[K] // key 比较函数
+	length  int                      // 节点数量
+	augment func(node *RBNode[K, V]) // 子树变化后重新计算节点的附加信息（如区间树的最大端点），可以为 nil
 }
 
+var _ datastruct.OrderedMap[int, int] = (*RBTree[int, int])(nil)
+
 // NewRBTree 新建红黑树
-func NewRBTree() *RBTree {
-	return &RBTree{root: nil}
+//
+//	@cmp key 的比较函数
+func NewRBTree[K, V any](cmp datastruct.Comparator[K]) *RBTree[K, V] {
+	if cmp == nil {
+		panic("RBTree comparator is nil")
+	}
+	return &RBTree[K, V]{root: nil, cmp: cmp}
 }
 
 // 左旋
-func (rbtree *RBTree) rotateLeft(node *RBNode) {
+func (rbtree *RBTree[K, V]) rotateLeft(node *RBNode[K, V]) {
 	if tempNode, err := node.rotate(LEFTROTATE); err == nil {
 		// 根节点可能发生改动
 		if tempNode != nil {
 			rbtree.root = tempNode
 		}
+		rbtree.augmentNode(node)
+		rbtree.augmentNode(node.parent)
 	}
 }
 
 // 右旋
-func (rbtree *RBTree) rotateRight(node *RBNode) {
+func (rbtree *RBTree[K, V]) rotateRight(node *RBNode[K, V]) {
 	if tempNode, err := node.rotate(RIGHTROTATE); err == nil {
 		// 根节点可能发生改动
 		if tempNode != nil {
 			rbtree.root = tempNode
 		}
+		rbtree.augmentNode(node)
+		rbtree.augmentNode(node.parent)
 	} else {
 		log.Println(err)
 	}
 }
 
-// Insert 新增节点
-func (rbtree *RBTree) Insert(data int64) {
-	if rbtree.root == nil {
-		rootNode := NewRBNode(data)
-		rootNode.color = BLACK
-		rbtree.root = rootNode
-	} else {
-		rbtree.insertNode(rbtree.root, data)
+// augmentNode 重新计算节点的附加信息，孩子节点必须已经是最新的
+func (rbtree *RBTree[K, V]) augmentNode(node *RBNode[K, V]) {
+	if rbtree.augment != nil && node != nil {
+		rbtree.augment(node)
 	}
 }
 
-// insertNode 找到插入位置
-func (rbtree *RBTree) insertNode(pnode *RBNode, data int64) {
-	if data <= pnode.value {
-		if pnode.left != nil {
-			rbtree.insertNode(pnode.left, data)
-		} else {
-			node := NewRBNode(data)
-			node.parent = pnode
-			pnode.left = node
-			rbtree.insertCheck(node) // 插入校验
+// Len 节点数量
+func (rbtree *RBTree[K, V]) Len() int {
+	return rbtree.length
+}
+
+// find 查找 key 所在的节点，不存在时返回 nil 以及应插入位置的父节点
+func (rbtree *RBTree[K, V]) find(key K) (node, parent *RBNode[K, V]) {
+	node = rbtree.root
+	for node != nil {
+		c := rbtree.cmp(key, node.key)
+		if c == 0 {
+			return node, node.parent
 		}
-	} else {
-		if pnode.right != nil {
-			rbtree.insertNode(pnode.right, data)
+		parent = node
+		if c < 0 {
+			node = node.left
 		} else {
-			node := NewRBNode(data)
-			node.parent = pnode
-			pnode.right = node
-			rbtree.insertCheck(node) // 插入校验
+			node = node.right
 		}
 	}
+	return nil, parent
 }
 
-// insertCheck 红黑树插入规则判断
-func (rbtree *RBTree) insertCheck(node *RBNode) {
+// Get 查找 key 对应的 value
+func (rbtree *RBTree[K, V]) Get(key K) (V, bool) {
+	if node, _ := rbtree.find(key); node != nil {
+		return node.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Put 插入或更新 key，key 已存在时返回旧的 value 与 replaced=true
+func (rbtree *RBTree[K, V]) Put(key K, value V) (old V, replaced bool) {
+	node, parent := rbtree.find(key)
+	if node != nil {
+		old, node.value = node.value, value
+		return old, true
+	}
+	node = NewRBNode(key, value)
+	rbtree.length++
+	rbtree.augmentNode(node)
+	if parent == nil {
+		node.color = BLACK
+		rbtree.root = node
+		return
+	}
+	node.parent = parent
+	if rbtree.cmp(key, parent.key) < 0 {
+		parent.left = node
+	} else {
+		parent.right = node
+	}
+	for p := parent; p != nil; p = p.parent {
+		p.size++
+		rbtree.augmentNode(p)
+	}
+	rbtree.insertCheck(node) // 插入校验
+	return
+}
+
+// insertCheck 红黑树插入规则判断，返回根节点是否由红色变为黑色（此时树的黑高加一）
+func (rbtree *RBTree[K, V]) insertCheck(node *RBNode[K, V]) bool {
 	if node.parent == nil {
 		// 根节点，改变颜色为黑色
+		grown := node.color == RED
 		rbtree.root = node
 		rbtree.root.color = BLACK
-		return
+		return grown
 	}
 
 	if node.parent.color == BLACK {
@@ -85,7 +135,7 @@ func (rbtree *RBTree) insertCheck(node *RBNode) {
 			node.parent.color = BLACK
 			node.getUncle().color = BLACK
 			node.getGrandParent().color = RED
-			rbtree.insertCheck(node.getGrandParent()) // 继续向上递归处理
+			return rbtree.insertCheck(node.getGrandParent()) // 继续向上递归处理
 		} else { // 叔叔节点为黑色或没有
 			isleft := node == node.parent.left
 			isParentLeft := node.parent == node.getGrandParent().left
@@ -112,135 +162,122 @@ func (rbtree *RBTree) insertCheck(node *RBNode) {
 			}
 		}
 	}
+	return false
 }
 
-// Delete 删除节点
-func (rbtree *RBTree) Delete(data int64) bool {
-	return rbtree.deleteNode(rbtree.root, data)
+// Delete 删除 key，返回被删除的 value
+func (rbtree *RBTree[K, V]) Delete(key K) (V, bool) {
+	node, _ := rbtree.find(key)
+	if node == nil {
+		var zero V
+		return zero, false
+	}
+	value := node.value
+	rbtree.deleteNode(node)
+	rbtree.length--
+	return value, true
 }
 
-func (rbtree *RBTree) deleteNode(node *RBNode, data int64) bool {
-	if data < node.value {
-		if node.left != nil {
-			return rbtree.deleteNode(node.left, data)
-		}
-		return false
-	} else if data > node.value {
-		if node.right != nil {
-			return rbtree.deleteNode(node.right, data)
-		}
-		return false
-	} else {
-		// 找到 delNode 后继节点(右子树最小的节点)
-		var delNode *RBNode
-		if node.right == nil {
-			delNode = node
-		} else {
-			r := node.right
-			rl := r.left
-			for rl != nil && rl.left != nil {
-				r = rl
-				rl = rl.left
-			}
-			if rl == nil {
-				delNode = r
-			} else {
-				delNode = rl
-			}
+// deleteNode 从树中摘除节点
+func (rbtree *RBTree[K, V]) deleteNode(node *RBNode[K, V]) {
+	if node.left != nil && node.right != nil {
+		// 找到后继节点(右子树最小的节点)，用它的 kv 替换后转化为删除后继节点
+		succ := node.right
+		for succ.left != nil {
+			succ = succ.left
 		}
-		node.value = delNode.value
-		rbtree.deleteCheck(delNode)
-		return true
+		node.key, node.value = succ.key, succ.value
+		node = succ
 	}
-}
 
-func (rbtree *RBTree) deleteCheck(delNode *RBNode) {
-	if delNode.parent == nil {
-		rbtree.root.color = BLACK
-		return
+	// 此时 node 最多只有一个孩子，用孩子替换 node
+	child := node.left
+	if child == nil {
+		child = node.right
+	}
+	parent := node.parent
+	if child != nil {
+		child.parent = parent
+	}
+	if parent == nil {
+		rbtree.root = child
+	} else if node == parent.left {
+		parent.left = child
+	} else {
+		parent.right = child
+	}
+	for p := parent; p != nil; p = p.parent {
+		p.size--
+		rbtree.augmentNode(p)
 	}
+	if node.color == BLACK { // 删除黑色节点后经过 child 的路径少了一个黑色节点
+		rbtree.deleteCheck(child, parent)
+	}
+	node.left, node.right, node.parent = nil, nil, nil
+}
 
-	sibNode := delNode.getSibling() // 删除节点的兄弟节点
-	isleft := delNode == delNode.parent.left
-	if sibNode != nil && sibNode.color == RED { // 删除节点的兄弟节点为红色
-		// 3.2
-		sibNode.color = BLACK
-		if isleft {
-			if sibNode.left != nil {
-				sibNode.left.color = RED
-			}
-			rbtree.rotateLeft(delNode.parent)
-			delNode.parent.left = delNode.left
-		} else {
-			if sibNode.right != nil {
-				sibNode.right.color = RED
+// deleteCheck 红黑树删除规则判断
+// node 所在路径比兄弟路径少一个黑色节点，node 可能为 nil，因此同时传入父节点
+func (rbtree *RBTree[K, V]) deleteCheck(node, parent *RBNode[K, V]) {
+	for node != rbtree.root && node.isBlack() {
+		if node == parent.left {
+			sibNode := parent.right
+			if !sibNode.isBlack() { // 兄弟节点为红色，转化为兄弟节点为黑色
+				sibNode.color = BLACK
+				parent.color = RED
+				rbtree.rotateLeft(parent)
+				sibNode = parent.right
 			}
-			rbtree.rotateRight(delNode.parent)
-			delNode.parent.right = delNode.right
-		}
-		delNode.parent = nil
-		delNode = nil
-	} else { // 删除节点的兄弟节点为黑色或没有
-		if (sibNode == nil) || (sibNode.left == nil && sibNode.right == nil) {
-			// 3.1.4
-			delNode.parent.color = BLACK
-			if sibNode != nil {
+			if sibNode.left.isBlack() && sibNode.right.isBlack() { // 兄弟节点的孩子都是黑色，问题上移到父节点
 				sibNode.color = RED
+				node, parent = parent, parent.parent
+				continue
 			}
-			if isleft {
-				delNode.parent.left = delNode.left
-			} else {
-				delNode.parent.right = delNode.right
-			}
-		} else if sibNode.right != nil && sibNode.left == nil {
-			// 3.1.1
-			if isleft {
-				sibNode.color = delNode.parent.color
-				delNode.parent.color = BLACK
-				sibNode.right.color = BLACK
-				rbtree.rotateLeft(delNode.parent)
-				delNode.parent.left = delNode.left
-			} else {
+			if sibNode.right.isBlack() { // 右左，转化为右右
+				sibNode.left.color = BLACK
 				sibNode.color = RED
-				sibNode.right.color = BLACK
-				rbtree.rotateLeft(sibNode)
-				rbtree.deleteCheck(delNode)
+				rbtree.rotateRight(sibNode)
+				sibNode = parent.right
 			}
-		} else if sibNode.right == nil && sibNode.left != nil {
-			// 3.1.2
-			if isleft {
+			// 右右
+			sibNode.color = parent.color
+			parent.color = BLACK
+			sibNode.right.color = BLACK
+			rbtree.rotateLeft(parent)
+		} else {
+			sibNode := parent.left
+			if !sibNode.isBlack() {
+				sibNode.color = BLACK
+				parent.color = RED
+				rbtree.rotateRight(parent)
+				sibNode = parent.left
+			}
+			if sibNode.left.isBlack() && sibNode.right.isBlack() {
 				sibNode.color = RED
-				sibNode.left.color = BLACK
-				rbtree.rotateRight(sibNode)
-				rbtree.deleteCheck(delNode)
-			} else {
-				sibNode.color = delNode.parent.color
-				delNode.parent.color = BLACK
-				sibNode.left.color = BLACK
-				rbtree.rotateRight(delNode.parent)
-				delNode.parent.right = delNode.right
+				node, parent = parent, parent.parent
+				continue
 			}
-		} else if sibNode.right != nil && sibNode.left != nil {
-			// 3.1.3
-			sibNode.color = delNode.parent.color
-			delNode.parent.color = BLACK
-			if isleft {
+			if sibNode.left.isBlack() { // 左右，转化为左左
 				sibNode.right.color = BLACK
-				rbtree.rotateLeft(delNode.parent)
-				delNode.parent.left = delNode.left
-			} else {
-				sibNode.left.color = BLACK
-				rbtree.rotateRight(delNode.parent)
-				delNode.parent.right = delNode.right
+				sibNode.color = RED
+				rbtree.rotateLeft(sibNode)
+				sibNode = parent.left
 			}
+			// 左左
+			sibNode.color = parent.color
+			parent.color = BLACK
+			sibNode.left.color = BLACK
+			rbtree.rotateRight(parent)
 		}
-		delNode.parent = nil
-		delNode = nil
+		node = rbtree.root
+	}
+	if node != nil {
+		node.color = BLACK
 	}
 }
 
 // log输出树
-func printTreeInLog(n *RBNode, front string) {
+func printTreeInLog[K, V any](n *RBNode[K, V], front string) {
 	if n != nil {
 		var colorstr string
 		if n.color == RED {
@@ -248,7 +285,7 @@ func printTreeInLog(n *RBNode, front string) {
 		} else {
 			colorstr = "黑"
 		}
-		log.Printf(front+"%d,%s\n", n.value, colorstr)
+		log.Printf(front+"%v,%s\n", n.key, colorstr)
 		// if n.parent != nil {
 		// 	fmt.Printf("parent:%d--", n.parent.value)
 		// }
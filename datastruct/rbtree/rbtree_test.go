@@ -1,17 +1,21 @@
 package rbtree
 
 import (
+	"DataStruct/datastruct"
 	"log"
+	"math/rand"
+	"slices"
+	"sort"
 	"testing"
 )
 
 func Test_rbtree(test *testing.T) {
-	rbtree := NewRBTree()
+	rbtree := NewRBTree[int64, int64](datastruct.OrderedComparator[int64]())
 
 	int64arr := [...]int64{1, 2, 3, 4, 5, 6, 7, 8}
 
 	for _, num := range int64arr {
-		rbtree.Insert(num)
+		rbtree.Put(num, num)
 	}
 
 	// r := rand.New(rand.NewSource(time.Now().UnixNano()))
@@ -22,8 +26,93 @@ func Test_rbtree(test *testing.T) {
 	printTreeInLog(rbtree.root, "(root)")
 
 	log.Print("删除节点@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@")
-	rbtree.Delete(rbtree.root.value)
+	rbtree.Delete(rbtree.root.key)
 	rbtree.Delete(int64(2))
 	rbtree.Delete(int64(3))
 	printTreeInLog(rbtree.root, "(root)")
 }
+
+// 随机插入删除后与 map 对比
+func TestRBTreeRandom(t *testing.T) {
+	rbtree := NewRBTree[int, int](datastruct.OrderedComparator[int]())
+	ref := make(map[int]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		key := r.Intn(1000)
+		if r.Intn(3) == 0 {
+			want, exist := ref[key]
+			if v, ok := rbtree.Delete(key); ok != exist || v != want {
+				t.Fatalf("Delete(%d)=%d,%v, 期望 %d,%v", key, v, ok, want, exist)
+			}
+			delete(ref, key)
+		} else {
+			want, exist := ref[key]
+			if old, ok := rbtree.Put(key, i); ok != exist || old != want {
+				t.Fatalf("Put(%d)=%d,%v, 期望 %d,%v", key, old, ok, want, exist)
+			}
+			ref[key] = i
+		}
+		if i%500 == 0 {
+			if err := rbtree.Verify(); err != nil {
+				t.Fatalf("第 %d 次操作后 Verify: %v", i, err)
+			}
+		}
+	}
+	if err := rbtree.Verify(); err != nil {
+		t.Fatal(err)
+	}
+	if rbtree.Len() != len(ref) {
+		t.Fatalf("Len=%d, 期望 %d", rbtree.Len(), len(ref))
+	}
+	keys := make([]int, 0, len(ref))
+	for k, v := range ref {
+		keys = append(keys, k)
+		if got, ok := rbtree.Get(k); !ok || got != v {
+			t.Fatalf("Get(%d)=%d,%v, 期望 %d", k, got, ok, v)
+		}
+	}
+	sort.Ints(keys)
+
+	for key := -1; key <= 1000; key++ {
+		_, exist := ref[key]
+		if _, ok := rbtree.Get(key); ok != exist {
+			t.Fatalf("Get(%d) 结果错误", key)
+		}
+		i := sort.SearchInts(keys, key+1)
+		if k, v, ok := rbtree.Floor(key); ok != (i > 0) || (ok && (k != keys[i-1] || v != ref[k])) {
+			t.Fatalf("Floor(%d)=%d,%v 错误", key, k, ok)
+		}
+		i = sort.SearchInts(keys, key)
+		if k, v, ok := rbtree.Ceiling(key); ok != (i < len(keys)) || (ok && (k != keys[i] || v != ref[k])) {
+			t.Fatalf("Ceiling(%d)=%d,%v 错误", key, k, ok)
+		}
+	}
+	if k, _, _ := rbtree.Min(); k != keys[0] {
+		t.Fatalf("Min=%d, 期望 %d", k, keys[0])
+	}
+	if k, _, _ := rbtree.Max(); k != keys[len(keys)-1] {
+		t.Fatalf("Max=%d, 期望 %d", k, keys[len(keys)-1])
+	}
+
+	var asc, desc []int
+	rbtree.Ascend(func(k, v int) bool {
+		asc = append(asc, k)
+		return true
+	})
+	rbtree.Descend(func(k, v int) bool {
+		desc = append(desc, k)
+		return true
+	})
+	slices.Reverse(desc)
+	if !slices.Equal(asc, keys) || !slices.Equal(desc, keys) {
+		t.Fatal("遍历结果与期望不一致")
+	}
+
+	// 全部删除
+	for _, k := range keys {
+		rbtree.Delete(k)
+	}
+	if rbtree.Len() != 0 || rbtree.root != nil {
+		t.Fatalf("全部删除后 Len=%d", rbtree.Len())
+	}
+}
@@ -0,0 +1,67 @@
+package rbtree
+
+import "DataStruct/datastruct"
+
+// Verify 校验红黑树的不变式：二叉搜索树有序、父指针正确、根结点为黑色、红色结点没有红色孩子、
+// 每条路径上黑色结点数相同、子树的 size 正确、节点数量等于 Len，返回发现的第一处错误（*datastruct.InvariantError）
+func (rbtree *RBTree[K, V]) Verify() error {
+	if rbtree.root == nil {
+		return nil
+	}
+	if rbtree.root.parent != nil {
+		return datastruct.NewInvariantError("root", "root has parent")
+	}
+	if rbtree.root.color != BLACK {
+		return datastruct.NewInvariantError("root", "root is red")
+	}
+	n, _, err := rbtree.verify(rbtree.root, "root", nil, nil)
+	if err == nil && n != rbtree.length {
+		err = datastruct.NewInvariantError("root", "%d nodes, length %d", n, rbtree.length)
+	}
+	return err
+}
+
+// verify 校验子树，lo、hi 为子树中 key 的开区间边界（nil 表示无边界），返回子树的节点数量与黑高
+func (rbtree *RBTree[K, V]) verify(node *RBNode[K, V], path string, lo, hi *K) (int, int, error) {
+	if node == nil {
+		return 0, 1, nil
+	}
+	if node.color != RED && node.color != BLACK {
+		return 0, 0, datastruct.NewInvariantError(path, "invalid color %d", node.color)
+	}
+	if lo != nil && rbtree.cmp(node.key, *lo) <= 0 {
+		return 0, 0, datastruct.NewInvariantError(path, "key %v <= lower bound %v", node.key, *lo)
+	}
+	if hi != nil && rbtree.cmp(node.key, *hi) >= 0 {
+		return 0, 0, datastruct.NewInvariantError(path, "key %v >= upper bound %v", node.key, *hi)
+	}
+	for _, child := range []*RBNode[K, V]{node.left, node.right} {
+		if child == nil {
+			continue
+		}
+		if child.parent != node {
+			return 0, 0, datastruct.NewInvariantError(path, "child %v has wrong parent", child.key)
+		}
+		if node.color == RED && child.color == RED {
+			return 0, 0, datastruct.NewInvariantError(path, "red node %v has red child %v", node.key, child.key)
+		}
+	}
+	ln, lbh, err := rbtree.verify(node.left, path+".L", lo, &node.key)
+	if err != nil {
+		return 0, 0, err
+	}
+	rn, rbh, err := rbtree.verify(node.right, path+".R", &node.key, hi)
+	if err != nil {
+		return 0, 0, err
+	}
+	if lbh != rbh {
+		return 0, 0, datastruct.NewInvariantError(path, "black height %d (left) != %d (right)", lbh, rbh)
+	}
+	if node.size != ln+rn+1 {
+		return 0, 0, datastruct.NewInvariantError(path, "size %d, expected %d", node.size, ln+rn+1)
+	}
+	if node.color == BLACK {
+		lbh++
+	}
+	return node.size, lbh, nil
+}
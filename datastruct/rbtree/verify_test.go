@@ -0,0 +1,56 @@
+package rbtree
+
+import (
+	"DataStruct/datastruct"
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestRBTreeVerify(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	build := func() *RBTree[int64, int] {
+		rbtree := NewRBTree[int64, int](datastruct.OrderedComparator[int64]())
+		for i := 0; i < 200; i++ {
+			rbtree.Put(r.Int63n(1000), i)
+		}
+		for i := 0; i < 50; i++ {
+			rbtree.Delete(r.Int63n(1000))
+		}
+		return rbtree
+	}
+	if err := NewRBTree[int64, int](datastruct.OrderedComparator[int64]()).Verify(); err != nil {
+		t.Fatalf("空树校验失败: %v", err)
+	}
+	if err := build().Verify(); err != nil {
+		t.Fatalf("随机插入删除后校验失败: %v", err)
+	}
+
+	cases := []struct {
+		name    string
+		corrupt func(rbtree *RBTree[int64, int])
+		path    string
+	}{
+		{"根结点为红色", func(rbtree *RBTree[int64, int]) { rbtree.root.color = RED }, "root"},
+		{"无序", func(rbtree *RBTree[int64, int]) { rbtree.root.left.key = rbtree.root.key + 1 }, "root.L"},
+		{"父指针错误", func(rbtree *RBTree[int64, int]) { rbtree.root.right.parent = nil }, "root"},
+		{"黑高不一致", func(rbtree *RBTree[int64, int]) {
+			node := rbtree.root
+			for node.left != nil {
+				node = node.left
+			}
+			node.left = &RBNode[int64, int]{key: node.key - 1, color: BLACK, parent: node}
+		}, ""},
+	}
+	for _, c := range cases {
+		err := func() error {
+			rbtree := build()
+			c.corrupt(rbtree)
+			return rbtree.Verify()
+		}()
+		var ie *datastruct.InvariantError
+		if !errors.Is(err, datastruct.ErrInvariant) || !errors.As(err, &ie) || (c.path != "" && ie.Path() != c.path) {
+			t.Errorf("%s: 期望 %s 处的错误, 实际 %v", c.name, c.path, err)
+		}
+	}
+}
@@ -0,0 +1,495 @@
+package datastruct
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"unsafe"
+)
+
+// 仿 redis zset
+
+// Node SkipLinks 中的一个元素，RangeByScore 等查询返回的是元素的副本，修改它不会影响 SkipLinks
+type Node[T any] struct {
+	Key   string
+	Score float64 // 分数
+	Val   T
+	// Next 原先记录该节点每一层的后继
+	//
+	// Deprecated: SkipLinks 基于 SkipList 实现后不再使用 Node 链接节点，查询返回的 Node 中 Next 始终为 nil
+	Next []*Node[T]
+}
+
+// NewNode 创建节点
+//
+// Deprecated: SkipLinks 不再使用 Node 作为内部节点，需要构造元素时直接使用 &Node[T]{Key: key, Score: score, Val: val}
+func NewNode[T any](key string, score float64, val T, maxLevel uint16) *Node[T] {
+	return &Node[T]{
+		Key:   key,
+		Score: score,
+		Val:   val,
+		Next:  make([]*Node[T], maxLevel),
+	}
+}
+
+// 自定义 error
+
+// 分数小于最小
+type ScoreOutOfRangeError struct {
+	key   string
+	score float64
+	min   float64
+}
+
+var _ error = (*ScoreOutOfRangeError)(nil)
+
+func NewScoreOutOfRangeError(key string, score, min float64) *ScoreOutOfRangeError {
+	return &ScoreOutOfRangeError{
+		key:   key,
+		score: score,
+		min:   min,
+	}
+}
+
+func (e *ScoreOutOfRangeError) Error() string {
+	return fmt.Sprintf("score out of range, min(include)=%v, score=%v, key=%s", e.min, e.score, e.key)
+}
+
+// 键值错误
+type InvalidKeyError struct {
+	key    string
+	reason string
+}
+
+var _ error = (*InvalidKeyError)(nil)
+
+func NewInvalidKeyError(key, reason string) *InvalidKeyError {
+	return &InvalidKeyError{
+		key:    key,
+		reason: reason,
+	}
+}
+
+func (e *InvalidKeyError) Error() string {
+	return fmt.Sprintf("invalid key: %s, reason: %s", e.key, e.reason)
+}
+
+// 字典序区间格式错误
+type InvalidLexRangeError struct {
+	item string
+}
+
+var _ error = (*InvalidLexRangeError)(nil)
+
+func NewInvalidLexRangeError(item string) *InvalidLexRangeError {
+	return &InvalidLexRangeError{
+		item: item,
+	}
+}
+
+func (e *InvalidLexRangeError) Error() string {
+	return fmt.Sprintf("invalid lex range item: %q, must start with '(' or '[', or be '-' or '+'", e.item)
+}
+
+// ------------------------------- skip list ---------------------------------
+
+const (
+	defaultSkipLinkedP = 0.5
+	defaultMaxLevel    = 64
+)
+
+// SkipLinks 按分数排序的跳跃表，分数相同时按 key 排序，key 唯一
+//
+// 基于 SkipList[ScoreKey, T] 实现，另外记录 key 到分数的映射
+type SkipLinks[T any] struct {
+	list     *SkipList[ScoreKey, T] // 按 (score, key) 排序的跳跃表
+	scoreMap map[string]float64     // key score 映射表
+	minScore float64                // 最小分数值
+	codec    Codec[T]               // value 的编码，用于序列化
+}
+
+// NewSkipLinks 创建跳跃表，value 使用 JSONCodec 序列化
+//
+//	@opts 配置项，见 WithSeed、WithRandSource、WithMaxLevel、WithProbability、WithMinScore
+func NewSkipLinks[T any](opts ...SkipListOption) *SkipLinks[T] {
+	return NewSkipLinksWithCodec[T](JSONCodec[T]{}, opts...)
+}
+
+// NewSkipLinksWithCodec 创建跳跃表，使用 codec 序列化 value（MarshalBinary 等方法）
+//
+//	@codec value 的编码，为 nil 时使用 JSONCodec
+//	@opts 配置项，同 NewSkipLinks
+func NewSkipLinksWithCodec[T any](codec Codec[T], opts ...SkipListOption) *SkipLinks[T] {
+	if codec == nil {
+		codec = JSONCodec[T]{}
+	}
+	o := newSkipListOptions(opts)
+	return &SkipLinks[T]{
+		list:     newSkipList[ScoreKey, T](CompareScoreKey, o),
+		scoreMap: make(map[string]float64),
+		minScore: o.minScore,
+		codec:    codec,
+	}
+}
+
+// NewSkipLinked 创建跳跃表，等价于 NewSkipLinks(WithMaxLevel(maxLevel), WithMinScore(minScore), WithProbability(p[0]))
+//
+//	@maxLevel 设置最大层数
+//	@minScore 设置最小分数，Add 拒绝更小的分数，传入 math.Inf(-1) 时不限制
+//	@p 自定义上升概率（0<p<1 默认为0.5）
+func NewSkipLinked[T any](maxLevel uint16, minScore float64, p ...float64) *SkipLinks[T] {
+	opts := []SkipListOption{WithMaxLevel(int(maxLevel)), WithMinScore(minScore)}
+	if len(p) != 0 {
+		opts = append(opts, WithProbability(p[0]))
+	}
+	return NewSkipLinks[T](opts...)
+}
+
+// Search 查找 key，返回其 value 与分数
+//
+// SkipLinks 的元素保存在 SkipList 中，不再有可以原地修改的 *Node，修改 value 或分数需要调用 Add
+func (l *SkipLinks[T]) Search(key string) (ok bool, val T, score float64) {
+	score, ok = l.scoreMap[key]
+	if !ok {
+		return false, val, 0
+	}
+	if val, ok = l.list.Get(ScoreKey{Score: score, Key: key}); !ok {
+		return false, val, 0
+	}
+	return true, val, score
+}
+
+// Add 添加 key，key 已存在时更新分数与 value
+func (l *SkipLinks[T]) Add(key string, score float64, val T) error {
+	_, _, err := l.put(key, score, val)
+	return err
+}
+
+// put 添加 key，key 已存在时先删除原来的节点，返回原来的 value
+func (l *SkipLinks[T]) put(key string, score float64, val T) (old T, replaced bool, err error) {
+	if score < l.minScore || math.IsNaN(score) {
+		return old, false, NewScoreOutOfRangeError(key, score, l.minScore)
+	}
+	if key == "" {
+		return old, false, NewInvalidKeyError(key, "key empty")
+	}
+	replaced, old = l.Erase(key)
+	l.list.Put(ScoreKey{Score: score, Key: key}, val)
+	l.scoreMap[key] = score
+	return old, replaced, nil
+}
+
+// Erase 删除 key，返回被删除的 value
+func (l *SkipLinks[T]) Erase(key string) (bool, T) {
+	score, exist := l.scoreMap[key]
+	if !exist {
+		var zero T
+		return false, zero
+	}
+	delete(l.scoreMap, key)
+	val, exist := l.list.Delete(ScoreKey{Score: score, Key: key})
+	return exist, val
+}
+
+// Len 元素数量
+func (l *SkipLinks[T]) Len() int {
+	return l.list.Len()
+}
+
+// Rank key 从 0 开始的升序排名（按分数从低到高），key 不存在时返回 ok=false
+func (l *SkipLinks[T]) Rank(key string) (rank int, ok bool) {
+	score, exist := l.scoreMap[key]
+	if !exist {
+		return -1, false
+	}
+	return l.list.Rank(ScoreKey{Score: score, Key: key})
+}
+
+// RevRank key 从 0 开始的降序排名（按分数从高到低），key 不存在时返回 ok=false
+func (l *SkipLinks[T]) RevRank(key string) (rank int, ok bool) {
+	if rank, ok = l.Rank(key); ok {
+		rank = l.list.Len() - 1 - rank
+	}
+	return
+}
+
+// RangeByRank 返回升序排名在 [start, stop] 区间内的元素，负数表示倒数第几个（-1 为最后一个）
+func (l *SkipLinks[T]) RangeByRank(start, stop int) []*Node[T] {
+	length := l.list.Len()
+	if start < 0 {
+		start = max(start+length, 0)
+	}
+	if stop < 0 {
+		stop += length
+	}
+	stop = min(stop, length-1)
+	if start > stop {
+		return nil
+	}
+	nodes := make([]*Node[T], 0, stop-start+1)
+	for node := l.list.nodeByRank(start); len(nodes) < cap(nodes); node = node.levels[0].next {
+		nodes = append(nodes, newNodeCopy(node))
+	}
+	return nodes
+}
+
+// RangeByScore 按分数升序返回分数在 low、high 之间的元素
+//
+//	@lowExclusive、highExclusive 为 true 时不包含分数等于 low、high 的元素
+//	@offset 跳过前 offset 个符合条件的元素，与 Redis 的 ZRANGEBYSCORE ... LIMIT 相同，负数时返回空
+//	@limit 最多返回的元素数量，负数表示不限制
+func (l *SkipLinks[T]) RangeByScore(low, high float64, lowExclusive, highExclusive bool, offset, limit int) []*Node[T] {
+	if offset < 0 {
+		return nil
+	}
+	var nodes []*Node[T]
+	node, _ := l.list.seekBy(scoreBefore(low, lowExclusive), nil, nil)
+	inRange := scoreBefore(high, !highExclusive)
+	for ; node != nil && limit != 0 && inRange(node.key); node = node.levels[0].next {
+		if offset > 0 {
+			offset--
+			continue
+		}
+		nodes = append(nodes, newNodeCopy(node))
+		limit--
+	}
+	return nodes
+}
+
+// Count 分数在 [low, high] 区间内的元素数量
+func (l *SkipLinks[T]) Count(low, high float64) int {
+	_, lo := l.list.seekBy(scoreBefore(low, false), nil, nil)
+	_, hi := l.list.seekBy(scoreBefore(high, true), nil, nil)
+	return max(hi-lo, 0)
+}
+
+// IncrBy 将 key 的分数增加 delta，返回新的分数，key 不存在时以分数 0、零值 value 添加
+func (l *SkipLinks[T]) IncrBy(key string, delta float64) (float64, error) {
+	var val T
+	score, exist := l.scoreMap[key]
+	if exist {
+		val, _ = l.list.Get(ScoreKey{Score: score, Key: key})
+	}
+	score += delta
+	if _, _, err := l.put(key, score, val); err != nil {
+		return 0, err
+	}
+	return score, nil
+}
+
+// PopMin 删除并返回分数最低的 count 个元素，按分数升序排列
+func (l *SkipLinks[T]) PopMin(count int) []*Node[T] {
+	if count <= 0 {
+		return nil
+	}
+	nodes := l.RangeByRank(0, count-1)
+	for _, node := range nodes {
+		l.Erase(node.Key)
+	}
+	return nodes
+}
+
+// PopMax 删除并返回分数最高的 count 个元素，按分数降序排列
+func (l *SkipLinks[T]) PopMax(count int) []*Node[T] {
+	if count <= 0 {
+		return nil
+	}
+	nodes := l.RangeByRank(-count, -1)
+	slices.Reverse(nodes)
+	for _, node := range nodes {
+		l.Erase(node.Key)
+	}
+	return nodes
+}
+
+// RangeByLex 按 key 的字典序返回 key 在 low、high 之间的元素，与 redis ZRANGEBYLEX 一致，所有元素分数相同时结果才有意义
+//
+// low、high 以 "[" 开头表示包含，以 "(" 开头表示不包含，"-"、"+" 分别表示负无穷与正无穷
+func (l *SkipLinks[T]) RangeByLex(low, high string) ([]*Node[T], error) {
+	var nodes []*Node[T]
+	err := l.walkLex(low, high, func(node *skipNode[ScoreKey, T]) {
+		nodes = append(nodes, newNodeCopy(node))
+	})
+	return nodes, err
+}
+
+// RemoveRangeByLex 删除 key 在 low、high 之间的元素，返回删除的数量，区间的格式与 RangeByLex 相同
+func (l *SkipLinks[T]) RemoveRangeByLex(low, high string) (int, error) {
+	var keys []string
+	err := l.walkLex(low, high, func(node *skipNode[ScoreKey, T]) {
+		keys = append(keys, node.key.Key)
+	})
+	for _, key := range keys {
+		l.Erase(key)
+	}
+	return len(keys), err
+}
+
+// walkLex 按顺序对 key 在 low、high 之间的节点调用 fn
+func (l *SkipLinks[T]) walkLex(low, high string, fn func(node *skipNode[ScoreKey, T])) error {
+	lo, err := parseLexBound(low)
+	if err != nil {
+		return err
+	}
+	hi, err := parseLexBound(high)
+	if err != nil {
+		return err
+	}
+	node, _ := l.list.seekBy(func(key ScoreKey) bool { return !lo.gteMin(key.Key) }, nil, nil)
+	for ; node != nil && hi.lteMax(node.key.Key); node = node.levels[0].next {
+		fn(node)
+	}
+	return nil
+}
+
+// lexBound 字典序区间的一端
+type lexBound struct {
+	value     string
+	inf       int  // -1 为 "-"，1 为 "+"，0 为普通的值
+	exclusive bool // 是否不包含 value
+}
+
+// parseLexBound 解析 "[value"、"(value"、"-"、"+"
+func parseLexBound(item string) (lexBound, error) {
+	switch {
+	case item == "-":
+		return lexBound{inf: -1}, nil
+	case item == "+":
+		return lexBound{inf: 1}, nil
+	case strings.HasPrefix(item, "["):
+		return lexBound{value: item[1:]}, nil
+	case strings.HasPrefix(item, "("):
+		return lexBound{value: item[1:], exclusive: true}, nil
+	}
+	return lexBound{}, NewInvalidLexRangeError(item)
+}
+
+// gteMin s 是否满足作为下界的 b
+func (b lexBound) gteMin(s string) bool {
+	if b.inf != 0 {
+		return b.inf < 0
+	}
+	if b.exclusive {
+		return s > b.value
+	}
+	return s >= b.value
+}
+
+// lteMax s 是否满足作为上界的 b
+func (b lexBound) lteMax(s string) bool {
+	if b.inf != 0 {
+		return b.inf > 0
+	}
+	if b.exclusive {
+		return s < b.value
+	}
+	return s <= b.value
+}
+
+// scoreBefore 返回 seekBy 使用的判断函数，inclusive 为 true 时跳过分数 <= score 的元素，否则跳过分数 < score 的元素
+func scoreBefore(score float64, inclusive bool) func(key ScoreKey) bool {
+	if inclusive {
+		return func(key ScoreKey) bool { return key.Score <= score }
+	}
+	return func(key ScoreKey) bool { return key.Score < score }
+}
+
+// newNodeCopy 根据跳跃表节点生成 Node 副本（不含 Next）
+func newNodeCopy[T any](node *skipNode[ScoreKey, T]) *Node[T] {
+	return &Node[T]{Key: node.key.Key, Score: node.key.Score, Val: node.value}
+}
+
+// Stats 统计跳跃表的层数分布、平均查找路径长度与内存占用，内存占用包含 key 到分数的映射表（按每项的大小估算）
+func (l *SkipLinks[T]) Stats() SkipListStats {
+	stats := l.list.Stats()
+	var entry struct {
+		key   string
+		score float64
+	}
+	stats.MemoryBytes += len(l.scoreMap) * int(unsafe.Sizeof(entry))
+	return stats
+}
+
+// Println 打印跳跃表
+func (l *SkipLinks[T]) Println() {
+	for i := l.list.level - 1; i >= 0; i-- {
+		var (
+			content string                 = fmt.Sprintf("level%d", i)
+			node    *skipNode[ScoreKey, T] = l.list.head.levels[i].next
+		)
+		for node != nil {
+			content += fmt.Sprintf(" %s(%v)", node.key.Key, node.key.Score)
+			node = node.levels[i].next
+		}
+		fmt.Println(content)
+	}
+}
+
+// ScoreKey 跳跃表元素的排序键，先按 Score 再按 Key 排序
+type ScoreKey struct {
+	Score float64
+	Key   string
+}
+
+// CompareScoreKey ScoreKey 的比较函数
+func CompareScoreKey(a, b ScoreKey) int {
+	return compareScoreKey(a, b)
+}
+
+var compareScoreKey = ChainComparators(
+	ComparatorBy(func(k ScoreKey) float64 { return k.Score }),
+	ComparatorBy(func(k ScoreKey) string { return k.Key }),
+)
+
+// skipLinksMap 以 ScoreKey 为 key 的跳跃表视图
+type skipLinksMap[T any] struct {
+	l *SkipLinks[T]
+}
+
+var _ OrderedMap[ScoreKey, int] = skipLinksMap[int]{}
+
+// OrderedMap 返回以 ScoreKey 为 key 的 OrderedMap 视图，对视图的修改直接作用于跳跃表
+//
+// Key 相同、Score 不同的 ScoreKey 视为同一个元素：Put 会更新该元素的分数，Get、Delete 要求分数一致。
+// Put 的分数小于最小分数或 Key 为空时 panic
+func (l *SkipLinks[T]) OrderedMap() OrderedMap[ScoreKey, T] {
+	return skipLinksMap[T]{l: l}
+}
+
+func (m skipLinksMap[T]) Get(key ScoreKey) (T, bool) {
+	if score, ok := m.l.scoreMap[key.Key]; ok && score == key.Score {
+		return m.l.list.Get(key)
+	}
+	var zero T
+	return zero, false
+}
+
+func (m skipLinksMap[T]) Put(key ScoreKey, value T) (T, bool) {
+	old, replaced, err := m.l.put(key.Key, key.Score, value)
+	if err != nil {
+		panic(err)
+	}
+	return old, replaced
+}
+
+func (m skipLinksMap[T]) Delete(key ScoreKey) (T, bool) {
+	if score, ok := m.l.scoreMap[key.Key]; ok && score == key.Score {
+		ok, val := m.l.Erase(key.Key)
+		return val, ok
+	}
+	var zero T
+	return zero, false
+}
+
+func (m skipLinksMap[T]) Len() int {
+	return m.l.list.Len()
+}
+
+func (m skipLinksMap[T]) Ascend(fn func(key ScoreKey, value T) bool) {
+	m.l.list.Ascend(fn)
+}
+
+func (m skipLinksMap[T]) Range(lo, hi ScoreKey, fn func(key ScoreKey, value T) bool) {
+	m.l.list.Range(lo, hi, fn)
+}
@@ -0,0 +1,318 @@
+package datastruct
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestSkipList(t *testing.T) {
+	sl := NewSkipLinked[string](20, 1)
+	for i := 1; i < 10; i++ {
+		kv := fmt.Sprintf("%d", i)
+		sl.Add(kv, float64(i+10), kv)
+	}
+	sl.Println()
+	if ok, val, _ := sl.Search("1"); ok {
+		fmt.Println(val)
+	}
+
+	sl.Erase("1")
+
+	fmt.Println("-----------------------------")
+
+	sl.Println()
+	if ok, val, score := sl.Search("1"); ok {
+		fmt.Println(val, score)
+	} else {
+		fmt.Println("not found")
+	}
+}
+
+func TestSkipLinksReplace(t *testing.T) {
+	sl := NewSkipLinked[int](16, math.Inf(-1))
+	for i, score := range []float64{-1e9, 3, math.Inf(-1), -2} {
+		if err := sl.Add(fmt.Sprintf("k%d", i), score, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 重复添加同一个 key 只更新分数与 value
+	if err := sl.Add("k1", -5, 10); err != nil {
+		t.Fatal(err)
+	}
+	if sl.list.Len() != 4 {
+		t.Fatalf("Len=%d, 期望 4", sl.list.Len())
+	}
+	var got []string
+	sl.OrderedMap().Ascend(func(k ScoreKey, _ int) bool {
+		got = append(got, k.Key)
+		return true
+	})
+	if want := []string{"k2", "k0", "k1", "k3"}; !slices.Equal(got, want) {
+		t.Fatalf("排序结果 %v, 期望 %v", got, want)
+	}
+	if ok, val, score := sl.Search("k1"); !ok || score != -5 || val != 10 {
+		t.Fatalf("Search(k1)=%v,%v,%v", ok, val, score)
+	}
+	if ok, val, score := sl.Search("missing"); ok || val != 0 || score != 0 {
+		t.Fatalf("Search(missing)=%v,%v,%v", ok, val, score)
+	}
+
+	if _, ok := sl.OrderedMap().Delete(ScoreKey{Score: -5, Key: "k1"}); !ok {
+		t.Fatal("Delete(k1) 失败")
+	}
+	if ok, _, _ := sl.Search("k1"); ok {
+		t.Fatal("删除后仍能找到 k1")
+	}
+
+	limited := NewSkipLinked[int](16, 0)
+	if err := limited.Add("a", -1, 0); err == nil {
+		t.Fatal("分数小于最小分数时应返回错误")
+	}
+}
+
+func TestSkipLinksZSet(t *testing.T) {
+	sl := NewSkipLinked[int](16, math.Inf(-1))
+	r := rand.New(rand.NewSource(1))
+	ref := make(map[string]float64)
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprintf("k%03d", r.Intn(300))
+		switch r.Intn(4) {
+		case 0:
+			sl.Erase(key)
+			delete(ref, key)
+		case 1:
+			delta := float64(r.Intn(7) - 3)
+			score, err := sl.IncrBy(key, delta)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if score != ref[key]+delta {
+				t.Fatalf("IncrBy(%s, %v)=%v, 期望 %v", key, delta, score, ref[key]+delta)
+			}
+			ref[key] = score
+		default:
+			score := float64(r.Intn(40) - 20)
+			if err := sl.Add(key, score, i); err != nil {
+				t.Fatal(err)
+			}
+			ref[key] = score
+		}
+	}
+	checkSkipListSpans(t, sl.list)
+
+	// 参考实现：按 (score, key) 排序
+	keys := make([]string, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return CompareScoreKey(ScoreKey{ref[a], a}, ScoreKey{ref[b], b})
+	})
+	nodeKeys := func(nodes []*Node[int]) []string {
+		var ks []string
+		for _, n := range nodes {
+			if n.Score != ref[n.Key] {
+				t.Fatalf("%s 的分数为 %v, 期望 %v", n.Key, n.Score, ref[n.Key])
+			}
+			ks = append(ks, n.Key)
+		}
+		return ks
+	}
+
+	if sl.Len() != len(keys) {
+		t.Fatalf("Len=%d, 期望 %d", sl.Len(), len(keys))
+	}
+	for i, k := range keys {
+		if rank, ok := sl.Rank(k); !ok || rank != i {
+			t.Fatalf("Rank(%s)=%d,%v, 期望 %d", k, rank, ok, i)
+		}
+		if rank, ok := sl.RevRank(k); !ok || rank != len(keys)-1-i {
+			t.Fatalf("RevRank(%s)=%d,%v, 期望 %d", k, rank, ok, len(keys)-1-i)
+		}
+	}
+	if _, ok := sl.Rank("none"); ok {
+		t.Fatal("不存在的 key 不应有排名")
+	}
+
+	n := len(keys)
+	rankCases := []struct{ start, stop, lo, hi int }{
+		{0, -1, 0, n},
+		{0, 9, 0, 10},
+		{-10, -1, n - 10, n},
+		{5, 2, 0, 0},
+		{-n - 5, 3, 0, 4},
+		{n - 3, n + 10, n - 3, n},
+		{n, n + 1, 0, 0},
+	}
+	for _, c := range rankCases {
+		if got := nodeKeys(sl.RangeByRank(c.start, c.stop)); !slices.Equal(got, keys[c.lo:c.hi]) {
+			t.Fatalf("RangeByRank(%d, %d)=%v, 期望 %v", c.start, c.stop, got, keys[c.lo:c.hi])
+		}
+	}
+
+	for _, c := range []struct {
+		low, high                   float64
+		lowExclusive, highExclusive bool
+		offset, limit               int
+	}{
+		{-5, 5, false, false, 0, -1},
+		{-5, 5, true, false, 0, -1},
+		{-5, 5, false, true, 3, -1},
+		{-5, 5, true, true, 2, 4},
+		{math.Inf(-1), math.Inf(1), false, false, 0, 0},
+		{10, -10, false, false, 0, -1},
+		{math.Inf(-1), 0, false, false, 0, -1},
+		{-5, 5, false, false, -1, -1}, // 负数 offset 与 Redis 一致返回空
+	} {
+		var want []string
+		for _, k := range keys {
+			s := ref[k]
+			if s < c.low || s > c.high || c.lowExclusive && s == c.low || c.highExclusive && s == c.high {
+				continue
+			}
+			want = append(want, k)
+		}
+		if c.offset >= 0 && c.offset < len(want) {
+			want = want[c.offset:]
+		} else {
+			want = nil
+		}
+		if c.limit >= 0 && c.limit < len(want) {
+			want = want[:c.limit]
+		}
+		got := nodeKeys(sl.RangeByScore(c.low, c.high, c.lowExclusive, c.highExclusive, c.offset, c.limit))
+		if !slices.Equal(got, want) {
+			t.Fatalf("RangeByScore%+v=%v, 期望 %v", c, got, want)
+		}
+		if !c.lowExclusive && !c.highExclusive && c.offset == 0 && c.limit < 0 {
+			if count := sl.Count(c.low, c.high); count != len(want) {
+				t.Fatalf("Count(%v, %v)=%d, 期望 %d", c.low, c.high, count, len(want))
+			}
+		}
+	}
+
+	if got := nodeKeys(sl.PopMin(3)); !slices.Equal(got, keys[:3]) {
+		t.Fatalf("PopMin(3)=%v, 期望 %v", got, keys[:3])
+	}
+	want := slices.Clone(keys[n-3:])
+	slices.Reverse(want)
+	if got := nodeKeys(sl.PopMax(3)); !slices.Equal(got, want) {
+		t.Fatalf("PopMax(3)=%v, 期望 %v", got, want)
+	}
+	if sl.Len() != n-6 {
+		t.Fatalf("Pop 后 Len=%d, 期望 %d", sl.Len(), n-6)
+	}
+	if ok, _, _ := sl.Search(keys[0]); ok {
+		t.Fatalf("%s 已被 PopMin 删除", keys[0])
+	}
+	if len(sl.PopMin(n)) != n-6 || sl.Len() != 0 || sl.PopMax(1) != nil {
+		t.Fatal("全部弹出后应为空")
+	}
+	checkSkipListSpans(t, sl.list)
+
+	if _, err := sl.IncrBy("x", math.NaN()); err == nil {
+		t.Fatal("分数为 NaN 时应返回错误")
+	}
+}
+
+func TestSkipLinksLex(t *testing.T) {
+	newZSet := func(keys ...string) *SkipLinks[int] {
+		sl := NewSkipLinked[int](16, 0)
+		for i, k := range keys {
+			if err := sl.Add(k, 0, i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return sl
+	}
+	keysOf := func(nodes []*Node[int]) []string {
+		ks := []string{}
+		for _, n := range nodes {
+			ks = append(ks, n.Key)
+		}
+		return ks
+	}
+
+	// redis 文档中 ZRANGEBYLEX 的例子以及边界情况
+	sl := newZSet("a", "b", "c", "d", "e", "f", "g")
+	for _, c := range []struct {
+		low, high string
+		want      []string
+	}{
+		{"-", "[c", []string{"a", "b", "c"}},
+		{"-", "(c", []string{"a", "b"}},
+		{"[aaa", "(g", []string{"b", "c", "d", "e", "f"}},
+		{"-", "+", []string{"a", "b", "c", "d", "e", "f", "g"}},
+		{"[a", "[a", []string{"a"}},
+		{"(a", "(a", []string{}},
+		{"(a", "[a", []string{}},
+		{"[e", "[b", []string{}},
+		{"+", "+", []string{}},
+		{"-", "-", []string{}},
+		{"+", "-", []string{}},
+		{"[", "(b", []string{"a"}},
+		{"(f", "+", []string{"g"}},
+		{"[h", "+", []string{}},
+	} {
+		got, err := sl.RangeByLex(c.low, c.high)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(keysOf(got), c.want) {
+			t.Errorf("RangeByLex(%q, %q)=%v, 期望 %v", c.low, c.high, keysOf(got), c.want)
+		}
+	}
+	for _, c := range [][2]string{{"a", "+"}, {"-", "c"}, {"", "+"}, {"-", "++"}, {"--", "+"}} {
+		var target *InvalidLexRangeError
+		if _, err := sl.RangeByLex(c[0], c[1]); !errors.As(err, &target) {
+			t.Errorf("RangeByLex(%q, %q) 应返回 InvalidLexRangeError, 实际为 %v", c[0], c[1], err)
+		}
+	}
+
+	// redis 文档中 ZREMRANGEBYLEX 的例子
+	sl = newZSet("aaaa", "b", "c", "d", "e", "foo", "zap", "zip", "ALPHA", "alpha")
+	if n, err := sl.RemoveRangeByLex("[alpha", "[omega"); err != nil || n != 6 {
+		t.Fatalf("RemoveRangeByLex=%d,%v, 期望 6", n, err)
+	}
+	if got := keysOf(sl.RangeByRank(0, -1)); !slices.Equal(got, []string{"ALPHA", "aaaa", "zap", "zip"}) {
+		t.Fatalf("删除后剩余 %v", got)
+	}
+	if n, err := sl.RemoveRangeByLex("(aaaa", "[b"); err != nil || n != 0 || sl.Len() != 4 {
+		t.Fatalf("RemoveRangeByLex=%d,%v, 期望 0", n, err)
+	}
+	if n, err := sl.RemoveRangeByLex("[z", "x"); err == nil || n != 0 || sl.Len() != 4 {
+		t.Fatalf("区间错误时不应删除, n=%d err=%v", n, err)
+	}
+	checkSkipListSpans(t, sl.list)
+}
+
+func TestSkipLinksOptions(t *testing.T) {
+	build := func(opts ...SkipListOption) *SkipLinks[int] {
+		sl := NewSkipLinks[int](opts...)
+		for i := 0; i < 500; i++ {
+			sl.Add(fmt.Sprintf("k%d", i), float64(i%50-25), i)
+		}
+		return sl
+	}
+	a, b := build(WithSeed(3)), build(WithSeed(3))
+	if !slices.Equal(levelsOf(a.list), levelsOf(b.list)) {
+		t.Fatal("相同种子的层数不一致")
+	}
+	// 默认不限制最小分数
+	if a.Len() != 500 {
+		t.Fatalf("Len=%d, 期望 500", a.Len())
+	}
+	if err := build(WithMinScore(0)).Add("x", -1, 0); err == nil {
+		t.Fatal("分数小于最小分数时应返回错误")
+	}
+	if stats := a.Stats(); stats.Len != 500 || stats.MemoryBytes <= a.list.Stats().MemoryBytes {
+		t.Fatalf("统计错误 %+v", stats)
+	}
+	if l := NewSkipLinked[int](4, 0, 0.25).list; l.maxLevel != 4 || l.p != 0.25 {
+		t.Fatalf("NewSkipLinked 配置错误 maxLevel=%d p=%v", l.maxLevel, l.p)
+	}
+}
@@ -0,0 +1,212 @@
+package datastruct
+
+import (
+	"bufio"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+// SkipLinks 的二进制格式：
+//
+//	magic(4) | version(1) | count(uvarint) | entry * count | crc32(4)
+//	entry = keyLen(uvarint) | key | score(8，IEEE 754 大端序) | valueLen(uvarint) | value
+//
+// entry 按 (score, key) 升序排列，crc32 覆盖之前的所有字节
+
+const (
+	skipLinksMagic   = "SKPL"
+	skipLinksVersion = 1
+)
+
+// ErrInvalidSnapshot 反序列化的数据不是合法的 SkipLinks 快照
+var ErrInvalidSnapshot = errors.New("skiplinks: invalid snapshot")
+
+var (
+	_ encoding.BinaryMarshaler   = (*SkipLinks[int])(nil)
+	_ encoding.BinaryUnmarshaler = (*SkipLinks[int])(nil)
+	_ io.WriterTo                = (*SkipLinks[int])(nil)
+	_ io.ReaderFrom              = (*SkipLinks[int])(nil)
+)
+
+// MarshalBinary 序列化为二进制快照，value 使用 NewSkipLinksWithCodec 设置的编码，value 无法编码时返回 error
+func (l *SkipLinks[T]) MarshalBinary() ([]byte, error) {
+	w := &sliceWriter{}
+	if _, err := l.WriteTo(w); err != nil {
+		return nil, err
+	}
+	return w.buf, nil
+}
+
+// UnmarshalBinary 从 MarshalBinary 生成的快照恢复，替换原有的全部元素，出错时原有元素不变
+//
+// 快照中的元素已经有序，按顺序追加到跳跃表的末尾，时间复杂度 O(n)。
+// 可以在零值上调用，此时使用默认配置，已设置的 codec 保留
+func (l *SkipLinks[T]) UnmarshalBinary(data []byte) error {
+	if l.list == nil {
+		codec := l.codec
+		*l = *NewSkipLinks[T]()
+		if codec != nil {
+			l.codec = codec
+		}
+	}
+	if len(data) < len(skipLinksMagic)+1+4 || string(data[:len(skipLinksMagic)]) != skipLinksMagic {
+		return fmt.Errorf("%w: bad header", ErrInvalidSnapshot)
+	}
+	body, sum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidSnapshot)
+	}
+	if version := body[len(skipLinksMagic)]; version != skipLinksVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, version)
+	}
+	d := snapshotDecoder{buf: body[len(skipLinksMagic)+1:]}
+	count := d.uvarint()
+
+	list := l.list.emptyCopy()
+	scoreMap := make(map[string]float64, min(count, uint64(len(d.buf))))
+	b := list.newBuilder()
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		key := string(d.bytes())
+		score := math.Float64frombits(d.uint64())
+		raw := d.bytes()
+		if d.err != nil {
+			break
+		}
+		if key == "" || math.IsNaN(score) || score < l.minScore {
+			return fmt.Errorf("%w: invalid entry %q(%v)", ErrInvalidSnapshot, key, score)
+		}
+		val, err := l.codec.Decode(raw)
+		if err != nil {
+			return fmt.Errorf("%w: decode value of %q: %v", ErrInvalidSnapshot, key, err)
+		}
+		if !b.append(ScoreKey{Score: score, Key: key}, val) {
+			return fmt.Errorf("%w: entry %q(%v) out of order", ErrInvalidSnapshot, key, score)
+		}
+		if _, dup := scoreMap[key]; dup {
+			return fmt.Errorf("%w: duplicate key %q", ErrInvalidSnapshot, key)
+		}
+		scoreMap[key] = score
+	}
+	if d.err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSnapshot, d.err)
+	}
+	if len(d.buf) != 0 {
+		return fmt.Errorf("%w: %d trailing bytes", ErrInvalidSnapshot, len(d.buf))
+	}
+	l.list, l.scoreMap = list, scoreMap
+	return nil
+}
+
+// WriteTo 将二进制快照写入 w，返回写入的字节数
+//
+// value 无法编码时返回 error，此时 w 中可能已写入不完整的快照
+func (l *SkipLinks[T]) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	crc := crc32.NewIEEE()
+	bw := bufio.NewWriter(io.MultiWriter(cw, crc))
+
+	buf := append([]byte(skipLinksMagic), skipLinksVersion)
+	buf = binary.AppendUvarint(buf, uint64(l.Len()))
+	bw.Write(buf)
+	var value []byte
+	for node := l.list.head.levels[0].next; node != nil; node = node.levels[0].next {
+		var err error
+		if value, err = l.codec.Append(value[:0], node.value); err != nil {
+			return cw.n, fmt.Errorf("skiplinks: encode value of %q: %w", node.key.Key, err)
+		}
+		buf = binary.AppendUvarint(buf[:0], uint64(len(node.key.Key)))
+		buf = append(buf, node.key.Key...)
+		buf = binary.BigEndian.AppendUint64(buf, math.Float64bits(node.key.Score))
+		buf = binary.AppendUvarint(buf, uint64(len(value)))
+		bw.Write(buf)
+		bw.Write(value)
+	}
+	if err := bw.Flush(); err != nil {
+		return cw.n, err
+	}
+	_, err := cw.Write(crc.Sum(nil))
+	return cw.n, err
+}
+
+// ReadFrom 从 r 读取 WriteTo 写入的快照直到 EOF，替换原有的全部元素，返回读取的字节数
+func (l *SkipLinks[T]) ReadFrom(r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return int64(len(data)), err
+	}
+	return int64(len(data)), l.UnmarshalBinary(data)
+}
+
+// snapshotDecoder 顺序解码快照，出错后的读取都返回零值，错误记录在 err 中
+type snapshotDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *snapshotDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = errors.New("bad uvarint")
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *snapshotDecoder) uint64() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.buf) < 8 {
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.buf)
+	d.buf = d.buf[8:]
+	return v
+}
+
+// bytes 长度前缀的字节串，引用 buf 的内存
+func (d *snapshotDecoder) bytes() []byte {
+	n := d.uvarint()
+	if d.err != nil {
+		return nil
+	}
+	if n > uint64(len(d.buf)) {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+// sliceWriter 写入内存的 io.Writer
+type sliceWriter struct {
+	buf []byte
+}
+
+func (w *sliceWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+// countingWriter 记录写入的字节数
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	return n, err
+}
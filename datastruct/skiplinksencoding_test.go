@@ -0,0 +1,206 @@
+package datastruct
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// skipLinksEntries 按顺序列出全部元素
+func skipLinksEntries[T any](l *SkipLinks[T]) []string {
+	var entries []string
+	for _, node := range l.RangeByRank(0, -1) {
+		entries = append(entries, fmt.Sprintf("%s(%v)=%v", node.Key, node.Score, node.Val))
+	}
+	return entries
+}
+
+func TestSkipLinksBinary(t *testing.T) {
+	sl := NewSkipLinksWithCodec[string](StringCodec{}, WithSeed(1))
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		sl.Add(fmt.Sprintf("k%d", r.Intn(1500)), float64(r.Intn(200)-100)/4, fmt.Sprint(i))
+	}
+	sl.Add("-inf", math.Inf(-1), "")
+	sl.Add("+inf", math.Inf(1), "最大")
+
+	data, err := sl.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := NewSkipLinksWithCodec[string](StringCodec{})
+	restored.Add("old", 1, "被替换")
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(skipLinksEntries(restored), skipLinksEntries(sl)) {
+		t.Fatal("反序列化后元素不一致")
+	}
+	checkSkipListSpans(t, restored.list)
+	if ok, _, _ := restored.Search("old"); ok {
+		t.Fatal("原有元素应被替换")
+	}
+	if rank, ok := restored.Rank("+inf"); !ok || rank != sl.Len()-1 {
+		t.Fatalf("Rank(+inf)=%d,%v", rank, ok)
+	}
+	// 恢复后可以继续修改
+	restored.Add("k0", -1000, "x")
+	restored.Erase("-inf")
+	if rank, _ := restored.Rank("k0"); rank != 0 {
+		t.Fatalf("Rank(k0)=%d, 期望 0", rank)
+	}
+	checkSkipListSpans(t, restored.list)
+
+	// WriteTo、ReadFrom 与 MarshalBinary 结果一致
+	var buf bytes.Buffer
+	if n, err := sl.WriteTo(&buf); err != nil || n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Fatalf("WriteTo=%d,%v, 期望 %d", n, err, len(data))
+	}
+	// 零值也可以反序列化，保留已设置的 codec
+	var fromReader SkipLinks[string]
+	fromReader.codec = StringCodec{}
+	if n, err := fromReader.ReadFrom(&buf); err != nil || n != int64(len(data)) {
+		t.Fatalf("ReadFrom=%d,%v", n, err)
+	}
+	if !slices.Equal(skipLinksEntries(&fromReader), skipLinksEntries(sl)) {
+		t.Fatal("ReadFrom 后元素不一致")
+	}
+}
+
+func TestSkipLinksBinaryDefaultCodec(t *testing.T) {
+	type player struct {
+		Name  string
+		Level int
+	}
+	sl := NewSkipLinks[player]()
+	for i := 0; i < 10; i++ {
+		sl.Add(fmt.Sprintf("p%d", i), float64(i%3), player{Name: fmt.Sprint("玩家", i), Level: i})
+	}
+	data, err := sl.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored SkipLinks[player]
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(skipLinksEntries(&restored), skipLinksEntries(sl)) {
+		t.Fatal("反序列化后元素不一致")
+	}
+
+	empty, _ := NewSkipLinks[player]().MarshalBinary()
+	if err := restored.UnmarshalBinary(empty); err != nil || restored.Len() != 0 {
+		t.Fatalf("空快照反序列化 Len=%d, err=%v", restored.Len(), err)
+	}
+}
+
+// value 无法编码时 MarshalBinary 与 WriteTo 返回 error 而不是 panic
+func TestSkipLinksBinaryUnencodable(t *testing.T) {
+	sl := NewSkipLinks[float64]()
+	sl.Add("a", 1, 1.5)
+	sl.Add("b", 2, math.NaN()) // encoding/json 不支持 NaN
+	if data, err := sl.MarshalBinary(); err == nil || data != nil {
+		t.Fatalf("MarshalBinary 应返回 error, got %d 字节, %v", len(data), err)
+	}
+	var buf bytes.Buffer
+	if _, err := sl.WriteTo(&buf); err == nil || !strings.Contains(err.Error(), `"b"`) {
+		t.Fatalf("WriteTo 应返回包含 key 的 error, got %v", err)
+	}
+}
+
+func TestSkipLinksBinaryInvalid(t *testing.T) {
+	codec := IntegerCodec[int]{}
+	sl := NewSkipLinksWithCodec[int](codec)
+	for i := 0; i < 20; i++ {
+		sl.Add(fmt.Sprintf("k%02d", i), float64(i), i)
+	}
+	data, _ := sl.MarshalBinary()
+
+	// withChecksum 修改内容后重新计算校验和
+	withChecksum := func(body []byte) []byte {
+		return binary.BigEndian.AppendUint32(body, crc32.ChecksumIEEE(body))
+	}
+	body := slices.Clone(data[:len(data)-4])
+	entry := func(key string, score float64, value int) []byte {
+		b := binary.AppendUvarint(nil, uint64(len(key)))
+		b = append(b, key...)
+		b = binary.BigEndian.AppendUint64(b, math.Float64bits(score))
+		b = binary.AppendUvarint(b, 8)
+		return binary.BigEndian.AppendUint64(b, uint64(value))
+	}
+	header := func(count int) []byte {
+		return binary.AppendUvarint([]byte("SKPL\x01"), uint64(count))
+	}
+
+	flipped := slices.Clone(data)
+	flipped[10] ^= 1
+	badVersion := slices.Clone(body)
+	badVersion[4] = 2
+	cases := map[string][]byte{
+		"空数据":      nil,
+		"magic 错误": append([]byte("XXXX"), data[4:]...),
+		"校验和错误":    flipped,
+		"截断":       data[:len(data)/2],
+		"版本错误":     withChecksum(badVersion),
+		"数量多于元素":   withChecksum(append(header(21), body[6:]...)),
+		"多余的字节":    withChecksum(append(slices.Clone(body), 0)),
+		"顺序错误":     withChecksum(bytes.Join([][]byte{header(2), entry("b", 2, 0), entry("a", 1, 0)}, nil)),
+		"key 重复":   withChecksum(bytes.Join([][]byte{header(2), entry("a", 1, 0), entry("a", 2, 0)}, nil)),
+		"key 为空":   withChecksum(bytes.Join([][]byte{header(1), entry("", 1, 0)}, nil)),
+		"分数为 NaN":  withChecksum(bytes.Join([][]byte{header(1), entry("a", math.NaN(), 0)}, nil)),
+	}
+	for name, data := range cases {
+		restored := NewSkipLinksWithCodec[int](codec)
+		restored.Add("old", 0, 0)
+		if err := restored.UnmarshalBinary(data); !errors.Is(err, ErrInvalidSnapshot) {
+			t.Errorf("%s: err=%v, 期望 ErrInvalidSnapshot", name, err)
+		}
+		if restored.Len() != 1 {
+			t.Errorf("%s: 出错后原有元素被修改", name)
+		}
+	}
+
+	// 快照中的分数小于接收方的最小分数
+	limited := NewSkipLinksWithCodec[int](codec, WithMinScore(10))
+	if err := limited.UnmarshalBinary(data); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Errorf("分数小于最小分数: err=%v", err)
+	}
+}
+
+func benchmarkSkipLinks(n int) *SkipLinks[int] {
+	sl := NewSkipLinksWithCodec[int](IntegerCodec[int]{})
+	for i := 0; i < n; i++ {
+		sl.Add(fmt.Sprintf("member-%d", i), float64(i%1000), i)
+	}
+	return sl
+}
+
+func BenchmarkSkipLinksUnmarshalBinary(b *testing.B) {
+	data, _ := benchmarkSkipLinks(1 << 16).MarshalBinary()
+	sl := NewSkipLinksWithCodec[int](IntegerCodec[int]{})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := sl.UnmarshalBinary(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSkipLinksAddAll(b *testing.B) {
+	nodes := benchmarkSkipLinks(1<<16).RangeByRank(0, -1)
+	rand.New(rand.NewSource(1)).Shuffle(len(nodes), func(i, j int) { nodes[i], nodes[j] = nodes[j], nodes[i] })
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sl := NewSkipLinks[int]()
+		for _, node := range nodes {
+			sl.Add(node.Key, node.Score, node.Val)
+		}
+	}
+}
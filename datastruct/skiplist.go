@@ -1,266 +1,414 @@
 package datastruct
 
 import (
-	"fmt"
+	"math"
 	"math/rand"
-	"strings"
-	"time"
+	"unsafe"
 )
 
-// 仿 redis zset
+// skipNode 跳跃表节点
+type skipNode[K, V any] struct {
+	key    K
+	value  V
+	levels []skipLevel[K, V] // 每一层的后继节点
+}
 
-type Node[T any] struct {
-	Key   string
-	Score float64 // 分数
-	Val   T
-	Next  []*Node[T] // 记录该节点后面链表的头节点(每一层)
+// skipLevel 节点某一层的索引
+type skipLevel[K, V any] struct {
+	next *skipNode[K, V] // 后继节点
+	span int             // 到后继节点跨越的节点数，next 为 nil 时为到表尾的节点数
 }
 
-func NewNode[T any](key string, score float64, val T, maxLevel uint16) *Node[T] {
-	return &Node[T]{
-		Key:   key,
-		Score: score,
-		Val:   val,
-		Next:  make([]*Node[T], maxLevel),
-	}
+// SkipList 按比较函数排序的跳跃表
+//
+// 头节点不保存 kv，key 没有取值范围的限制
+type SkipList[K, V any] struct {
+	head     *skipNode[K, V]   // 头节点
+	cmp      Comparator[K]     // key 比较函数
+	level    int               // 当前层数
+	maxLevel int               // 最大层数
+	p        float64           // 上升索引概率
+	length   int               // 节点数量
+	update   []*skipNode[K, V] // 插入删除时记录每一层的前驱节点
+	ranks    []int             // 插入时记录每一层前驱节点的排名
+	rand     *rand.Rand        // 生成随机数，用于与 p 比较，上升索引
 }
 
-// 自定义 error
+var _ OrderedMap[int, int] = (*SkipList[int, int])(nil)
 
-// 分数小于最小
-type ScoreOutOfRangeError struct {
-	key   string
-	score float64
-	min   float64
+// skipListOptions 跳跃表的配置
+type skipListOptions struct {
+	maxLevel int
+	p        float64
+	source   rand.Source
+	minScore float64
 }
 
-var _ error = (*ScoreOutOfRangeError)(nil)
+// SkipListOption 跳跃表的配置项，SkipList 与 SkipLinks 共用
+type SkipListOption func(*skipListOptions)
 
-func NewScoreOutOfRangeError(key string, score, min float64) *ScoreOutOfRangeError {
-	return &ScoreOutOfRangeError{
-		key:   key,
-		score: score,
-		min:   min,
+// WithSeed 使用固定的随机数种子，相同的种子与操作序列得到相同的层数，便于复现问题
+func WithSeed(seed int64) SkipListOption {
+	return func(o *skipListOptions) {
+		o.source = rand.NewSource(seed)
 	}
 }
 
-func (e *ScoreOutOfRangeError) Error() string {
-	return fmt.Sprintf("score out of range, min(include)=%v, score=%v, key=%s", e.min, e.score, e.key)
+// WithRandSource 使用自定义的随机数源生成节点层数，source 不需要并发安全
+func WithRandSource(source rand.Source) SkipListOption {
+	return func(o *skipListOptions) {
+		if source != nil {
+			o.source = source
+		}
+	}
 }
 
-// 键值错误
-type InvalidKeyError struct {
-	key    string
-	reason string
+// WithMaxLevel 设置最大层数（1~64，默认为 64）
+func WithMaxLevel(maxLevel int) SkipListOption {
+	return func(o *skipListOptions) {
+		if maxLevel > 0 && maxLevel <= defaultMaxLevel {
+			o.maxLevel = maxLevel
+		}
+	}
 }
 
-var _ error = (*InvalidKeyError)(nil)
+// WithProbability 设置上升索引的概率（0<p<1，默认为 0.5）
+func WithProbability(p float64) SkipListOption {
+	return func(o *skipListOptions) {
+		if p > 0 && p < 1 {
+			o.p = p
+		}
+	}
+}
 
-func NewInvalidKeyError(key, reason string) *InvalidKeyError {
-	return &InvalidKeyError{
-		key:    key,
-		reason: reason,
+// WithMinScore 设置最小分数，Add 拒绝更小的分数（默认不限制），仅对 SkipLinks 生效
+func WithMinScore(score float64) SkipListOption {
+	return func(o *skipListOptions) {
+		o.minScore = score
 	}
 }
 
-func (e *InvalidKeyError) Error() string {
-	return fmt.Sprintf("invalid key: %s, reason: %s", e.key, e.reason)
+// newSkipListOptions 默认配置加上 opts
+//
+// 没有指定随机数源时，从全局随机数源取种子，同时创建的跳跃表也不会得到相同的层数序列
+func newSkipListOptions(opts []SkipListOption) *skipListOptions {
+	o := &skipListOptions{
+		maxLevel: defaultMaxLevel,
+		p:        defaultSkipLinkedP,
+		minScore: math.Inf(-1),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.source == nil {
+		o.source = rand.NewSource(rand.Int63())
+	}
+	return o
 }
 
-// ------------------------------- skip list ---------------------------------
+// NewSkipList 新建跳跃表
+//
+//	@cmp key 的比较函数
+//	@opts 配置项，见 WithSeed、WithRandSource、WithMaxLevel、WithProbability
+func NewSkipList[K, V any](cmp Comparator[K], opts ...SkipListOption) *SkipList[K, V] {
+	return newSkipList[K, V](cmp, newSkipListOptions(opts))
+}
 
-const (
-	defaultSkipLinkedP = 0.5
-	defaultMaxLevel    = 64
-)
+func newSkipList[K, V any](cmp Comparator[K], o *skipListOptions) *SkipList[K, V] {
+	if cmp == nil {
+		panic("SkipList comparator is nil")
+	}
+	return &SkipList[K, V]{
+		head:     &skipNode[K, V]{levels: make([]skipLevel[K, V], o.maxLevel)},
+		cmp:      cmp,
+		level:    1,
+		maxLevel: o.maxLevel,
+		p:        o.p,
+		update:   make([]*skipNode[K, V], o.maxLevel),
+		ranks:    make([]int, o.maxLevel),
+		rand:     rand.New(o.source),
+	}
+}
 
-type SkipLinks[T any] struct {
-	scoreMap    map[string]float64 // key score 映射表
-	curLevel    uint16             // 当前层数
-	maxLevel    uint16             // 最大层数
-	minScore    float64            // 最小分数值
-	skipLinkedP float64            // 上升索引概率
-	head        *Node[T]           // 头节点
-	rand        *rand.Rand         // 生成随机数，用于与skipLinkedP比较，上升索引
+// emptyCopy 返回配置相同的空跳跃表，与 l 共用随机数生成器
+func (l *SkipList[K, V]) emptyCopy() *SkipList[K, V] {
+	c := newSkipList[K, V](l.cmp, &skipListOptions{maxLevel: l.maxLevel, p: l.p, source: l.rand})
+	c.rand = l.rand
+	return c
 }
 
-// NewSkipLinks 创建跳跃表
-//
-//	@maxLevel 设置最大层数
-//	@minScore 设置最小分数
-//	@p 自定义上升概率（0<p<1 默认为0.5）
-func NewSkipLinked[T any](maxLevel uint16, minScore float64, p ...float64) *SkipLinks[T] {
-	skipLinkedP := defaultSkipLinkedP
-	if len(p) != 0 && p[0] > float64(0) && p[0] < float64(1) {
-		skipLinkedP = p[0]
-	}
-	if maxLevel <= 0 || maxLevel > defaultMaxLevel {
-		maxLevel = defaultMaxLevel
-	}
-
-	var zero T
-	return &SkipLinks[T]{
-		scoreMap:    make(map[string]float64),
-		curLevel:    1,
-		maxLevel:    maxLevel,
-		minScore:    minScore,
-		skipLinkedP: skipLinkedP,
-		head:        NewNode[T]("", minScore, zero, maxLevel),
-		rand:        rand.New(rand.NewSource(time.Now().Unix())),
-	}
-}
-
-// 通过概率计算level，用于后续判断是否增加索引（并且具体加到第几层）
-func (l *SkipLinks[T]) getLevel() uint16 {
-	level := uint16(1)
-	for l.rand.Float64() < l.skipLinkedP && level < l.maxLevel {
+// randomLevel 通过概率计算新节点的层数
+func (l *SkipList[K, V]) randomLevel() int {
+	level := 1
+	for level < l.maxLevel && l.rand.Float64() < l.p {
 		level++
 	}
 	return level
 }
 
-// 比较大小，用于排序
-func (l *SkipLinks[T]) compare(score1, score2 float64, key1, key2 string) int {
-	if score1 > score2 {
-		return 1
-	} else if score1 < score2 {
-		return -1
-	} else {
-		return strings.Compare(key1, key2)
+// seekBy 跳过所有 before 返回 true 的节点，before 必须对一段前缀返回 true、其余返回 false
+//
+// 返回第一个 before 返回 false 的节点以及跳过的节点数（即该节点从 0 开始的排名）。
+// update 不为 nil 时记录每一层最后一个被跳过的节点，ranks 不为 nil 时记录这些节点的排名（头节点为 0）
+func (l *SkipList[K, V]) seekBy(before func(key K) bool, update []*skipNode[K, V], ranks []int) (*skipNode[K, V], int) {
+	node, rank := l.head, 0
+	for i := l.level - 1; i >= 0; i-- {
+		for next := node.levels[i].next; next != nil && before(next.key); next = node.levels[i].next {
+			rank += node.levels[i].span
+			node = next
+		}
+		if update != nil {
+			update[i] = node
+		}
+		if ranks != nil {
+			ranks[i] = rank
+		}
 	}
+	return node.levels[0].next, rank
+}
+
+// seek 第一个 >= key 的节点，update 不为 nil 时记录每一层最后一个 < key 的节点
+func (l *SkipList[K, V]) seek(key K, update []*skipNode[K, V], ranks []int) (*skipNode[K, V], int) {
+	return l.seekBy(func(k K) bool { return l.cmp(k, key) < 0 }, update, ranks)
 }
 
-// Search 查找对应 key 的 value
-func (l *SkipLinks[T]) Search(key string) (bool, interface{}) {
-	score, exist := l.scoreMap[key]
-	if !exist {
-		return false, nil
+// find key 所在的节点及其排名，不存在时返回 nil
+func (l *SkipList[K, V]) find(key K) (*skipNode[K, V], int) {
+	if node, rank := l.seek(key, nil, nil); node != nil && l.cmp(node.key, key) == 0 {
+		return node, rank
 	}
-	exist, node := l.search(key, score, l.head, l.curLevel)
-	if !exist {
-		return false, nil
+	return nil, -1
+}
+
+// nodeByRank 从 0 开始排名为 rank 的节点，越界时返回 nil
+func (l *SkipList[K, V]) nodeByRank(rank int) *skipNode[K, V] {
+	if rank < 0 || rank >= l.length {
+		return nil
+	}
+	node, traversed := l.head, -1 // 头节点的排名视为 -1
+	for i := l.level - 1; i >= 0; i-- {
+		for node.levels[i].next != nil && traversed+node.levels[i].span <= rank {
+			traversed += node.levels[i].span
+			node = node.levels[i].next
+		}
+		if traversed == rank {
+			return node
+		}
+	}
+	return nil
+}
+
+// skipListBuilder 按 key 升序追加节点，O(n) 构建跳跃表
+type skipListBuilder[K, V any] struct {
+	l     *SkipList[K, V]
+	tails []*skipNode[K, V] // 每一层的最后一个节点
+	ranks []int             // tails 的排名，头节点为 0
+}
+
+// newBuilder 清空跳跃表并返回 builder，构建完成前不能调用跳跃表的其他方法
+func (l *SkipList[K, V]) newBuilder() *skipListBuilder[K, V] {
+	clear(l.head.levels)
+	l.level, l.length = 1, 0
+	b := &skipListBuilder[K, V]{l: l, tails: make([]*skipNode[K, V], l.maxLevel), ranks: make([]int, l.maxLevel)}
+	for i := range b.tails {
+		b.tails[i] = l.head
 	}
-	return true, node
+	return b
 }
 
-func (l *SkipLinks[T]) search(key string, score float64, node *Node[T], level uint16) (bool, *Node[T]) {
-	next := node.Next[level-1] // 当前层 当前节点的 下一个节点
-	for next != nil && l.compare(next.Score, score, next.Key, key) <= 0 {
-		// 往后移
-		node = next
-		next = node.Next[level-1]
+// append 追加 key，key 必须大于已追加的所有 key，否则返回 false
+func (b *skipListBuilder[K, V]) append(key K, value V) bool {
+	l := b.l
+	if l.length > 0 && l.cmp(b.tails[0].key, key) >= 0 {
+		return false
 	}
-	if l.compare(node.Score, score, node.Key, key) == 0 {
-		return true, node
+	level := l.randomLevel()
+	for ; l.level < level; l.level++ {
+		l.head.levels[l.level].span = l.length
 	}
-	if level == 1 {
-		return false, nil
+	node := &skipNode[K, V]{key: key, value: value, levels: make([]skipLevel[K, V], level)}
+	rank := l.length + 1
+	for i := 0; i < level; i++ {
+		b.tails[i].levels[i] = skipLevel[K, V]{next: node, span: rank - b.ranks[i]}
+		b.tails[i], b.ranks[i] = node, rank
 	}
-	return l.search(key, score, node, level-1) // 往下一层找
+	for i := level; i < l.level; i++ { // 更高层到表尾的跨度多了新节点
+		b.tails[i].levels[i].span++
+	}
+	l.length++
+	return true
+}
+
+// Len 节点数量
+func (l *SkipList[K, V]) Len() int {
+	return l.length
+}
+
+// Get 查找 key 对应的 value
+func (l *SkipList[K, V]) Get(key K) (V, bool) {
+	if node, _ := l.find(key); node != nil {
+		return node.value, true
+	}
+	var zero V
+	return zero, false
 }
 
-func (l *SkipLinks[T]) Add(key string, score float64, val T) error {
-	if score < l.minScore {
-		return NewScoreOutOfRangeError(key, score, l.minScore)
+// Put 插入或更新 key，key 已存在时返回旧的 value 与 replaced=true
+func (l *SkipList[K, V]) Put(key K, value V) (old V, replaced bool) {
+	update, ranks := l.update, l.ranks
+	defer clear(update)
+	if node, _ := l.seek(key, update, ranks); node != nil && l.cmp(node.key, key) == 0 {
+		old, node.value = node.value, value
+		return old, true
 	}
-	if key == "" {
-		return NewInvalidKeyError(key, "key empty")
+	level := l.randomLevel()
+	for ; l.level < level; l.level++ { // 增加索引层
+		update[l.level], ranks[l.level] = l.head, 0
+		l.head.levels[l.level].span = l.length
 	}
-	// 添加
-	hight, newNode := l.add(key, score, val, l.head, l.curLevel)
-	if hight > l.curLevel { // 增加索引，这里只改变 head.Next[]，成员的自己去改变
-		l.head.Next[l.curLevel] = newNode
-		l.curLevel++
+	node := &skipNode[K, V]{key: key, value: value, levels: make([]skipLevel[K, V], level)}
+	for i := 0; i < level; i++ {
+		prev := &update[i].levels[i]
+		// 新节点的排名为 ranks[0]+1，拆分前驱节点原来的跨度
+		node.levels[i] = skipLevel[K, V]{next: prev.next, span: prev.span - (ranks[0] - ranks[i])}
+		prev.next, prev.span = node, ranks[0]-ranks[i]+1
 	}
-	l.scoreMap[key] = score
-	return nil
+	for i := level; i < l.level; i++ { // 更高层的跨度多了新节点
+		update[i].levels[i].span++
+	}
+	l.length++
+	return
 }
 
-func (l *SkipLinks[T]) add(key string, score float64, val T, node *Node[T], level uint16) (uint16, *Node[T]) {
-	next := node.Next[level-1]
-	for next != nil && l.compare(next.Score, score, next.Key, key) <= 0 { // 一层一层比
-		// 后移
-		node = next
-		next = node.Next[level-1]
-	}
-	if level == 1 { // 找到了
-		newNode := NewNode[T](key, score, val, l.maxLevel)
-		newNode.Next[level-1] = next // 肯定在 next 前面
-		node.Next[level-1] = newNode
-		return l.getLevel(), newNode
-	}
-	hight, newNode := l.add(key, score, val, node, level-1) // 往下找
-	if hight >= level {
-		newNode.Next[level-1] = next
-		node.Next[level-1] = newNode
-	}
-	return hight, newNode
-}
-
-func (l *SkipLinks[T]) Erase(key string) (bool, T) {
-	score, exist := l.scoreMap[key]
-	if !exist {
-		var zero T
-		return false, zero
-	}
-	delete(l.scoreMap, key)
-	// 可能链表中已经没有了，缓存却有
-	exist, rmNode := l.erase(key, score, l.head, l.curLevel)
-	if !exist {
-		var zero T
-		return false, zero
-	}
-	// 注意这种情况，1被删除了
-	/*
-		1
-		1
-		1 2 3 4 5
-	*/
-	for i := int(l.curLevel - 1); i > 0; i-- {
-		if l.head.Next[i] == nil { // 最高一层的被删除了（改变head的next）
-			// Erase之后，层数可能减少
-			l.curLevel--
+// Delete 删除 key，返回被删除的 value
+func (l *SkipList[K, V]) Delete(key K) (V, bool) {
+	update := l.update
+	defer clear(update)
+	node, _ := l.seek(key, update, nil)
+	if node == nil || l.cmp(node.key, key) != 0 {
+		var zero V
+		return zero, false
+	}
+	l.deleteNode(node, update)
+	return node.value, true
+}
+
+// deleteNode 删除 node，update 为每一层最后一个在 node 之前的节点
+func (l *SkipList[K, V]) deleteNode(node *skipNode[K, V], update []*skipNode[K, V]) {
+	for i := 0; i < l.level; i++ {
+		prev := &update[i].levels[i]
+		if prev.next == node {
+			prev.next, prev.span = node.levels[i].next, prev.span+node.levels[i].span-1
 		} else {
-			break
+			prev.span--
+		}
+	}
+	// 最高层的节点被删除后层数减少
+	for l.level > 1 && l.head.levels[l.level-1].next == nil {
+		l.level--
+	}
+	l.length--
+}
+
+// Min 最小的 key 及其 value，空表返回 ok=false
+func (l *SkipList[K, V]) Min() (key K, value V, ok bool) {
+	if node := l.head.levels[0].next; node != nil {
+		return node.key, node.value, true
+	}
+	return
+}
+
+// Max 最大的 key 及其 value，空表返回 ok=false
+func (l *SkipList[K, V]) Max() (key K, value V, ok bool) {
+	if node := l.nodeByRank(l.length - 1); node != nil {
+		return node.key, node.value, true
+	}
+	return
+}
+
+// Rank key 从 0 开始的升序排名，key 不存在时返回 ok=false
+func (l *SkipList[K, V]) Rank(key K) (rank int, ok bool) {
+	if node, rank := l.find(key); node != nil {
+		return rank, true
+	}
+	return -1, false
+}
+
+// ByRank 从 0 开始排名为 rank 的 kv，越界时返回 ok=false
+func (l *SkipList[K, V]) ByRank(rank int) (key K, value V, ok bool) {
+	if node := l.nodeByRank(rank); node != nil {
+		return node.key, node.value, true
+	}
+	return
+}
+
+// Ascend 按 key 升序遍历，fn 返回 false 时停止
+func (l *SkipList[K, V]) Ascend(fn func(key K, value V) bool) {
+	for node := l.head.levels[0].next; node != nil; node = node.levels[0].next {
+		if !fn(node.key, node.value) {
+			return
 		}
 	}
-	return true, rmNode.Val
 }
 
-func (l *SkipLinks[T]) erase(key string, score float64, node *Node[T], level uint16) (bool, *Node[T]) {
-	next := node.Next[level-1]
-	for next != nil && l.compare(next.Score, score, next.Key, key) < 0 {
-		node = next
-		next = node.Next[level-1]
+// Range 按 key 升序遍历 [lo, hi) 区间，fn 返回 false 时停止
+func (l *SkipList[K, V]) Range(lo, hi K, fn func(key K, value V) bool) {
+	node, _ := l.seek(lo, nil, nil)
+	for ; node != nil && l.cmp(node.key, hi) < 0; node = node.levels[0].next {
+		if !fn(node.key, node.value) {
+			return
+		}
 	}
-	if level == 1 {
-		if next != nil && l.compare(next.Score, score, next.Key, key) == 0 {
-			node.Next[level-1] = next.Next[level-1]
-			next.Next[level-1] = nil
-			return true, next
+}
+
+// SkipListStats 跳跃表的统计信息
+type SkipListStats struct {
+	Len           int     // 节点数量
+	Level         int     // 当前层数
+	LevelCounts   []int   // 第 i 层的节点数量（不含头节点）
+	AvgSearchPath float64 // 查找已存在的 key 平均经过的步数（向右与向下移动的次数之和）
+	MemoryBytes   int     // 头节点与所有节点占用的内存估算值，不包含 key、value 引用的内存
+}
+
+// Stats 统计每一层的节点数量、平均查找路径长度与内存占用，时间复杂度 O(n log n)
+func (l *SkipList[K, V]) Stats() SkipListStats {
+	var (
+		node  skipNode[K, V]
+		level skipLevel[K, V]
+	)
+	stats := SkipListStats{
+		Len:         l.length,
+		Level:       l.level,
+		LevelCounts: make([]int, l.level),
+		MemoryBytes: int(unsafe.Sizeof(*l)) + int(unsafe.Sizeof(node)) + l.maxLevel*int(unsafe.Sizeof(level)) +
+			cap(l.update)*int(unsafe.Sizeof(l.head)) + cap(l.ranks)*int(unsafe.Sizeof(0)),
+	}
+	steps := 0
+	for n := l.head.levels[0].next; n != nil; n = n.levels[0].next {
+		for i := range n.levels {
+			stats.LevelCounts[i]++
 		}
-		return false, nil
-	}
-	exist, rmNode := l.erase(key, score, node, level-1)
-	if exist && next != nil && l.compare(next.Score, score, next.Key, key) == 0 {
-		// 每一层都删掉
-		node.Next[level-1] = next.Next[level-1]
-		next.Next[level-1] = nil
-	}
-	return exist, rmNode
-}
-
-// Println 打印跳跃表
-func (l *SkipLinks[T]) Println() {
-	for i := int(l.curLevel - 1); i >= 0; i-- {
-		var (
-			content string   = fmt.Sprintf("level%d", i)
-			node    *Node[T] = l.head.Next[i]
-		)
-		for node != nil {
-			content += fmt.Sprintf(" %s(%v)", node.Key, node.Score)
-			node = node.Next[i]
+		stats.MemoryBytes += int(unsafe.Sizeof(node)) + cap(n.levels)*int(unsafe.Sizeof(level))
+		steps += l.searchPath(n)
+	}
+	if l.length > 0 {
+		stats.AvgSearchPath = float64(steps) / float64(l.length)
+	}
+	return stats
+}
+
+// searchPath 从头节点查找 target 经过的步数
+func (l *SkipList[K, V]) searchPath(target *skipNode[K, V]) int {
+	node, steps := l.head, 0
+	for i := l.level - 1; i >= 0; i-- {
+		for next := node.levels[i].next; next != nil && l.cmp(next.key, target.key) <= 0; next = node.levels[i].next {
+			node = next
+			steps++
+			if node == target {
+				return steps
+			}
 		}
-		fmt.Println(content)
+		steps++ // 向下一层
 	}
+	return steps
 }
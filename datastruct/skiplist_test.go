@@ -1,29 +1,218 @@
 package datastruct
 
 import (
-	"fmt"
+	"math"
+	"math/rand"
+	"slices"
 	"testing"
+	"unsafe"
 )
 
-func TestSkipList(t *testing.T) {
-	sl := NewSkipLinked[string](20, 1)
-	for i := 1; i < 10; i++ {
-		kv := fmt.Sprintf("%d", i)
-		sl.Add(kv, float64(i+10), kv)
+func TestSkipListRandom(t *testing.T) {
+	l := NewSkipList[int, int](OrderedComparator[int]())
+	ref := make(map[int]int)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		key := r.Intn(1000) - 500 // 包含负数 key
+		want, exist := ref[key]
+		if r.Intn(3) == 0 {
+			if v, ok := l.Delete(key); ok != exist || v != want {
+				t.Fatalf("Delete(%d)=%d,%v, 期望 %d,%v", key, v, ok, want, exist)
+			}
+			delete(ref, key)
+		} else {
+			if old, ok := l.Put(key, i); ok != exist || old != want {
+				t.Fatalf("Put(%d)=%d,%v, 期望 %d,%v", key, old, ok, want, exist)
+			}
+			ref[key] = i
+		}
 	}
-	sl.Println()
-	if b, i := sl.Search("1"); b {
-		fmt.Println(i.(*Node[string]).Val)
+	if l.Len() != len(ref) {
+		t.Fatalf("Len=%d, 期望 %d", l.Len(), len(ref))
 	}
+	checkSkipListSpans(t, l)
+	keys := make([]int, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var got []int
+	l.Ascend(func(k, v int) bool {
+		if v != ref[k] {
+			t.Fatalf("key %d 的 value 为 %d, 期望 %d", k, v, ref[k])
+		}
+		got = append(got, k)
+		return true
+	})
+	if !slices.Equal(got, keys) {
+		t.Fatalf("Ascend 结果与期望不一致")
+	}
+	for i, k := range keys {
+		if rank, ok := l.Rank(k); !ok || rank != i {
+			t.Fatalf("Rank(%d)=%d,%v, 期望 %d", k, rank, ok, i)
+		}
+		if key, _, ok := l.ByRank(i); !ok || key != k {
+			t.Fatalf("ByRank(%d)=%d,%v, 期望 %d", i, key, ok, k)
+		}
+	}
+	if _, ok := l.Rank(1000); ok {
+		t.Fatal("不存在的 key 不应有排名")
+	}
+	if _, _, ok := l.ByRank(len(keys)); ok {
+		t.Fatal("越界的排名不应存在")
+	}
+	if k, _, ok := l.Min(); !ok || k != keys[0] {
+		t.Fatalf("Min=%d, 期望 %d", k, keys[0])
+	}
+	if k, _, ok := l.Max(); !ok || k != keys[len(keys)-1] {
+		t.Fatalf("Max=%d, 期望 %d", k, keys[len(keys)-1])
+	}
+
+	for i, k := range keys {
+		l.Delete(k)
+		if i%100 == 0 {
+			checkSkipListSpans(t, l)
+		}
+	}
+	if _, _, ok := l.Max(); ok || l.Len() != 0 || l.level != 1 {
+		t.Fatalf("全部删除后 Len=%d level=%d", l.Len(), l.level)
+	}
+}
+
+// 多字段排序：分数降序，分数相同时按名字升序，分数可以是任意值
+func TestSkipListMultiField(t *testing.T) {
+	type player struct {
+		score float64
+		name  string
+	}
+	cmp := ChainComparators(
+		ReverseComparator(ComparatorBy(func(p player) float64 { return p.score })),
+		ComparatorBy(func(p player) string { return p.name }),
+	)
+	l := NewSkipList[player, int](cmp)
+	players := []player{{math.Inf(-1), "a"}, {-1e300, "b"}, {10, "d"}, {10, "c"}, {math.Inf(1), ""}, {0, ""}}
+	for i, p := range players {
+		l.Put(p, i)
+	}
+	var got []player
+	l.Ascend(func(p player, _ int) bool {
+		got = append(got, p)
+		return true
+	})
+	want := []player{{math.Inf(1), ""}, {10, "c"}, {10, "d"}, {0, ""}, {-1e300, "b"}, {math.Inf(-1), "a"}}
+	if !slices.Equal(got, want) {
+		t.Fatalf("排序结果 %v, 期望 %v", got, want)
+	}
+
+	got = got[:0]
+	l.Range(player{10, "d"}, player{-1e300, "b"}, func(p player, _ int) bool {
+		got = append(got, p)
+		return true
+	})
+	if !slices.Equal(got, want[2:4]) {
+		t.Fatalf("Range 结果 %v, 期望 %v", got, want[2:4])
+	}
+}
+
+// checkSkipListSpans 检查每一层的跨度与实际排名一致
+func checkSkipListSpans[K, V any](t *testing.T, l *SkipList[K, V]) {
+	t.Helper()
+	ranks := make(map[*skipNode[K, V]]int)
+	rank := 0
+	for node := l.head.levels[0].next; node != nil; node = node.levels[0].next {
+		rank++
+		ranks[node] = rank
+	}
+	if rank != l.length {
+		t.Fatalf("节点数 %d 与 length %d 不一致", rank, l.length)
+	}
+	for i := 0; i < l.level; i++ {
+		for node := l.head; node != nil; node = node.levels[i].next {
+			next, want := node.levels[i].next, l.length-ranks[node]
+			if next != nil {
+				want = ranks[next] - ranks[node]
+			}
+			if node.levels[i].span != want {
+				t.Fatalf("第 %d 层排名 %d 的节点跨度为 %d, 期望 %d", i, ranks[node], node.levels[i].span, want)
+			}
+		}
+	}
+}
+
+// levelsOf 每个节点的层数
+func levelsOf[K, V any](l *SkipList[K, V]) []int {
+	var levels []int
+	for node := l.head.levels[0].next; node != nil; node = node.levels[0].next {
+		levels = append(levels, len(node.levels))
+	}
+	return levels
+}
+
+func TestSkipListOptions(t *testing.T) {
+	cmp := OrderedComparator[int]()
+	build := func(opts ...SkipListOption) *SkipList[int, int] {
+		l := NewSkipList[int, int](cmp, opts...)
+		for i := 0; i < 1000; i++ {
+			l.Put(i, i)
+		}
+		return l
+	}
+
+	// 相同的种子得到相同的层数，默认种子各不相同
+	if !slices.Equal(levelsOf(build(WithSeed(42))), levelsOf(build(WithSeed(42)))) {
+		t.Fatal("相同种子的层数不一致")
+	}
+	if slices.Equal(levelsOf(build()), levelsOf(build())) {
+		t.Fatal("默认种子的层数不应相同")
+	}
+	if !slices.Equal(levelsOf(build(WithRandSource(rand.NewSource(7)))), levelsOf(build(WithSeed(7)))) {
+		t.Fatal("WithRandSource 与 WithSeed 使用相同种子时层数不一致")
+	}
+
+	if l := build(WithMaxLevel(3), WithSeed(1)); l.Stats().Level > 3 || slices.Max(levelsOf(l)) > 3 {
+		t.Fatalf("WithMaxLevel(3) 后层数为 %d", l.Stats().Level)
+	}
+	// 非法的配置被忽略
+	if l := NewSkipList[int, int](cmp, WithMaxLevel(0), WithProbability(1), WithRandSource(nil)); l.maxLevel != defaultMaxLevel || l.p != defaultSkipLinkedP || l.rand == nil {
+		t.Fatalf("非法配置未被忽略 maxLevel=%d p=%v", l.maxLevel, l.p)
+	}
+	if l := build(WithProbability(0.25), WithSeed(1)); l.Stats().LevelCounts[1] > 400 {
+		t.Fatalf("p=0.25 时第 1 层有 %d 个节点", l.Stats().LevelCounts[1])
+	}
+}
 
-	sl.Erase("1")
+func TestSkipListStats(t *testing.T) {
+	empty := NewSkipList[int, int](OrderedComparator[int]()).Stats()
+	if empty.Len != 0 || empty.Level != 1 || empty.LevelCounts[0] != 0 || empty.AvgSearchPath != 0 || empty.MemoryBytes <= 0 {
+		t.Fatalf("空表统计错误 %+v", empty)
+	}
 
-	fmt.Println("-----------------------------")
+	l := NewSkipList[int, int](OrderedComparator[int](), WithSeed(1))
+	const n = 1 << 12
+	for i := 0; i < n; i++ {
+		l.Put(i, i)
+	}
+	stats := l.Stats()
+	if stats.Len != n || stats.Level != l.level || len(stats.LevelCounts) != l.level || stats.LevelCounts[0] != n {
+		t.Fatalf("统计错误 Len=%d Level=%d LevelCounts=%v", stats.Len, stats.Level, stats.LevelCounts)
+	}
+	for i := 1; i < len(stats.LevelCounts); i++ {
+		if stats.LevelCounts[i] > stats.LevelCounts[i-1] {
+			t.Fatalf("上层节点数多于下层 %v", stats.LevelCounts)
+		}
+	}
+	// p=0.5 时期望的查找路径约为 2*log2(n)
+	if stats.AvgSearchPath < 12 || stats.AvgSearchPath > 48 {
+		t.Fatalf("平均查找路径 %v 不符合预期", stats.AvgSearchPath)
+	}
+	if stats.MemoryBytes <= empty.MemoryBytes+n*int(unsafe.Sizeof(skipNode[int, int]{})) {
+		t.Fatalf("内存占用 %d 过小", stats.MemoryBytes)
+	}
 
-	sl.Println()
-	if b, i := sl.Search("1"); b {
-		fmt.Println(i)
-	} else {
-		fmt.Println("not found")
+	for i := 0; i < n; i += 2 {
+		l.Delete(i)
+	}
+	if stats = l.Stats(); stats.Len != n/2 || stats.LevelCounts[0] != n/2 {
+		t.Fatalf("删除后统计错误 %+v", stats)
 	}
 }
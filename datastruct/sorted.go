@@ -0,0 +1,97 @@
+package datastruct
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Seq2 按顺序产生 key/value 的序列，yield 返回 false 时停止
+type Seq2[K, V any] func(yield func(key K, value V) bool)
+
+var (
+	ErrUnsortedInput = errors.New("input is not sorted")          // 输入未按升序排列
+	ErrDuplicateKey  = errors.New("input contains duplicate key") // 输入包含重复的 key
+)
+
+// UnsortedInputError 要求有序输入的构造函数（如 BulkLoad）在输入不满足严格升序时返回的错误
+//
+// 可以通过 errors.Is 判断具体原因是 ErrUnsortedInput 还是 ErrDuplicateKey
+type UnsortedInputError struct {
+	index  int   // 出错元素在输入中的下标
+	key    any   // 出错元素的 key
+	reason error // ErrUnsortedInput 或 ErrDuplicateKey
+}
+
+var _ error = (*UnsortedInputError)(nil)
+
+func NewUnsortedInputError(index int, key any, reason error) *UnsortedInputError {
+	return &UnsortedInputError{
+		index:  index,
+		key:    key,
+		reason: reason,
+	}
+}
+
+// Index 出错元素在输入中的下标
+func (e *UnsortedInputError) Index() int {
+	return e.index
+}
+
+func (e *UnsortedInputError) Error() string {
+	return fmt.Sprintf("%v: index=%d, key=%v", e.reason, e.index, e.key)
+}
+
+func (e *UnsortedInputError) Unwrap() error {
+	return e.reason
+}
+
+// ForEachSorted 按顺序遍历 seq 并对每个元素调用 fn，同时校验 key 严格升序；
+// 遇到乱序或重复的 key 时停止遍历并返回 *UnsortedInputError
+func ForEachSorted[K, V any](seq Seq2[K, V], cmp Comparator[K], fn func(key K, value V)) error {
+	var (
+		prev  K
+		index int
+		err   error
+	)
+	seq(func(key K, value V) bool {
+		if index > 0 {
+			switch c := cmp(prev, key); {
+			case c == 0:
+				err = NewUnsortedInputError(index, key, ErrDuplicateKey)
+			case c > 0:
+				err = NewUnsortedInputError(index, key, ErrUnsortedInput)
+			}
+			if err != nil {
+				return false
+			}
+		}
+		fn(key, value)
+		prev = key
+		index++
+		return true
+	})
+	return err
+}
+
+// SplitSizes 将 n 个元素尽量平均地分成若干组，每组大小接近 target 且不小于 min（只有一组时除外），
+// 返回每组的大小。用于自底向上构建树时划分结点
+func SplitSizes(n, target, min int) []int {
+	if n <= 0 {
+		return nil
+	}
+	if target < min {
+		target = min
+	}
+	groups := (n + target - 1) / target
+	for groups > 1 && n/groups < min {
+		groups--
+	}
+	sizes := make([]int, groups)
+	for i := range sizes {
+		sizes[i] = n / groups
+		if i < n%groups {
+			sizes[i]++
+		}
+	}
+	return sizes
+}
@@ -0,0 +1,70 @@
+package datastruct
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func sliceSeq(keys []int) Seq2[int, int] {
+	return func(yield func(key, value int) bool) {
+		for i, k := range keys {
+			if !yield(k, i) {
+				return
+			}
+		}
+	}
+}
+
+func TestForEachSorted(t *testing.T) {
+	var got []int
+	err := ForEachSorted(sliceSeq([]int{1, 3, 5}), OrderedComparator[int](), func(key, value int) {
+		got = append(got, key)
+	})
+	if err != nil || !slices.Equal(got, []int{1, 3, 5}) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+
+	cases := []struct {
+		keys   []int
+		index  int
+		reason error
+	}{
+		{[]int{1, 2, 2, 3}, 2, ErrDuplicateKey},
+		{[]int{1, 5, 4, 6}, 2, ErrUnsortedInput},
+	}
+	for _, c := range cases {
+		n := 0
+		err := ForEachSorted(sliceSeq(c.keys), OrderedComparator[int](), func(key, value int) { n++ })
+		var ue *UnsortedInputError
+		if !errors.As(err, &ue) || !errors.Is(err, c.reason) || ue.Index() != c.index {
+			t.Errorf("%v: 期望 %v at %d, 实际 %v", c.keys, c.reason, c.index, err)
+		}
+		if n != c.index {
+			t.Errorf("%v: 出错前应遍历 %d 个元素, 实际 %d", c.keys, c.index, n)
+		}
+	}
+}
+
+func TestSplitSizes(t *testing.T) {
+	for n := 0; n < 200; n++ {
+		for min := 1; min <= 5; min++ {
+			for target := min; target <= 2*min+1; target++ {
+				sizes := SplitSizes(n, target, min)
+				sum := 0
+				for _, s := range sizes {
+					sum += s
+					if len(sizes) > 1 && s < min {
+						t.Fatalf("SplitSizes(%d, %d, %d)=%v 小于下限", n, target, min, sizes)
+					}
+					if s > max(target, 2*min-1) {
+						t.Fatalf("SplitSizes(%d, %d, %d)=%v 超过上限", n, target, min, sizes)
+					}
+				}
+				if sum != n {
+					t.Fatalf("SplitSizes(%d, %d, %d)=%v 总数不等于 n", n, target, min, sizes)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package datastruct
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// 开放寻址的 Swiss table，参考 Abseil flat_hash_map 的布局，用可移植的 Go 代码模拟 SIMD
+//
+// 每 8 个槽位为一组，每组有 8 个控制字节，放在一个 uint64 中，可以用位运算（SWAR）同时比较 8 个槽位。
+// 控制字节为 swissEmpty、swissDeleted，或者哈希值的低 7 位 h2（槽位已使用）。
+// 哈希值的其余位 h1 决定从哪一组开始探测，组之间使用三角数探测，组数为 2 的幂次时能访问到所有组。
+// 与 HashMap2 不同，每个 kv 直接保存在槽位中，没有额外的节点分配
+
+const (
+	swissGroupSize = 8
+	swissEmpty     = 0x80 // 空槽位，查找到此停止
+	swissDeleted   = 0xFE // 被删除的槽位（墓碑），查找时跳过
+	swissMaxLoad   = 7    // 最大负载为 7/8
+
+	swissLsb = 0x0101010101010101
+	swissMsb = 0x8080808080808080
+)
+
+// swissCtrl 一组的 8 个控制字节，第 i 个字节对应第 i 个槽位
+type swissCtrl uint64
+
+// matchH2 控制字节等于 h2 的槽位，每个匹配的槽位对应字节的最高位为 1
+//
+// 可能有误报（相邻字节的借位），调用方需要比较 key，但不会漏报
+func (c swissCtrl) matchH2(h2 uint8) uint64 {
+	x := uint64(c) ^ (swissLsb * uint64(h2))
+	return (x - swissLsb) &^ x & swissMsb
+}
+
+// matchEmpty 空槽位
+func (c swissCtrl) matchEmpty() uint64 {
+	// 只有 swissEmpty 最高位为 1 且第 1 位为 0
+	return uint64(c) &^ (uint64(c) << 6) & swissMsb
+}
+
+// matchEmptyOrDeleted 空槽位或被删除的槽位
+func (c swissCtrl) matchEmptyOrDeleted() uint64 {
+	return uint64(c) & swissMsb
+}
+
+// set 设置第 i 个控制字节
+func (c *swissCtrl) set(i int, b uint8) {
+	shift := uint(i) * 8
+	*c = swissCtrl(uint64(*c)&^(0xff<<shift) | uint64(b)<<shift)
+}
+
+// get 第 i 个控制字节
+func (c swissCtrl) get(i int) uint8 {
+	return uint8(c >> (uint(i) * 8))
+}
+
+// nextMatch 取出 match 中最低的匹配槽位下标，并清除该位
+func nextMatch(match *uint64) int {
+	i := bits.TrailingZeros64(*match) / 8
+	*match &= *match - 1
+	return i
+}
+
+type swissSlot[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+type swissGroup[K comparable, V any] struct {
+	ctrl  swissCtrl
+	slots [swissGroupSize]swissSlot[K, V]
+}
+
+// SwissMap 开放寻址哈希表，不是并发安全的
+type SwissMap[K comparable, V any] struct {
+	groups     []swissGroup[K, V]
+	groupMask  uint64    // 组数-1
+	length     int       // 元素数量
+	growthLeft int       // 还能使用的空槽位数量，为 0 时扩容
+	hasher     Hasher[K] // 哈希函数
+	seed       uint64    // 哈希种子，默认每个 map 随机生成
+}
+
+type SwissMapOption[K comparable, V any] func(*SwissMap[K, V])
+
+// WithSwissMapHashAlgorithm 设置不使用种子的哈希算法
+func WithSwissMapHashAlgorithm[K comparable, V any](hashAlgorithm func(key K) uint64) SwissMapOption[K, V] {
+	return func(m *SwissMap[K, V]) {
+		if hashAlgorithm != nil {
+			m.hasher = func(_ uint64, key K) uint64 { return hashAlgorithm(key) }
+		}
+	}
+}
+
+// WithSwissMapHasher 设置哈希函数（默认为 DefaultHasher）
+func WithSwissMapHasher[K comparable, V any](hasher Hasher[K]) SwissMapOption[K, V] {
+	return func(m *SwissMap[K, V]) {
+		if hasher != nil {
+			m.hasher = hasher
+		}
+	}
+}
+
+// WithSwissMapHashSeed 设置固定的哈希种子（默认随机生成），用于复现问题
+func WithSwissMapHashSeed[K comparable, V any](seed uint64) SwissMapOption[K, V] {
+	return func(m *SwissMap[K, V]) {
+		m.seed = seed
+	}
+}
+
+// WithSwissMapCapacity 预留至少能保存 capacity 个元素的空间
+func WithSwissMapCapacity[K comparable, V any](capacity int) SwissMapOption[K, V] {
+	return func(m *SwissMap[K, V]) {
+		if capacity > 0 {
+			m.resize(swissGroupsFor(capacity))
+		}
+	}
+}
+
+// NewSwissMap 新建开放寻址哈希表
+func NewSwissMap[K comparable, V any](options ...SwissMapOption[K, V]) *SwissMap[K, V] {
+	m := &SwissMap[K, V]{hasher: DefaultHasher[K](), seed: rand.Uint64()}
+	m.resize(1)
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+// swissGroupsFor 保存 n 个元素需要的组数（2 的幂次）
+func swissGroupsFor(n int) int {
+	slots := (n*swissGroupSize + swissMaxLoad - 1) / swissMaxLoad
+	groups := (slots + swissGroupSize - 1) / swissGroupSize
+	return 1 << bits.Len(uint(max(groups, 1)-1))
+}
+
+// resize 重建为 groups 组，重新插入所有元素，同时清除墓碑
+func (m *SwissMap[K, V]) resize(groups int) {
+	old := m.groups
+	m.groups = make([]swissGroup[K, V], groups)
+	for i := range m.groups {
+		m.groups[i].ctrl = swissEmpty * swissLsb
+	}
+	m.groupMask = uint64(groups - 1)
+	m.growthLeft = groups * swissGroupSize * swissMaxLoad / 8
+	m.length = 0
+	for g := range old {
+		for i := 0; i < swissGroupSize; i++ {
+			if old[g].ctrl.get(i)&0x80 == 0 {
+				slot := &old[g].slots[i]
+				m.insertNew(m.hash(slot.key), slot.key, slot.value)
+			}
+		}
+	}
+}
+
+// probe 依次返回 hash 的探测序列中的组
+func (m *SwissMap[K, V]) probe(hash uint64, fn func(g *swissGroup[K, V]) bool) {
+	g := (hash >> 7) & m.groupMask
+	for step := uint64(1); ; step++ {
+		if !fn(&m.groups[g]) {
+			return
+		}
+		g = (g + step) & m.groupMask
+	}
+}
+
+// find key 所在的组与槽位下标，不存在时返回 nil
+func (m *SwissMap[K, V]) find(hash uint64, key K) (*swissGroup[K, V], int) {
+	var (
+		found *swissGroup[K, V]
+		index int
+	)
+	m.probe(hash, func(g *swissGroup[K, V]) bool {
+		for match := g.ctrl.matchH2(uint8(hash & 0x7f)); match != 0; {
+			if i := nextMatch(&match); g.slots[i].key == key {
+				found, index = g, i
+				return false
+			}
+		}
+		// 有空槽位说明 key 不可能在后面的组
+		return g.ctrl.matchEmpty() == 0
+	})
+	return found, index
+}
+
+// insertNew 插入不存在的 key，调用方需保证 growthLeft > 0
+func (m *SwissMap[K, V]) insertNew(hash uint64, key K, value V) {
+	m.probe(hash, func(g *swissGroup[K, V]) bool {
+		match := g.ctrl.matchEmptyOrDeleted()
+		if match == 0 {
+			return true
+		}
+		i := nextMatch(&match)
+		if g.ctrl.get(i) == swissEmpty {
+			m.growthLeft--
+		}
+		g.ctrl.set(i, uint8(hash&0x7f))
+		g.slots[i] = swissSlot[K, V]{key: key, value: value}
+		m.length++
+		return false
+	})
+}
+
+// hash 计算 key 的哈希值
+func (m *SwissMap[K, V]) hash(key K) uint64 {
+	return m.hasher(m.seed, key)
+}
+
+// Get 查找 key 对应的 value
+func (m *SwissMap[K, V]) Get(key K) (V, bool) {
+	if g, i := m.find(m.hash(key), key); g != nil {
+		return g.slots[i].value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Put 插入或更新 key
+func (m *SwissMap[K, V]) Put(key K, value V) {
+	hash := m.hash(key)
+	if g, i := m.find(hash, key); g != nil {
+		g.slots[i].value = value
+		return
+	}
+	if m.growthLeft == 0 {
+		// 墓碑较多时原地重建，否则容量翻倍
+		groups := len(m.groups)
+		if m.length >= groups*swissGroupSize*swissMaxLoad/16 {
+			groups *= 2
+		}
+		m.resize(groups)
+	}
+	m.insertNew(hash, key, value)
+}
+
+// Remove 删除 key，key 存在时返回 true
+func (m *SwissMap[K, V]) Remove(key K) bool {
+	g, i := m.find(m.hash(key), key)
+	if g == nil {
+		return false
+	}
+	// 组内有空槽位时，之前的查找不会越过这一组，可以直接置为空，否则留下墓碑
+	if g.ctrl.matchEmpty() != 0 {
+		g.ctrl.set(i, swissEmpty)
+		m.growthLeft++
+	} else {
+		g.ctrl.set(i, swissDeleted)
+	}
+	g.slots[i] = swissSlot[K, V]{}
+	m.length--
+	return true
+}
+
+// Len 元素数量
+func (m *SwissMap[K, V]) Len() int {
+	return m.length
+}
+
+// Capacity 不扩容能保存的最大元素数量
+func (m *SwissMap[K, V]) Capacity() int {
+	return len(m.groups) * swissGroupSize * swissMaxLoad / 8
+}
+
+// Range 遍历所有 kv，fn 返回 false 时停止，遍历期间不能修改 map
+func (m *SwissMap[K, V]) Range(fn func(key K, value V) bool) {
+	for g := range m.groups {
+		group := &m.groups[g]
+		for match := ^uint64(group.ctrl) & swissMsb; match != 0; {
+			i := nextMatch(&match)
+			if !fn(group.slots[i].key, group.slots[i].value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package datastruct
+
+import (
+	"math/bits"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func TestSwissCtrlMatch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	states := []uint8{swissEmpty, swissDeleted}
+	for n := 0; n < 10000; n++ {
+		var ctrl swissCtrl
+		for i := 0; i < swissGroupSize; i++ {
+			if b := r.Intn(4); b < 2 {
+				ctrl.set(i, states[b])
+			} else {
+				ctrl.set(i, uint8(r.Intn(4))) // h2 取值少，便于产生相同的字节
+			}
+		}
+		h2 := uint8(r.Intn(4))
+		match, empty, free := ctrl.matchH2(h2), ctrl.matchEmpty(), ctrl.matchEmptyOrDeleted()
+		for i := 0; i < swissGroupSize; i++ {
+			b, bit := ctrl.get(i), uint64(0x80)<<(uint(i)*8)
+			// matchH2 可以误报，但不能漏报，也不能匹配空槽位与墓碑
+			if b == h2 && match&bit == 0 || b&0x80 != 0 && match&bit != 0 {
+				t.Fatalf("ctrl=%016x h2=%d 第 %d 个槽位 matchH2 错误", uint64(ctrl), h2, i)
+			}
+			if (b == swissEmpty) != (empty&bit != 0) {
+				t.Fatalf("ctrl=%016x 第 %d 个槽位 matchEmpty 错误", uint64(ctrl), i)
+			}
+			if (b&0x80 != 0) != (free&bit != 0) {
+				t.Fatalf("ctrl=%016x 第 %d 个槽位 matchEmptyOrDeleted 错误", uint64(ctrl), i)
+			}
+		}
+	}
+}
+
+func testSwissMapRandom(t *testing.T, m *SwissMap[int, int], keys, ops int) {
+	t.Helper()
+	ref := make(map[int]int)
+	r := rand.New(rand.NewSource(1))
+	for op := 0; op < ops; op++ {
+		key := r.Intn(keys)
+		switch r.Intn(3) {
+		case 0:
+			_, exist := ref[key]
+			if ok := m.Remove(key); ok != exist {
+				t.Fatalf("Remove(%d)=%v, 期望 %v", key, ok, exist)
+			}
+			delete(ref, key)
+		case 1:
+			m.Put(key, op)
+			ref[key] = op
+		default:
+			want, exist := ref[key]
+			if v, ok := m.Get(key); ok != exist || v != want {
+				t.Fatalf("Get(%d)=%d,%v, 期望 %d,%v", key, v, ok, want, exist)
+			}
+		}
+		if m.Len() != len(ref) {
+			t.Fatalf("第 %d 次操作后 Len=%d, 期望 %d", op, m.Len(), len(ref))
+		}
+	}
+	seen := 0
+	m.Range(func(k, v int) bool {
+		if want, ok := ref[k]; !ok || want != v {
+			t.Fatalf("遍历到 %d=%d, 期望 %d,%v", k, v, want, ok)
+		}
+		seen++
+		return true
+	})
+	if seen != len(ref) {
+		t.Fatalf("遍历到 %d 个, 期望 %d", seen, len(ref))
+	}
+}
+
+func TestSwissMap(t *testing.T) {
+	t.Run("随机操作", func(t *testing.T) {
+		testSwissMapRandom(t, NewSwissMap[int, int](), 5000, 100000)
+	})
+	t.Run("反复插入删除产生墓碑", func(t *testing.T) {
+		m := NewSwissMap[int, int](WithSwissMapCapacity[int, int](64))
+		capacity := m.Capacity()
+		for i := 0; i < 100000; i++ {
+			m.Put(i, i)
+			if i >= 32 {
+				m.Remove(i - 32)
+			}
+		}
+		// 元素数量不变时原地清除墓碑，不扩容
+		if m.Len() != 32 || m.Capacity() != capacity {
+			t.Fatalf("Len=%d Capacity=%d, 期望 32 %d", m.Len(), m.Capacity(), capacity)
+		}
+		for i := 100000 - 32; i < 100000; i++ {
+			m.Remove(i)
+		}
+		testSwissMapRandom(t, m, 64, 10000)
+	})
+	t.Run("所有 key 哈希值相同", func(t *testing.T) {
+		m := NewSwissMap[int, int](WithSwissMapHashAlgorithm[int, int](func(int) uint64 { return 42 }))
+		testSwissMapRandom(t, m, 100, 5000)
+	})
+	t.Run("零值 key", func(t *testing.T) {
+		m := NewSwissMap[string, int]()
+		if _, ok := m.Get(""); ok {
+			t.Fatal("空 map 中不应找到零值 key")
+		}
+		m.Put("", 1)
+		if v, ok := m.Get(""); !ok || v != 1 || !m.Remove("") || m.Remove("") {
+			t.Fatal("零值 key 读写错误")
+		}
+	})
+	t.Run("预留容量", func(t *testing.T) {
+		m := NewSwissMap[int, int](WithSwissMapCapacity[int, int](1000))
+		capacity := m.Capacity()
+		if capacity < 1000 || bits.OnesCount(uint(len(m.groups))) != 1 {
+			t.Fatalf("预留 1000 个元素后 Capacity=%d, 组数 %d", capacity, len(m.groups))
+		}
+		for i := 0; i < 1000; i++ {
+			m.Put(i, i)
+		}
+		if m.Capacity() != capacity {
+			t.Fatalf("插入预留数量的元素后扩容了, Capacity=%d", m.Capacity())
+		}
+	})
+	t.Run("提前终止遍历", func(t *testing.T) {
+		m := NewSwissMap[int, int]()
+		for i := 0; i < 100; i++ {
+			m.Put(i, i)
+		}
+		count := 0
+		m.Range(func(int, int) bool {
+			count++
+			return count < 10
+		})
+		if count != 10 {
+			t.Fatalf("提前终止后访问了 %d 个", count)
+		}
+	})
+}
+
+// ------------------------------- benchmark ---------------------------------
+
+const benchmarkMapSize = 1 << 16
+
+// benchmarkMap 被比较的哈希表，key 为字符串，value 为 int
+type benchmarkMap struct {
+	name string
+	new  func() (put func(key string, value int), get func(key string) bool)
+}
+
+var benchmarkMaps = []benchmarkMap{
+	{"HashMap", func() (func(string, int), func(string) bool) {
+		m := NewHashMap(0)
+		return func(k string, v int) { m.Put(k, v) }, func(k string) bool { _, ok := m.Get(k); return ok }
+	}},
+	{"HashMap2", func() (func(string, int), func(string) bool) {
+		m := NewHashMap2[string, int]()
+		return m.Put, func(k string) bool { _, ok := m.Get(k); return ok }
+	}},
+	{"SwissMap", func() (func(string, int), func(string) bool) {
+		m := NewSwissMap[string, int]()
+		return m.Put, func(k string) bool { _, ok := m.Get(k); return ok }
+	}},
+	{"builtin", func() (func(string, int), func(string) bool) {
+		m := make(map[string]int)
+		return func(k string, v int) { m[k] = v }, func(k string) bool { _, ok := m[k]; return ok }
+	}},
+}
+
+func benchmarkKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key-" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+// BenchmarkMapMemory 每个元素占用的堆内存（不含 key 字符串本身），单位 B/entry
+func BenchmarkMapMemory(b *testing.B) {
+	keys := benchmarkKeys(benchmarkMapSize)
+	for _, bm := range benchmarkMaps {
+		b.Run(bm.name, func(b *testing.B) {
+			var perEntry float64
+			for i := 0; i < b.N; i++ {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				put, get := bm.new()
+				for j, key := range keys {
+					put(key, j)
+				}
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				perEntry = float64(after.HeapAlloc-before.HeapAlloc) / benchmarkMapSize
+				runtime.KeepAlive(get)
+			}
+			b.ReportMetric(perEntry, "B/entry")
+		})
+	}
+}
+
+func BenchmarkMapGet(b *testing.B) {
+	keys := benchmarkKeys(benchmarkMapSize)
+	for _, bm := range benchmarkMaps {
+		b.Run(bm.name, func(b *testing.B) {
+			put, get := bm.new()
+			for j, key := range keys {
+				put(key, j)
+			}
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !get(keys[i&(benchmarkMapSize-1)]) {
+					b.Fatal("key 不存在")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMapPut(b *testing.B) {
+	keys := benchmarkKeys(benchmarkMapSize)
+	for _, bm := range benchmarkMaps {
+		b.Run(bm.name, func(b *testing.B) {
+			for i := 0; i < b.N; {
+				put, _ := bm.new()
+				for j := 0; j < benchmarkMapSize && i < b.N; j, i = j+1, i+1 {
+					put(keys[j], j)
+				}
+			}
+		})
+	}
+}
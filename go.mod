@@ -1,6 +1,6 @@
 module DataStruct
 
-go 1.21
+go 1.24
 
 require (
 	github.com/OneOfOne/xxhash v1.2.8